@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"time"
 )
 
@@ -16,10 +15,7 @@ func SendPulse(project, agentID string, taskID interface{}, status, prevStatus s
 
 // SendPulseWithMessage sends a pulse with optional event metadata.
 func SendPulseWithMessage(project, agentID string, taskID interface{}, status, prevStatus, eventType, message string) {
-	pulseURL := os.Getenv("QUICKPLAN_WEB_URL")
-	if pulseURL == "" {
-		pulseURL = "http://localhost:8081"
-	}
+	pulseURL := projectSetting(project, "web_url").Value
 
 	pulse := struct {
 		Project    string      `json:"project"`
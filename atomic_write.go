@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/trstoyan/quickplan/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// backupSuffix is appended to a state file to name its last-good copy.
+const backupSuffix = ".bak"
+
+// writeFileAtomic durably replaces path with data; see atomicfile.WriteFile.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return atomicfile.WriteFile(path, data, perm)
+}
+
+// writeFileAtomicWithBackup is writeFileAtomic that first preserves the
+// current contents of path as path.bak. The backup is only refreshed while
+// the current file still parses as YAML, so a corrupted file never
+// overwrites the last-good copy.
+func writeFileAtomicWithBackup(path string, data []byte, perm os.FileMode) error {
+	current, err := os.ReadFile(path)
+	if err == nil && len(current) > 0 {
+		var probe yaml.Node
+		if yaml.Unmarshal(current, &probe) == nil {
+			if err := writeFileAtomic(path+backupSuffix, current, perm); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s for backup: %w", path, err)
+	}
+
+	return writeFileAtomic(path, data, perm)
+}
+
+// syncDir fsyncs a directory so a preceding rename survives a crash.
+func syncDir(dir string) error {
+	return atomicfile.SyncDir(dir)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic_ReplacesWithoutLeavingTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.yaml")
+
+	if err := writeFileAtomic(path, []byte("a: 1\n"), 0644); err != nil {
+		t.Fatalf("first write failed: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("a: 2\n"), 0644); err != nil {
+		t.Fatalf("second write failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(data) != "a: 2\n" {
+		t.Fatalf("unexpected content: %q", data)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp") {
+			t.Fatalf("temp file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomicWithBackup_KeepsLastGoodCopy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "project.yaml")
+
+	if err := writeFileAtomicWithBackup(path, []byte("v: 1\n"), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := os.Stat(path + backupSuffix); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup after first write, got err=%v", err)
+	}
+
+	if err := writeFileAtomicWithBackup(path, []byte("v: 2\n"), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	backup, _ := os.ReadFile(path + backupSuffix)
+	if string(backup) != "v: 1\n" {
+		t.Fatalf("expected backup of previous content, got %q", backup)
+	}
+
+	// A corrupted main file must not replace the last-good backup.
+	os.WriteFile(path, []byte("v: [unterminated\n"), 0644)
+	if err := writeFileAtomicWithBackup(path, []byte("v: 3\n"), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	backup, _ = os.ReadFile(path + backupSuffix)
+	if string(backup) != "v: 1\n" {
+		t.Fatalf("corrupt file overwrote backup: %q", backup)
+	}
+}
+
+func TestLoadProjectV11_FallsBackToBackupOnCorruption(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks:         []TaskV11{{ID: "t-1", Name: "first", Status: "TODO"}},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	v11.Tasks = append(v11.Tasks, TaskV11{ID: "t-2", Name: "second", Status: "TODO"})
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	// Simulate a torn write of the main file.
+	v11File := filepath.Join(pdm.dataDir, projectName, "project.yaml")
+	if err := os.WriteFile(v11File, []byte("schema_version: \"1.1\"\ntasks:\n  - id: t-1\n    name: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("expected fallback to backup, got %v", err)
+	}
+	if len(loaded.Tasks) != 1 || loaded.Tasks[0].ID != "t-1" {
+		t.Fatalf("expected last-good copy with one task, got %+v", loaded.Tasks)
+	}
+}
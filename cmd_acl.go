@@ -134,7 +134,7 @@ var aclAddCmd = &cobra.Command{
 		if err := os.MkdirAll(filepath.Dir(aclPath), 0700); err != nil {
 			return err
 		}
-		if err := os.WriteFile(aclPath, newACLData, 0600); err != nil {
+		if err := writeFileAtomic(aclPath, newACLData, 0600); err != nil {
 			return err
 		}
 
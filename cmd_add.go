@@ -34,7 +34,6 @@ Wrap the task in single quotes or escape ! if your text includes it.`,
 				return fmt.Errorf("project '%s' does not exist", targetProject)
 			}
 
-			// Load project data
 			dataDir, err := getDataDir()
 			if err != nil {
 				return fmt.Errorf("failed to get data directory: %w", err)
@@ -43,148 +42,38 @@ Wrap the task in single quotes or escape ! if your text includes it.`,
 			versionManager := NewVersionManager(version)
 			projectManager := NewProjectDataManager(dataDir, versionManager)
 
-			// Try v1.1 first
-			if v11, err := projectManager.LoadProjectV11(targetProject); err == nil {
-				// Parse flags
-				assignedTo, _ := cmd.Flags().GetString("assigned-to")
-				dependsOnRaw, _ := cmd.Flags().GetIntSlice("depends-on")
-				role, _ := cmd.Flags().GetString("role")
-				lifecycle, _ := cmd.Flags().GetString("lifecycle")
-				strategy, _ := cmd.Flags().GetString("strategy")
-				command, _ := cmd.Flags().GetString("command")
-				plugin, _ := cmd.Flags().GetString("plugin")
-				watchPath, _ := cmd.Flags().GetString("watch-path")
-
-				// Map depends_on
-				deps := make([]string, len(dependsOnRaw))
-				for i, d := range dependsOnRaw {
-					deps[i] = fmt.Sprintf("t-%d", d)
-				}
-
-				// Generate ID from max numeric suffix to avoid collisions after deletions.
-				newTask := TaskV11{
-					ID:         fmt.Sprintf("t-%d", nextV11TaskNumericID(v11.Tasks)),
-					Name:       taskText,
-					Status:     "TODO",
-					AssignedTo: assignedTo,
-					DependsOn:  deps,
-					Behavior: AgentBehavior{
-						Role:      role,
-						LifeCycle: lifecycle,
-						Strategy:  strategy,
-						Command:   command,
-						Plugin:    plugin,
-					},
-					Watch: WatchConfig{
-						Paths: []string{watchPath},
-					},
-					UpdatedAt: time.Now(),
-				}
-				v11.Tasks = append(v11.Tasks, newTask)
-				if err := projectManager.SaveProjectV11(targetProject, v11); err != nil {
-					return fmt.Errorf("failed to save project v1.1: %w", err)
-				}
-
-				// Emit pulse
-				SendPulse(targetProject, "human", newTask.ID, "TODO", "")
-
-				if globalJSON {
-					output := map[string]interface{}{
-						"status":  "success",
-						"project": targetProject,
-						"task": map[string]interface{}{
-							"id":     newTask.ID,
-							"text":   newTask.Name,
-							"status": newTask.Status,
-							"done":   newTask.Status == "DONE",
-						},
-					}
-					payload, _ := json.Marshal(output)
-					fmt.Println(string(payload))
-					return nil
-				}
-
-				fmt.Printf("Added task to project '%s' (v1.1): %s\n", targetProject, taskText)
-				return nil
-			}
-
-			projectData, err := projectManager.LoadProjectData(targetProject)
+			var (
+				isV11  bool
+				taskID interface{}
+				output map[string]interface{}
+			)
+			err = projectManager.Update(targetProject, func(tx *ProjectTx) error {
+				isV11 = tx.IsV11()
+				var err error
+				taskID, output, err = addTaskTx(cmd, tx, taskText)
+				return err
+			})
 			if err != nil {
-				return fmt.Errorf("failed to load project data: %w", err)
-			}
-
-			// Parse flags
-			assignedTo, _ := cmd.Flags().GetString("assigned-to")
-			dependsOnRaw, _ := cmd.Flags().GetIntSlice("depends-on")
-			role, _ := cmd.Flags().GetString("role")
-			lifecycle, _ := cmd.Flags().GetString("lifecycle")
-			strategy, _ := cmd.Flags().GetString("strategy")
-			command, _ := cmd.Flags().GetString("command")
-			plugin, _ := cmd.Flags().GetString("plugin")
-			watchPath, _ := cmd.Flags().GetString("watch-path")
-
-			// Add new task
-			maxID := 0
-			for _, t := range projectData.Tasks {
-				if t.ID > maxID {
-					maxID = t.ID
-				}
-			}
-
-			newTask := Task{
-				ID:         maxID + 1,
-				Text:       taskText,
-				Done:       false,
-				Status:     "TODO",
-				Created:    time.Now(),
-				Completed:  nil,
-				AssignedTo: assignedTo,
-				DependsOn:  dependsOnRaw,
-				Behavior: AgentBehavior{
-					Role:      role,
-					LifeCycle: lifecycle,
-					Strategy:  strategy,
-					Command:   command,
-					Plugin:    plugin,
-				},
-				WatchPath: watchPath,
-			}
-			projectData.Tasks = append(projectData.Tasks, newTask)
-
-			// Save project data
-			if err := projectManager.SaveProjectData(targetProject, projectData); err != nil {
-				return fmt.Errorf("failed to save project data: %w", err)
+				return err
 			}
 
-			// Emit event
-			projectManager.AppendEvent(targetProject, Event{
-				Timestamp:  time.Now(),
-				Type:       "TASK_CREATED",
-				Actor:      "human",
-				TaskID:     fmt.Sprintf("t-%d", newTask.ID),
-				NextStatus: "TODO",
-				Message:    fmt.Sprintf("Task created: %s", taskText),
-			})
-
 			// Emit pulse
-			SendPulse(targetProject, "human", newTask.ID, "TODO", "")
+			SendPulse(targetProject, "human", taskID, "TODO", "")
 
 			if globalJSON {
-				output := map[string]interface{}{
+				payload, _ := json.Marshal(map[string]interface{}{
 					"status":  "success",
 					"project": targetProject,
-					"task": map[string]interface{}{
-						"id":     newTask.ID,
-						"text":   newTask.Text,
-						"status": GetTaskStatus(newTask),
-						"done":   newTask.Done,
-					},
-				}
-				payload, _ := json.Marshal(output)
+					"task":    output,
+				})
 				fmt.Println(string(payload))
 				return nil
 			}
 
+			if isV11 {
+				fmt.Printf("Added task to project '%s' (v1.1): %s\n", targetProject, taskText)
+				return nil
+			}
 			fmt.Printf("Added task to project '%s': %s\n", targetProject, taskText)
 			return nil
 		},
@@ -198,9 +87,243 @@ func init() {
 	addCmd.Flags().String("role", "", "Role for the agent behavior")
 	addCmd.Flags().String("lifecycle", "", "Lifecycle for the agent behavior (e.g., Atomic, Infinite)")
 	addCmd.Flags().String("strategy", "", "Strategy for the agent behavior (e.g., TDD, Fast Prototype)")
+	addCmd.Flags().String("timeout", "", "Stop a run that takes longer than this (e.g., 10m)")
+	addCmd.Flags().String("loop-interval", "", "Pause between runs of an Infinite task (e.g., 30s, 5m)")
+	addCmd.Flags().Int("max-iterations", 0, "End an Infinite task after this many runs, 0 for no limit")
+	addCmd.Flags().Int("until-exit-code", -1, "End an Infinite task once a run exits with this code")
 	addCmd.Flags().String("command", "", "Execution command for the task")
 	addCmd.Flags().String("plugin", "", "Plugin name to execute for the task (equivalent to assigned-to=plugin:<name>)")
 	addCmd.Flags().String("watch-path", "", "Physical file path to watch for dependency verification")
+	addCmd.Flags().String("priority", "", "Task priority: low, medium, high or urgent (schema 1.2)")
+	addCmd.Flags().String("due", "", "Due date as 2026-03-01 or RFC3339 (schema 1.2)")
+	addCmd.Flags().String("start", "", "Start date as 2026-03-01 or RFC3339; workers wait until then (schema 1.3)")
+	addCmd.Flags().StringSlice("label", []string{}, "Label to attach; repeat or comma-separate (schema 1.2)")
+	addCmd.Flags().String("estimate", "", "Effort estimate such as 90m, 4h, 3d or 2w (schema 1.2)")
+	addCmd.Flags().String("description", "", "Markdown description (schema 1.2)")
+	addCmd.Flags().String("parent", "", "Make the task a subtask of this task ID (schema 1.3)")
+	addCmd.Flags().String("recur", "", "Repeat the task on a cron schedule such as \"0 9 * * MON\" (schema 1.3)")
+	addCmd.Flags().String("recur-tz", "", "Time zone for --recur, e.g. Europe/Berlin (default local)")
+}
+
+// addTaskTx adds a task built from the command's flags to the project of
+// tx, recording a TASK_CREATED event, and returns its ID and JSON output.
+func addTaskTx(cmd *cobra.Command, tx *ProjectTx, taskText string) (interface{}, map[string]interface{}, error) {
+	var (
+		taskID      interface{}
+		output      map[string]interface{}
+		eventTaskID string
+	)
+	if tx.IsV11() {
+		newTask, err := addTaskV11(cmd, tx.V11(), taskText)
+		if err != nil {
+			return nil, nil, err
+		}
+		taskID, eventTaskID = newTask.ID, newTask.ID
+		output = map[string]interface{}{
+			"id":     newTask.ID,
+			"text":   newTask.Name,
+			"status": newTask.Status,
+			"done":   newTask.Status == "DONE",
+		}
+	} else {
+		newTask, err := addTaskLegacy(cmd, tx.Legacy(), taskText)
+		if err != nil {
+			return nil, nil, err
+		}
+		taskID, eventTaskID = newTask.ID, fmt.Sprintf("t-%d", newTask.ID)
+		output = map[string]interface{}{
+			"id":     newTask.ID,
+			"text":   newTask.Text,
+			"status": GetTaskStatus(newTask),
+			"done":   newTask.Done,
+		}
+	}
+
+	tx.AppendEvent(Event{
+		Timestamp:  time.Now(),
+		Type:       "TASK_CREATED",
+		Actor:      "human",
+		TaskID:     eventTaskID,
+		NextStatus: "TODO",
+		Message:    fmt.Sprintf("Task created: %s", taskText),
+	})
+	tx.MarkModified()
+	return taskID, output, nil
+}
+
+// addTaskV11 appends a task built from the command's flags to project and
+// returns it.
+func addTaskV11(cmd *cobra.Command, project *ProjectV11, taskText string) (TaskV11, error) {
+	assignedTo, _ := cmd.Flags().GetString("assigned-to")
+	dependsOnRaw, _ := cmd.Flags().GetIntSlice("depends-on")
+	role, _ := cmd.Flags().GetString("role")
+	lifecycle, _ := cmd.Flags().GetString("lifecycle")
+	strategy, _ := cmd.Flags().GetString("strategy")
+	command, _ := cmd.Flags().GetString("command")
+	plugin, _ := cmd.Flags().GetString("plugin")
+	watchPath, _ := cmd.Flags().GetString("watch-path")
+
+	// Map depends_on
+	deps := make([]string, len(dependsOnRaw))
+	for i, d := range dependsOnRaw {
+		deps[i] = fmt.Sprintf("t-%d", d)
+	}
+
+	// Generate ID from max numeric suffix to avoid collisions after deletions.
+	newTask := TaskV11{
+		ID:         fmt.Sprintf("t-%d", nextV11TaskNumericID(project.Tasks)),
+		Name:       taskText,
+		Status:     "TODO",
+		AssignedTo: assignedTo,
+		DependsOn:  deps,
+		Behavior: AgentBehavior{
+			Role:      role,
+			LifeCycle: lifecycle,
+			Strategy:  strategy,
+			Command:   command,
+			Plugin:    plugin,
+		},
+		Watch: WatchConfig{
+			Paths: []string{watchPath},
+		},
+		UpdatedAt: time.Now(),
+	}
+	if err := applyBehaviorFlags(cmd, &newTask.Behavior); err != nil {
+		return TaskV11{}, err
+	}
+	if _, err := applyTaskMetadataFlags(cmd, &newTask); err != nil {
+		return TaskV11{}, err
+	}
+	if err := validateTaskSchemaFields(project.SchemaVersion, newTask); err != nil {
+		return TaskV11{}, err
+	}
+	project.Tasks = append(project.Tasks, newTask)
+	return newTask, nil
+}
+
+// addTaskLegacy appends a task built from the command's flags to a legacy
+// project and returns it.
+func addTaskLegacy(cmd *cobra.Command, projectData *ProjectData, taskText string) (Task, error) {
+	if set, err := applyTaskMetadataFlags(cmd, &TaskV11{}); err != nil {
+		return Task{}, err
+	} else if set {
+		return Task{}, fmt.Errorf("--priority, --due, --start, --label, --estimate, --description, --parent and --recur require a schema 1.2 or later project (run 'quickplan migrate v1.1 --schema 1.3')")
+	}
+
+	// Parse flags
+	assignedTo, _ := cmd.Flags().GetString("assigned-to")
+	dependsOnRaw, _ := cmd.Flags().GetIntSlice("depends-on")
+	role, _ := cmd.Flags().GetString("role")
+	lifecycle, _ := cmd.Flags().GetString("lifecycle")
+	strategy, _ := cmd.Flags().GetString("strategy")
+	command, _ := cmd.Flags().GetString("command")
+	plugin, _ := cmd.Flags().GetString("plugin")
+	watchPath, _ := cmd.Flags().GetString("watch-path")
+
+	// Add new task
+	maxID := 0
+	for _, t := range projectData.Tasks {
+		if t.ID > maxID {
+			maxID = t.ID
+		}
+	}
+
+	newTask := Task{
+		ID:         maxID + 1,
+		Text:       taskText,
+		Done:       false,
+		Status:     "TODO",
+		Created:    time.Now(),
+		Completed:  nil,
+		AssignedTo: assignedTo,
+		DependsOn:  dependsOnRaw,
+		Behavior: AgentBehavior{
+			Role:      role,
+			LifeCycle: lifecycle,
+			Strategy:  strategy,
+			Command:   command,
+			Plugin:    plugin,
+		},
+		WatchPath: watchPath,
+	}
+	if err := applyBehaviorFlags(cmd, &newTask.Behavior); err != nil {
+		return Task{}, err
+	}
+	projectData.Tasks = append(projectData.Tasks, newTask)
+	return newTask, nil
+}
+
+// applyTaskMetadataFlags copies the schema 1.2 and 1.3 metadata flags that were set
+// onto task and reports whether any were.
+func applyTaskMetadataFlags(cmd *cobra.Command, task *TaskV11) (bool, error) {
+	set := false
+	if cmd.Flags().Changed("priority") {
+		priority, _ := cmd.Flags().GetString("priority")
+		task.Priority = strings.ToLower(strings.TrimSpace(priority))
+		set = true
+	}
+	if cmd.Flags().Changed("due") {
+		raw, _ := cmd.Flags().GetString("due")
+		if strings.TrimSpace(raw) == "" {
+			task.Due = nil
+		} else {
+			due, err := parseDueDate(raw)
+			if err != nil {
+				return false, err
+			}
+			task.Due = &due
+			set = true
+		}
+	}
+	if cmd.Flags().Changed("start") {
+		raw, _ := cmd.Flags().GetString("start")
+		if strings.TrimSpace(raw) == "" {
+			task.Start = nil
+		} else {
+			start, err := parseStartDate(raw)
+			if err != nil {
+				return false, err
+			}
+			task.Start = &start
+			set = true
+		}
+	}
+	if cmd.Flags().Changed("label") {
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		task.Labels = labels
+		set = true
+	}
+	if cmd.Flags().Changed("estimate") {
+		task.Estimate, _ = cmd.Flags().GetString("estimate")
+		set = true
+	}
+	if cmd.Flags().Changed("description") {
+		task.Description, _ = cmd.Flags().GetString("description")
+		set = true
+	}
+	if cmd.Flags().Changed("recur") || cmd.Flags().Changed("recur-tz") {
+		schedule, _ := cmd.Flags().GetString("recur")
+		timezone, _ := cmd.Flags().GetString("recur-tz")
+		if !cmd.Flags().Changed("recur") && task.Recurrence != nil {
+			schedule = task.Recurrence.Schedule
+		}
+		if strings.TrimSpace(schedule) == "" {
+			task.Recurrence = nil
+		} else {
+			recurrence := &Recurrence{Schedule: schedule, Timezone: timezone}
+			if _, err := nextOccurrence(recurrence, time.Now()); err != nil {
+				return false, err
+			}
+			task.Recurrence = recurrence
+			set = true
+		}
+	}
+	if cmd.Flags().Changed("parent") {
+		parent, _ := cmd.Flags().GetString("parent")
+		task.Parent = normalizeTaskID(parent)
+		set = true
+	}
+	return set, nil
 }
 
 func nextV11TaskNumericID(tasks []TaskV11) int {
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestAddTaskV11_ConcurrentAddsKeepEveryTask(t *testing.T) {
+	pdm, projectName, cleanup := newLeaseTestProject(t)
+	defer cleanup()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- pdm.Update(projectName, func(tx *ProjectTx) error {
+				_, err := addTaskV11(addCmd, tx.V11(), fmt.Sprintf("task %d", i))
+				tx.MarkModified()
+				return err
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+
+	v11, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, task := range v11.Tasks {
+		ids[task.ID] = true
+	}
+	if len(v11.Tasks) != 8 || len(ids) != 8 {
+		t.Fatalf("expected 8 tasks with distinct IDs, got %d (%v)", len(v11.Tasks), ids)
+	}
+}
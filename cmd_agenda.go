@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "Show dated tasks from all projects by when they are due",
+	Long: `Show the open tasks of every active project that have a due or start date,
+grouped into overdue, today, this week (through Sunday) and later.
+Tasks with only a start date are listed under that date and never count
+as overdue. Inside a repository the agenda covers its projects and the
+global ones.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataDirs, err := allDataDirs()
+		if err != nil {
+			return fmt.Errorf("failed to get data directory: %w", err)
+		}
+
+		now := time.Now()
+		var items []AgendaItem
+		for _, dataDir := range dataDirs {
+			projectManager := NewProjectDataManager(dataDir, NewVersionManager(version))
+			projects, err := projectManager.ListProjects(false)
+			if err != nil {
+				return fmt.Errorf("failed to list projects: %w", err)
+			}
+			items = append(items, projectManager.Agenda(projects, now)...)
+		}
+		sortAgenda(items)
+
+		if globalJSON {
+			if items == nil {
+				items = []AgendaItem{}
+			}
+			payload, _ := json.Marshal(items)
+			fmt.Println(string(payload))
+			return nil
+		}
+
+		if len(items) == 0 {
+			fmt.Println("Nothing on the agenda. Give tasks a date with 'quickplan set <id> --due 2026-03-01'.")
+			return nil
+		}
+
+		titles := map[string]string{
+			agendaOverdue:  "Overdue",
+			agendaToday:    "Today",
+			agendaThisWeek: "This week",
+			agendaLater:    "Later",
+		}
+		first := true
+		for _, bucket := range agendaBuckets {
+			var rows []AgendaItem
+			for _, item := range items {
+				if item.Bucket == bucket {
+					rows = append(rows, item)
+				}
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			if !first {
+				fmt.Println()
+			}
+			first = false
+
+			fmt.Printf("%s:\n", titles[bucket])
+			for _, item := range rows {
+				when := "due " + item.date().Format("Mon Jan 2 15:04")
+				if item.Due == nil {
+					when = "starts " + item.date().Format("Mon Jan 2 15:04")
+				}
+				fmt.Printf("  %-24s %s/%s [%s] %s", when, item.Project, item.TaskID, getStatusIcon(item.Status), item.Text)
+				if item.Priority != "" {
+					fmt.Printf(" (%s)", item.Priority)
+				}
+				fmt.Println()
+			}
+		}
+		return nil
+	},
+}
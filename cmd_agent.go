@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -105,7 +106,7 @@ var agentRunCmd = &cobra.Command{
 				Strategy: target.Behavior.Strategy,
 			}
 
-			resp, err := ExecutePlugin(pluginName, req)
+			resp, err := ExecutePlugin(context.Background(), pluginName, req)
 			if err != nil {
 				return err
 			}
@@ -30,7 +30,6 @@ archives the current project. Use --toggle to switch archive state.`,
 			return fmt.Errorf("project '%s' does not exist", targetProject)
 		}
 
-		// Load project data
 		dataDir, err := getDataDir()
 		if err != nil {
 			return fmt.Errorf("failed to get data directory: %w", err)
@@ -39,21 +38,13 @@ archives the current project. Use --toggle to switch archive state.`,
 		versionManager := NewVersionManager(version)
 		projectManager := NewProjectDataManager(dataDir, versionManager)
 
-		projectData, err := projectManager.LoadProjectData(targetProject)
+		archived, err := projectManager.ToggleArchived(targetProject)
 		if err != nil {
-			return fmt.Errorf("failed to load project data: %w", err)
-		}
-
-		// Toggle archive state
-		projectData.Archived = !projectData.Archived
-
-		// Save project data
-		if err := projectManager.SaveProjectData(targetProject, projectData); err != nil {
-			return fmt.Errorf("failed to save project data: %w", err)
+			return err
 		}
 
 		status := "archived"
-		if !projectData.Archived {
+		if !archived {
 			status = "unarchived"
 		}
 		fmt.Printf("Project '%s' has been %s\n", targetProject, status)
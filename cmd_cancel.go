@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var cancelCmd = &cobra.Command{
+	Use:   "cancel <task-id>",
+	Short: "Cancel a task",
+	Long: `Move a task to CANCELLED from any status. Workers do not pick it up again,
+and a worker running it stops its command within a second or so.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetProject, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+		if !projectExists(targetProject) {
+			return fmt.Errorf("project '%s' does not exist", targetProject)
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return fmt.Errorf("failed to get data directory: %w", err)
+		}
+		projectManager := NewProjectDataManager(dataDir, NewVersionManager(version))
+
+		taskID := normalizeTaskID(args[0])
+		prevStatus, err := cancelTask(projectManager, targetProject, taskID, "human")
+		if err != nil {
+			return err
+		}
+
+		SendPulse(targetProject, "human", taskID, "CANCELLED", prevStatus)
+
+		if globalJSON {
+			payload, _ := json.Marshal(map[string]interface{}{
+				"status":  "success",
+				"project": targetProject,
+				"task": map[string]interface{}{
+					"id":     taskID,
+					"status": "CANCELLED",
+				},
+			})
+			fmt.Println(string(payload))
+			return nil
+		}
+
+		fmt.Printf("Cancelled task: %s\n", taskID)
+		return nil
+	},
+}
+
+func init() {
+	cancelCmd.Flags().StringP("project", "p", "", "Cancel a task in this project instead of current")
+}
+
+// cancelTask moves a task to CANCELLED and returns its previous status.
+func cancelTask(projectManager *ProjectDataManager, projectName, taskID, actor string) (string, error) {
+	var prevStatus string
+	err := projectManager.Update(projectName, func(tx *ProjectTx) error {
+		var err error
+		prevStatus, err = tx.SetStatus(taskID, "CANCELLED", actor)
+		return err
+	})
+	return prevStatus, err
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancelTask_FromAnyStatus(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	projectData, _ := pdm.LoadProjectData(projectName)
+	projectData.Tasks = []Task{
+		{ID: 1, Text: "running", Status: "IN_PROGRESS", Created: time.Now()},
+		{ID: 2, Text: "blocked", Status: "BLOCKED", Created: time.Now()},
+	}
+	_ = pdm.SaveProjectData(projectName, projectData)
+
+	for _, id := range []string{"t-1", "t-2"} {
+		if _, err := cancelTask(pdm, projectName, id, "human"); err != nil {
+			t.Fatalf("cancel %s failed: %v", id, err)
+		}
+		if status := taskStatus(t, pdm, projectName, id); status != "CANCELLED" {
+			t.Fatalf("expected %s CANCELLED, got %s", id, status)
+		}
+	}
+	if _, err := cancelTask(pdm, projectName, "t-9", "human"); err == nil {
+		t.Fatal("expected an unknown task to fail")
+	}
+}
@@ -188,20 +188,24 @@ displays an interactive menu to select a task to complete.`,
 
 		noteText, _ := cmd.Flags().GetString("note")
 		if noteText != "" {
-			reloaded, err := projectManager.LoadProjectData(targetProject)
-			if err != nil {
-				return fmt.Errorf("failed to reload project data for note update: %w", err)
-			}
-			for i := range reloaded.Tasks {
-				if reloaded.Tasks[i].ID == selectedTaskID {
-					reloaded.Tasks[i].Notes = append(reloaded.Tasks[i].Notes, NoteEntry{
-						Text:      noteText,
-						Timestamp: time.Now(),
-					})
-					break
+			err := projectManager.Update(targetProject, func(tx *ProjectTx) error {
+				if tx.IsV11() {
+					return fmt.Errorf("project '%s' is no longer a legacy project", targetProject)
 				}
-			}
-			if err := projectManager.SaveProjectData(targetProject, reloaded); err != nil {
+				tasks := tx.Legacy().Tasks
+				for i := range tasks {
+					if tasks[i].ID == selectedTaskID {
+						tasks[i].Notes = append(tasks[i].Notes, NoteEntry{
+							Text:      noteText,
+							Timestamp: time.Now(),
+						})
+						tx.MarkModified()
+						break
+					}
+				}
+				return nil
+			})
+			if err != nil {
 				return fmt.Errorf("failed to save note update: %w", err)
 			}
 		}
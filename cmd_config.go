@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and change quickplan settings",
+	Long: `Inspect and change quickplan settings.
+
+Global settings live in ~/.config/quickplan/config.yaml ($XDG_CONFIG_HOME is
+honored). A project can override them under "settings:" in its project.yml.
+The effective value follows this precedence:
+
+  flag > env > project > global > default`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := lookupSetting(args[0]); err != nil {
+			return err
+		}
+		projectManager, projectName, err := configProjectManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		resolved := projectManager.Setting(projectName, args[0])
+		if globalJSON {
+			out, err := json.Marshal(resolved)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+		fmt.Println(resolved.Value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Store a setting globally or for one project",
+	Long: `Store a setting in the global config file, or with --scope project in the
+target project's project.yml. An empty value removes the stored setting.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+		scope, _ := cmd.Flags().GetString("scope")
+
+		switch scope {
+		case sourceGlobal:
+			if err := setGlobalSetting(key, value); err != nil {
+				return err
+			}
+			path, _ := globalConfigPath()
+			fmt.Printf("✓ Set %s in %s\n", key, path)
+		case sourceProject:
+			projectManager, projectName, err := configProjectManager(cmd)
+			if err != nil {
+				return err
+			}
+			if err := projectManager.SetProjectSetting(projectName, key, value); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Set %s for project '%s'\n", key, projectName)
+		default:
+			return fmt.Errorf("invalid scope %q (use global or project)", scope)
+		}
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting with its effective value and source",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectManager, projectName, err := configProjectManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		settings := projectManager.Settings(projectName)
+		if globalJSON {
+			out, err := json.MarshalIndent(settings, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		fmt.Printf("Settings for project '%s':\n\n", projectName)
+		for i, resolved := range settings {
+			setting := configSettings[i]
+			value := resolved.Value
+			if setting.Secret && value != "" {
+				value = "********"
+			}
+			if value == "" {
+				value = "(unset)"
+			}
+			fmt.Printf("  %-22s %-24s [%s]\n", resolved.Key, value, resolved.Source)
+			fmt.Printf("  %-22s %s", "", setting.Description)
+			if setting.Env != "" {
+				fmt.Printf(" ($%s)", setting.Env)
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+// configProjectManager returns a manager for the current data directory and
+// the project whose overrides apply.
+func configProjectManager(cmd *cobra.Command) (*ProjectDataManager, string, error) {
+	projectName, err := getTargetProject(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+	dataDir, err := getDataDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get data directory: %w", err)
+	}
+	return NewProjectDataManager(dataDir, NewVersionManager(version)), projectName, nil
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	configCmd.PersistentFlags().StringP("project", "p", "", "Resolve project overrides for this project instead of current")
+	configSetCmd.Flags().String("scope", sourceGlobal, "Where to store the value: global or project")
+}
@@ -44,7 +44,13 @@ or defaults to creating/using 'default' project.`,
 		versionManager := NewVersionManager(version)
 		projectManager := NewProjectDataManager(dataDir, versionManager)
 
-		if err := projectManager.CreateProject(projectName); err != nil {
+		layout, _ := cmd.Flags().GetString("layout")
+		if layout == "" {
+			err = projectManager.CreateProject(projectName)
+		} else {
+			err = projectManager.CreateProjectV11(projectName, layout)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to create project: %w", err)
 		}
 
@@ -60,4 +66,5 @@ or defaults to creating/using 'default' project.`,
 
 func init() {
 	createCmd.Flags().StringP("project", "p", "", "Project name")
+	createCmd.Flags().String("layout", "", "Create a v1.1 project stored as single-file or task-files (default: legacy tasks.yaml)")
 }
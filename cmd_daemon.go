@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -45,9 +48,17 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		"pid":     os.Getpid(),
 	})
 
-	// 2. Initialize Managers
+	// 2. Initialize Managers, one per data directory: inside a repository
+	// the daemon runs its projects and the global ones.
+	dataDirs, err := allDataDirs()
+	if err != nil {
+		return fmt.Errorf("failed to get data directory: %w", err)
+	}
 	versionManager := NewVersionManager(version)
-	projectManager := NewProjectDataManager(dataDir, versionManager)
+	var managers []*ProjectDataManager
+	for _, dir := range dataDirs {
+		managers = append(managers, NewProjectDataManager(dir, versionManager))
+	}
 
 	// 3. Setup FSNotify
 	watcher, err := fsnotify.NewWatcher()
@@ -63,26 +74,27 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 
 	// Helper to add project directories to watcher
 	addProjectWatches := func() {
-		projects, err := projectManager.ListProjects(false)
-		if err != nil {
-			return
-		}
-
 		watchMu.Lock()
 		defer watchMu.Unlock()
-		for _, p := range projects {
-			pDir := filepath.Join(dataDir, p)
-			if !watchedDirs[pDir] {
-				if err := watcher.Add(pDir); err == nil {
-					watchedDirs[pDir] = true
-					logger.Log("INFO", "Daemon", fmt.Sprintf("Watching project: %s", p), nil)
+		for _, projectManager := range managers {
+			projects, err := projectManager.ListProjects(false)
+			if err != nil {
+				continue
+			}
+			for _, p := range projects {
+				pDir := filepath.Join(projectManager.dataDir, p)
+				if !watchedDirs[pDir] {
+					if err := watcher.Add(pDir); err == nil {
+						watchedDirs[pDir] = true
+						logger.Log("INFO", "Daemon", fmt.Sprintf("Watching project: %s", p), nil)
+					}
 				}
 			}
-		}
-		// Also watch the root dataDir for new project creation
-		if !watchedDirs[dataDir] {
-			if err := watcher.Add(dataDir); err == nil {
-				watchedDirs[dataDir] = true
+			// Also watch the root data directory for new project creation
+			if !watchedDirs[projectManager.dataDir] {
+				if err := watcher.Add(projectManager.dataDir); err == nil {
+					watchedDirs[projectManager.dataDir] = true
+				}
 			}
 		}
 	}
@@ -90,17 +102,40 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 	addProjectWatches()
 
 	// 4. Task Execution Engine
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	activeAgents := make(map[string]int)
 	var agentMu sync.Mutex
-	const maxAgentsPerProject = 2
+	var workers sync.WaitGroup
+
+	processProject := func(projectManager *ProjectDataManager, project string) {
+		if _, err := projectManager.ReapExpiredLeases(project, "daemon", time.Now()); err != nil {
+			logger.Log("ERROR", "Daemon", "Failed to reap expired leases", map[string]interface{}{
+				"project": project,
+				"error":   err.Error(),
+			})
+		}
+		if _, err := projectManager.RecurDoneTasks(project, "daemon", time.Now()); err != nil {
+			logger.Log("ERROR", "Daemon", "Failed to schedule recurring tasks", map[string]interface{}{
+				"project": project,
+				"error":   err.Error(),
+			})
+		}
+		if _, err := projectManager.ReportOverdueTasks(project, "daemon", time.Now()); err != nil {
+			logger.Log("ERROR", "Daemon", "Failed to check due dates", map[string]interface{}{
+				"project": project,
+				"error":   err.Error(),
+			})
+		}
 
-	processProject := func(project string) {
 		// Check if we have capacity for this project
+		agentKey := filepath.Join(projectManager.dataDir, project)
 		agentMu.Lock()
-		count := activeAgents[project]
+		count := activeAgents[agentKey]
 		agentMu.Unlock()
 
-		if count >= maxAgentsPerProject {
+		if count >= projectManager.SettingInt(project, "daemon.max_agents") {
 			return
 		}
 
@@ -117,21 +152,19 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return
 		}
-		statusByID := buildStatusIndex(views)
-
 		var targetTask *TaskView
-		for _, v := range views {
-			if v.AssignedTo == "" && isTaskRunnable(v, statusByID) {
-				targetTask = &v
-				break
-			}
+		candidates := projectManager.runnableCandidates(project, views, func(v TaskView) bool {
+			return v.AssignedTo == ""
+		})
+		if len(candidates) > 0 {
+			targetTask = &candidates[0]
 		}
 
 		if targetTask != nil {
 			agentID := fmt.Sprintf("daemon-worker-%d", time.Now().UnixNano()%10000)
 
-			// Update state to IN_PROGRESS and save to prevent hot-loop/double-grabbing
-			if err := projectManager.UpdateTaskStatus(project, targetTask.ID, "IN_PROGRESS", agentID); err != nil {
+			// Claim the task (IN_PROGRESS with a lease) to prevent hot-loop/double-grabbing
+			if err := projectManager.ClaimTask(project, targetTask.ID, agentID); err != nil {
 				logger.Log("ERROR", "Daemon", "Failed to update task to IN_PROGRESS", map[string]interface{}{
 					"project": project,
 					"task":    targetTask.ID,
@@ -142,13 +175,15 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 
 			// Spawn a worker
 			agentMu.Lock()
-			activeAgents[project]++
+			activeAgents[agentKey]++
 			agentMu.Unlock()
 
+			workers.Add(1)
 			go func(proj string, task TaskView, workerID string) {
+				defer workers.Done()
 				defer func() {
 					agentMu.Lock()
-					activeAgents[proj]--
+					activeAgents[agentKey]--
 					agentMu.Unlock()
 				}()
 
@@ -159,6 +194,7 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 				taskRunner := &BackgroundRunner{
 					Logger:         logger,
 					ProjectManager: projectManager,
+					Stop:           ctx.Done(),
 				}
 
 				if err := taskRunner.RunTask(proj, workerID, &task); err != nil {
@@ -178,20 +214,32 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initial scan
-	projects, _ := projectManager.ListProjects(false)
-	for _, p := range projects {
-		processProject(p)
+	scanProjects := func() {
+		for _, projectManager := range managers {
+			projects, _ := projectManager.ListProjects(false)
+			for _, p := range projects {
+				processProject(projectManager, p)
+			}
+		}
 	}
+	scanProjects()
 
 	// 5. Main Event Loop
 	logger.Log("INFO", "Daemon", "Entering event loop", nil)
 
 	// Periodic ticker as fallback and to discover new projects
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(managers[0].SettingDuration("", "daemon.poll_interval"))
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-ctx.Done():
+			// Atomic tasks finish their run; Infinite loops end after
+			// their current iteration and go back to PENDING.
+			logger.Log("INFO", "Daemon", "Shutting down, waiting for running agents", nil)
+			workers.Wait()
+			logger.Log("INFO", "Daemon", "Daemon stopped", nil)
+			return nil
 		case event, ok := <-watcher.Events:
 			if !ok {
 				return nil
@@ -199,23 +247,22 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 			// If a project file or directory changed
 			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
 				// Determine which project changed
-				rel, err := filepath.Rel(dataDir, event.Name)
-				if err == nil && rel != "." && rel != ".." {
+				for _, projectManager := range managers {
+					rel, err := filepath.Rel(projectManager.dataDir, event.Name)
+					if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+						continue
+					}
 					// rel might be "project-name" or "project-name/project.yaml"
 					projectName := rel
 					if strings.Contains(rel, string(os.PathSeparator)) {
 						projectName = filepath.Dir(rel)
 					}
 
-					// If it's the root dataDir, we might need to add new watches
-					if event.Name == dataDir {
-						addProjectWatches()
-					} else {
-						// Only process if it looks like a project file change
-						if strings.HasSuffix(event.Name, ".yaml") || event.Op&fsnotify.Create != 0 {
-							processProject(projectName)
-						}
+					// Only process if it looks like a project file change
+					if strings.HasSuffix(event.Name, ".yaml") || event.Op&fsnotify.Create != 0 {
+						processProject(projectManager, projectName)
 					}
+					break
 				}
 			}
 		case err, ok := <-watcher.Errors:
@@ -226,10 +273,7 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		case <-ticker.C:
 			// Fallback scan and watch update
 			addProjectWatches()
-			activeProjects, _ := projectManager.ListProjects(false)
-			for _, p := range activeProjects {
-				processProject(p)
-			}
+			scanProjects()
 		}
 	}
 }
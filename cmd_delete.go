@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -50,38 +49,17 @@ If --project flag is provided, deletes from that project instead of the current
 			versionManager := NewVersionManager(version)
 			projectManager := NewProjectDataManager(dataDir, versionManager)
 
+			if _, err := projectManager.LoadProjectV11(targetProject); err == nil {
+				return fmt.Errorf("delete by numeric ID is only supported for legacy projects; '%s' is v1.1", targetProject)
+			}
+
 			projectData, err := projectManager.LoadProjectData(targetProject)
 			if err != nil {
 				return fmt.Errorf("failed to load project data: %w", err)
 			}
 
-			// Create a deep copy for backup BEFORE deletion
-			// Using YAML marshal/unmarshal as a quick way to deep copy
-			projectDataBeforeDeletion := &ProjectData{}
-			if yamlBytes, err := yaml.Marshal(projectData); err == nil {
-				yaml.Unmarshal(yamlBytes, projectDataBeforeDeletion)
-			}
-
-			// Find tasks by ID
-			var tasksToDelete []Task
-			var indicesToDelete []int
-			var missingIDs []int
-
-			for _, id := range taskIDs {
-				found := false
-				for i, task := range projectData.Tasks {
-					if task.ID == id {
-						indicesToDelete = append(indicesToDelete, i)
-						tasksToDelete = append(tasksToDelete, task)
-						found = true
-						break
-					}
-				}
-				if !found {
-					missingIDs = append(missingIDs, id)
-				}
-			}
-
+			// Find tasks by ID for the confirmation prompt
+			tasksToDelete, missingIDs := findLegacyTasks(projectData, taskIDs)
 			if len(missingIDs) > 0 {
 				return fmt.Errorf("tasks with IDs %v not found in project '%s'", missingIDs, targetProject)
 			}
@@ -102,74 +80,54 @@ If --project flag is provided, deletes from that project instead of the current
 				}
 			}
 
-			// Delete tasks (in reverse order to maintain correct indices)
-			// First, sort indices to delete in descending order
-			for i := 0; i < len(indicesToDelete); i++ {
-				for j := i + 1; j < len(indicesToDelete); j++ {
-					if indicesToDelete[i] < indicesToDelete[j] {
-						indicesToDelete[i], indicesToDelete[j] = indicesToDelete[j], indicesToDelete[i]
-					}
+			// Re-resolve and delete under the project lock so edits made while
+			// the prompt was open are not lost.
+			err = projectManager.Update(targetProject, func(tx *ProjectTx) error {
+				if tx.IsV11() {
+					return fmt.Errorf("project '%s' was migrated to v1.1 during deletion", targetProject)
 				}
-			}
+				projectData := tx.Legacy()
 
-			for _, idx := range indicesToDelete {
-				projectData.Tasks = append(projectData.Tasks[:idx], projectData.Tasks[idx+1:]...)
-			}
-
-			// Emit events for deleted tasks
-			for _, deletedTask := range tasksToDelete {
-				projectManager.AppendEvent(targetProject, Event{
-					Timestamp: time.Now(),
-					Type:      "TASK_DELETED",
-					Actor:     "human",
-					TaskID:    fmt.Sprintf("t-%d", deletedTask.ID),
-					Message:   fmt.Sprintf("Task deleted: %s", deletedTask.Text),
-				})
-			}
-
-			// Update depends_on references in remaining tasks
-			for i := range projectData.Tasks {
-				newDependsOn := []int{}
-				removedIDs := []int{}
-				for _, depID := range projectData.Tasks[i].DependsOn {
-					isDeleted := false
-					for _, deletedID := range taskIDs {
-						if depID == deletedID {
-							isDeleted = true
-							removedIDs = append(removedIDs, deletedID)
-							break
-						}
-					}
-					if !isDeleted {
-						newDependsOn = append(newDependsOn, depID)
-					}
+				// Create a deep copy for backup BEFORE deletion
+				// Using YAML marshal/unmarshal as a quick way to deep copy
+				projectDataBeforeDeletion := &ProjectData{}
+				if yamlBytes, err := yaml.Marshal(projectData); err == nil {
+					yaml.Unmarshal(yamlBytes, projectDataBeforeDeletion)
 				}
-				if len(removedIDs) > 0 {
-					projectData.Tasks[i].DependsOn = newDependsOn
-					for _, rid := range removedIDs {
-						projectData.Tasks[i].Notes = append(projectData.Tasks[i].Notes, NoteEntry{
-							Text:      fmt.Sprintf("Dependency removed: %d (task deleted)", rid),
-							Timestamp: time.Now(),
-						})
-					}
+
+				deleted, missing := deleteLegacyTasks(projectData, taskIDs)
+				if len(missing) > 0 {
+					return fmt.Errorf("tasks with IDs %v not found in project '%s'", missing, targetProject)
 				}
-			}
+				tasksToDelete = deleted
+				tx.MarkModified()
 
-			// Save backup for undo
-			undoData := struct {
-				ProjectName string      `yaml:"project_name"`
-				Data        ProjectData `yaml:"data"`
-			}{
-				ProjectName: targetProject,
-				Data:        *projectDataBeforeDeletion,
-			}
-			undoBackupPath := filepath.Join(dataDir, ".undo_backup.yaml")
-			if undoBytes, err := yaml.Marshal(undoData); err == nil {
-				os.WriteFile(undoBackupPath, undoBytes, 0644)
-			}
+				// Emit events for deleted tasks
+				for _, deletedTask := range tasksToDelete {
+					tx.AppendEvent(Event{
+						Timestamp: time.Now(),
+						Type:      "TASK_DELETED",
+						Actor:     "human",
+						TaskID:    fmt.Sprintf("t-%d", deletedTask.ID),
+						Message:   fmt.Sprintf("Task deleted: %s", deletedTask.Text),
+					})
+				}
 
-			// Save updated project data
-			if err := projectManager.SaveProjectData(targetProject, projectData); err != nil {
+				// Save backup for undo
+				undoData := struct {
+					ProjectName string      `yaml:"project_name"`
+					Data        ProjectData `yaml:"data"`
+				}{
+					ProjectName: targetProject,
+					Data:        *projectDataBeforeDeletion,
+				}
+				undoBackupPath := filepath.Join(dataDir, ".undo_backup.yaml")
+				if undoBytes, err := yaml.Marshal(undoData); err == nil {
+					writeFileAtomic(undoBackupPath, undoBytes, 0644)
+				}
+				return nil
+			})
+			if err != nil {
 				return fmt.Errorf("failed to save project data: %w", err)
 			}
 
@@ -189,6 +147,73 @@ func init() {
 	deleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
 }
 
+// findLegacyTasks returns the tasks matching ids and the ids that were not found.
+func findLegacyTasks(projectData *ProjectData, ids []int) ([]Task, []int) {
+	var found []Task
+	var missing []int
+	for _, id := range ids {
+		matched := false
+		for _, task := range projectData.Tasks {
+			if task.ID == id {
+				found = append(found, task)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing
+}
+
+// deleteLegacyTasks removes the tasks with the given ids from projectData and
+// drops references to them from remaining depends_on lists, leaving a note on
+// each affected task. Nothing is removed if any id is missing.
+func deleteLegacyTasks(projectData *ProjectData, ids []int) ([]Task, []int) {
+	deleted, missing := findLegacyTasks(projectData, ids)
+	if len(missing) > 0 {
+		return nil, missing
+	}
+
+	deletedIDs := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		deletedIDs[id] = true
+	}
+
+	remaining := projectData.Tasks[:0]
+	for _, task := range projectData.Tasks {
+		if !deletedIDs[task.ID] {
+			remaining = append(remaining, task)
+		}
+	}
+	projectData.Tasks = remaining
+
+	// Update depends_on references in remaining tasks
+	for i := range projectData.Tasks {
+		newDependsOn := []int{}
+		removedIDs := []int{}
+		for _, depID := range projectData.Tasks[i].DependsOn {
+			if deletedIDs[depID] {
+				removedIDs = append(removedIDs, depID)
+				continue
+			}
+			newDependsOn = append(newDependsOn, depID)
+		}
+		if len(removedIDs) > 0 {
+			projectData.Tasks[i].DependsOn = newDependsOn
+			for _, rid := range removedIDs {
+				projectData.Tasks[i].Notes = append(projectData.Tasks[i].Notes, NoteEntry{
+					Text:      fmt.Sprintf("Dependency removed: %d (task deleted)", rid),
+					Timestamp: time.Now(),
+				})
+			}
+		}
+	}
+
+	return deleted, nil
+}
+
 // confirmDeletions displays a confirmation dialog for multiple task deletions
 func confirmDeletions(tasks []Task) (bool, error) {
 	var confirmed bool
@@ -225,7 +250,8 @@ func confirmDeletions(tasks []Task) (bool, error) {
 	return confirmed, nil
 }
 
-// getTargetProject determines the target project from flags or current context
+// getTargetProject determines the target project from the --project flag,
+// then the repository-local .quickplan/ context, then the global one.
 // Following DRY principle - reusable across commands
 func getTargetProject(cmd *cobra.Command) (string, error) {
 	projectFlag, _ := cmd.Flags().GetString("project")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -43,13 +44,24 @@ var doctorCmd = &cobra.Command{
 			}
 
 			// 2. Schema
-			v11, err := projectManager.LoadProjectV11(projectName)
+			v11File := filepath.Join(dataDir, projectName, "project.yaml")
+			data, err := os.ReadFile(v11File)
 			if err == nil {
-				if err := ValidateProjectV11(v11); err != nil {
-					report["schema"] = map[string]interface{}{"status": "ERROR", "message": fmt.Sprintf("Invalid v1.1: %v", err)}
-				} else {
-					report["schema"] = map[string]string{"status": "OK", "version": "v1.1"}
+				v11, diagnostics := DiagnoseProjectV11(v11File, data)
+				status := "OK"
+				if hasErrorDiagnostics(diagnostics) {
+					status = "ERROR"
+				} else if len(diagnostics) > 0 {
+					status = "WARN"
+				}
+				schema := map[string]interface{}{"status": status}
+				if v11 != nil {
+					schema["version"] = "v" + v11.SchemaVersion
 				}
+				if len(diagnostics) > 0 {
+					schema["diagnostics"] = diagnostics
+				}
+				report["schema"] = schema
 			} else if os.IsNotExist(err) {
 				legacy, err := projectManager.LoadProjectData(projectName)
 				if err != nil {
@@ -86,10 +98,7 @@ var doctorCmd = &cobra.Command{
 			}
 
 			// 4. Remote service
-			registryURL := os.Getenv("QUICKPLAN_REGISTRY_URL")
-			if registryURL == "" {
-				registryURL = "http://localhost:8081"
-			}
+			registryURL := projectManager.Setting(projectName, "registry_url").Value
 			client := http.Client{Timeout: 2 * time.Second}
 			req, reqErr := http.NewRequest(http.MethodGet, registryURL+"/api/v1/info", nil)
 			if reqErr != nil {
@@ -134,12 +143,20 @@ var doctorCmd = &cobra.Command{
 
 		// 2. Check Schema Validity
 		fmt.Print("  [2/4] Schema validity: ")
-		v11, err := projectManager.LoadProjectV11(projectName)
+		v11File := filepath.Join(dataDir, projectName, "project.yaml")
+		data, err := os.ReadFile(v11File)
 		if err == nil {
-			if err := ValidateProjectV11(v11); err != nil {
-				fmt.Printf("❌ Invalid v1.1: %v\n", err)
-			} else {
-				fmt.Println("✅ Valid v1.1 project.yaml")
+			v11, diagnostics := DiagnoseProjectV11(v11File, data)
+			switch {
+			case hasErrorDiagnostics(diagnostics):
+				fmt.Println("❌ Invalid project.yaml")
+			case len(diagnostics) > 0:
+				fmt.Printf("⚠️  Valid v%s project.yaml with warnings\n", v11.SchemaVersion)
+			default:
+				fmt.Printf("✅ Valid v%s project.yaml\n", v11.SchemaVersion)
+			}
+			for _, d := range diagnostics {
+				fmt.Printf("        %s\n", d)
 			}
 		} else if os.IsNotExist(err) {
 			// Check legacy
@@ -181,10 +198,7 @@ var doctorCmd = &cobra.Command{
 
 			// 4. Check Remote Connectivity
 			fmt.Print("  [4/4] Remote status: ")
-		registryURL := os.Getenv("QUICKPLAN_REGISTRY_URL")
-		if registryURL == "" {
-			registryURL = "http://localhost:8081"
-		}
+		registryURL := projectManager.Setting(projectName, "registry_url").Value
 
 		client := http.Client{Timeout: 2 * time.Second}
 		req, reqErr := http.NewRequest(http.MethodGet, registryURL+"/api/v1/info", nil)
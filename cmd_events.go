@@ -30,17 +30,12 @@ var eventsTailCmd = &cobra.Command{
 		versionManager := NewVersionManager(version)
 		projectManager := NewProjectDataManager(dataDir, versionManager)
 
-		eventLog, err := projectManager.LoadEvents(projectName)
+		n, _ := cmd.Flags().GetInt("n")
+		events, _, err := projectManager.TailEvents(projectName, n)
 		if err != nil {
 			return err
 		}
 
-		n, _ := cmd.Flags().GetInt("n")
-		events := eventLog.Events
-		if len(events) > n {
-			events = events[len(events)-n:]
-		}
-
 		if len(events) == 0 {
 			fmt.Println("No events found.")
 			return nil
@@ -81,7 +76,7 @@ var eventsExportCmd = &cobra.Command{
 		versionManager := NewVersionManager(version)
 		projectManager := NewProjectDataManager(dataDir, versionManager)
 
-		eventLog, err := projectManager.LoadEvents(projectName)
+		eventLog, err := projectManager.loadCanonicalEventLog(projectName)
 		if err != nil {
 			return err
 		}
@@ -146,7 +141,78 @@ var eventsExportProjectionCmd = &cobra.Command{
 	},
 }
 
+var eventsReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Rebuild task statuses from the event log and diff them against stored state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		repair, _ := cmd.Flags().GetBool("repair")
+		report, err := projectManager.ReplayEvents(projectName, repair)
+		if err != nil {
+			return err
+		}
+
+		if globalJSON {
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		} else {
+			fmt.Printf("Replayed %d events covering %d tasks in project '%s'\n", report.EventsReplayed, report.TasksReplayed, projectName)
+			for _, d := range report.Divergences {
+				switch d.Kind {
+				case ReplayStatusMismatch:
+					fmt.Printf("  %s: stored %s, event log says %s\n", d.TaskID, d.Stored, d.Replayed)
+				case ReplayUntracked:
+					fmt.Printf("  %s: stored %s, no status events\n", d.TaskID, d.Stored)
+				case ReplayMissing:
+					fmt.Printf("  %s: not in project, event log says %s\n", d.TaskID, d.Replayed)
+				}
+			}
+			if report.Repaired > 0 {
+				fmt.Printf("Repaired %d task statuses from the event log\n", report.Repaired)
+			}
+			if len(report.Divergences) == 0 {
+				fmt.Println("Project state matches the event log.")
+			}
+		}
+
+		// Untracked tasks predate their first event; they cannot be checked
+		// but do not contradict the log.
+		conflicts := 0
+		for _, d := range report.Divergences {
+			if d.Kind != ReplayUntracked {
+				conflicts++
+			}
+		}
+		if conflicts > 0 {
+			if !repair {
+				return fmt.Errorf("%d divergences between project state and event log (use --repair to fix status mismatches)", conflicts)
+			}
+			return fmt.Errorf("%d divergences could not be repaired from the event log", conflicts)
+		}
+		return nil
+	},
+}
+
 func init() {
+	eventsCmd.AddCommand(eventsReplayCmd)
+	eventsReplayCmd.Flags().StringP("project", "p", "", "Project name")
+	eventsReplayCmd.Flags().Bool("repair", false, "Rewrite diverging task statuses to match the event log")
+
 	eventsCmd.AddCommand(eventsTailCmd)
 	eventsTailCmd.Flags().IntP("n", "n", 50, "Number of events to show")
 	eventsTailCmd.Flags().StringP("project", "p", "", "Project name")
@@ -14,6 +14,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/trstoyan/quickplan/pkg/crypto"
+	"gopkg.in/yaml.v3"
 )
 
 var exportCmd = &cobra.Command{
@@ -42,7 +43,7 @@ var exportCmd = &cobra.Command{
 			projectFile = filepath.Join(projectDir, "tasks.yaml") // legacy fallback
 		}
 
-		plaintext, err := os.ReadFile(projectFile)
+		plaintext, err := readExportPlaintext(dataDir, projectName, projectFile)
 		if err != nil {
 			return fmt.Errorf("failed to read project data: %w", err)
 		}
@@ -91,7 +92,9 @@ var exportCmd = &cobra.Command{
 		projectID, err := os.ReadFile(projectIDPath)
 		if err != nil {
 			projectID = []byte(fmt.Sprintf("%d", time.Now().UnixNano()))
-			os.WriteFile(projectIDPath, projectID, 0600)
+			if err := writeFileAtomic(projectIDPath, projectID, 0600); err != nil {
+				return fmt.Errorf("failed to save project ID: %w", err)
+			}
 		}
 
 		revID := fmt.Sprintf("%d", time.Now().UnixNano())
@@ -160,3 +163,18 @@ func init() {
 	exportCmd.Flags().String("identity", "", "Path to identity.json")
 	exportCmd.Flags().String("out", "", "Output filename")
 }
+
+// readExportPlaintext returns the project file to encrypt. A task-files
+// project is exported as one single-file project.yaml so the blob stays
+// self-contained.
+func readExportPlaintext(dataDir, projectName, projectFile string) ([]byte, error) {
+	if filepath.Base(projectFile) == "project.yaml" {
+		projectManager := NewProjectDataManager(dataDir, NewVersionManager(version))
+		project, err := projectManager.LoadProjectV11(projectName)
+		if err == nil && project.Layout == layoutTaskFiles {
+			project.Layout = ""
+			return yaml.Marshal(project)
+		}
+	}
+	return os.ReadFile(projectFile)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [task]",
+	Short: "Show the git history of a project or one task",
+	Long: `Show the git history of a project, or only the commits that changed one task.
+
+History is kept for projects whose project.yml has sync_source.type "git".
+Enable it with --enable: every later save is then committed in the project
+directory with a message such as "t-4: PENDING -> IN_PROGRESS by worker-2",
+authored by the agent or user who made the change. Everything stays in a
+local repository; nothing is pushed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		if enable, _ := cmd.Flags().GetBool("enable"); enable {
+			if err := projectManager.EnableHistory(projectName); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Git history enabled for project '%s'\n", projectName)
+			return nil
+		}
+
+		taskID := ""
+		if len(args) == 1 {
+			taskID = args[0]
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		entries, err := projectManager.History(projectName, taskID, limit)
+		if err != nil {
+			return err
+		}
+
+		if globalJSON {
+			if entries == nil {
+				entries = []HistoryEntry{}
+			}
+			out, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No history recorded yet.")
+			return nil
+		}
+		for _, entry := range entries {
+			fmt.Printf("%s  %s  %-12s %s\n", shortRev(entry.Rev), entry.Date.Format("2006-01-02 15:04"), entry.Author, entry.Subject)
+		}
+		return nil
+	},
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <rev>",
+	Short: "Restore a project's tasks to how they were at a history revision",
+	Long: `Restore a project's tasks to their state right after commit <rev> (see
+'quickplan history') and record that as a new commit, so the revert itself
+can be reverted. The event log and project.yml are left as they are.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		target, err := projectManager.RevertHistory(projectName, args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Restored project '%s' to %s (%s)\n", projectName, shortRev(target.Rev), target.Subject)
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringP("project", "p", "", "Project to inspect")
+	historyCmd.Flags().Bool("enable", false, "Start keeping git history for the project")
+	historyCmd.Flags().IntP("limit", "n", 0, "Show at most this many commits")
+
+	revertCmd.Flags().StringP("project", "p", "", "Project to revert")
+}
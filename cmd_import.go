@@ -116,11 +116,7 @@ var importCmd = &cobra.Command{
 
 		// 7. Atomic Write
 		projectFile := filepath.Join(projectDir, "project.yaml")
-		tmpFile := projectFile + ".tmp"
-		if err := os.WriteFile(tmpFile, plaintext, 0644); err != nil {
-			return err
-		}
-		if err := os.Rename(tmpFile, projectFile); err != nil {
+		if err := writeFileAtomicWithBackup(projectFile, plaintext, 0644); err != nil {
 			return err
 		}
 
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,6 +22,29 @@ var initCmd = &cobra.Command{
 		}
 
 		isInteractive, _ := cmd.Flags().GetBool("interactive")
+		here, _ := cmd.Flags().GetBool("here")
+
+		if here {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			if projectName == "" {
+				projectName = filepath.Base(cwd)
+			}
+			dataDir, err := initRepoDataDir(cwd, projectName)
+			if err != nil {
+				return err
+			}
+
+			if globalJSON {
+				fmt.Printf("{\"status\": \"success\", \"project\": \"%s\", \"data_dir\": \"%s\"}\n", projectName, dataDir)
+			} else {
+				fmt.Printf("✓ Project '%s' initialized in %s.\n", projectName, dataDir)
+				fmt.Println("  Commands run anywhere below this directory now use it. Commit .quickplan/ to share the plan.")
+			}
+			return nil
+		}
 
 		// Initialize managers
 		dataDir, err := getDataDir()
@@ -143,4 +167,5 @@ var initCmd = &cobra.Command{
 
 func init() {
 	initCmd.Flags().BoolP("interactive", "i", false, "Run in interactive mode")
+	initCmd.Flags().Bool("here", false, "Create a repository-local project in ./.quickplan/")
 }
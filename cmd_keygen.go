@@ -65,7 +65,7 @@ var keygenCmd = &cobra.Command{
 			return err
 		}
 
-		if err := os.WriteFile(outPath, data, 0600); err != nil {
+		if err := writeFileAtomic(outPath, data, 0600); err != nil {
 			return err
 		}
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -17,9 +18,14 @@ Use --all-projects to list tasks from all projects.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		showAll, _ := cmd.Flags().GetBool("all")
 		allProjects, _ := cmd.Flags().GetBool("all-projects")
+		atValue, _ := cmd.Flags().GetString("at")
+		at, err := atFlag(atValue)
+		if err != nil {
+			return err
+		}
 
 		if allProjects {
-			return listAllProjects(showAll)
+			return listAllProjects(showAll, at)
 		}
 
 		// Determine target project
@@ -28,14 +34,13 @@ Use --all-projects to list tasks from all projects.`,
 		if projectFlag != "" {
 			targetProject = projectFlag
 		} else {
-			var err error
 			targetProject, err = getCurrentProject()
 			if err != nil {
 				return fmt.Errorf("failed to get current project: %w", err)
 			}
 		}
 
-		return listProjectTasks(targetProject, showAll)
+		return listProjectTasks(targetProject, showAll, at)
 	},
 }
 
@@ -43,10 +48,56 @@ func init() {
 	listCmd.Flags().StringP("project", "p", "", "List tasks from this project instead of current")
 	listCmd.Flags().BoolP("all", "a", false, "Show all tasks including completed ones")
 	listCmd.Flags().Bool("all-projects", false, "List tasks from all projects")
+	listCmd.Flags().String("at", "", "Show tasks as they were at this time (RFC3339, date, or duration ago like 36h or 7d)")
+}
+
+type listTaskJSON struct {
+	ID         interface{} `json:"id"`
+	Text       string      `json:"text"`
+	Status     string      `json:"status"`
+	Done       bool        `json:"done"`
+	AssignedTo string      `json:"assigned_to,omitempty"`
+	DependsOn  interface{} `json:"depends_on,omitempty"`
+	Provider   string      `json:"provider,omitempty"`
+
+	Priority    string     `json:"priority,omitempty"`
+	Due         *time.Time `json:"due,omitempty"`
+	Start       *time.Time `json:"start,omitempty"`
+	Labels      []string   `json:"labels,omitempty"`
+	Estimate    string     `json:"estimate,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Parent      string     `json:"parent,omitempty"`
+}
+
+// listTaskJSONFromViews converts task views, e.g. a time-travel result, to
+// the list --json shape.
+func listTaskJSONFromViews(views []TaskView) []listTaskJSON {
+	tasks := make([]listTaskJSON, 0, len(views))
+	for _, view := range views {
+		tasks = append(tasks, listTaskJSON{
+			ID:         view.ID,
+			Text:       view.Text,
+			Status:     view.Status,
+			Done:       view.Status == "DONE",
+			AssignedTo: view.AssignedTo,
+			DependsOn:  view.DependsOn,
+			Provider:   view.Behavior.Environment.Provider,
+
+			Priority:    view.Priority,
+			Due:         view.Due,
+			Start:       view.Start,
+			Labels:      view.Labels,
+			Estimate:    view.Estimate,
+			Description: view.Description,
+			Parent:      view.Parent,
+		})
+	}
+	return tasks
 }
 
-// listProjectTasks displays tasks for a single project
-func listProjectTasks(targetProject string, showAll bool) error {
+// listProjectTasks displays tasks for a single project, optionally as they
+// were at a past instant.
+func listProjectTasks(targetProject string, showAll bool, at *time.Time) error {
 	// Validate project exists
 	if !projectExists(targetProject) {
 		return fmt.Errorf("project '%s' does not exist", targetProject)
@@ -62,20 +113,20 @@ func listProjectTasks(targetProject string, showAll bool) error {
 	projectManager := NewProjectDataManager(dataDir, versionManager)
 
 	if globalJSON {
-		type taskJSON struct {
-			ID         interface{} `json:"id"`
-			Text       string      `json:"text"`
-			Status     string      `json:"status"`
-			Done       bool        `json:"done"`
-			AssignedTo string      `json:"assigned_to,omitempty"`
-			DependsOn  interface{} `json:"depends_on,omitempty"`
-			Provider   string      `json:"provider,omitempty"`
+		if at != nil {
+			views, _, err := projectManager.GetTaskViewsAt(targetProject, *at)
+			if err != nil {
+				return fmt.Errorf("failed to load project: %w", err)
+			}
+			payload, _ := json.Marshal(listTaskJSONFromViews(views))
+			fmt.Println(string(payload))
+			return nil
 		}
 
 		if v11, err := projectManager.LoadProjectV11(targetProject); err == nil {
-			tasks := make([]taskJSON, 0, len(v11.Tasks))
+			tasks := make([]listTaskJSON, 0, len(v11.Tasks))
 			for _, task := range v11.Tasks {
-				tasks = append(tasks, taskJSON{
+				tasks = append(tasks, listTaskJSON{
 					ID:         task.ID,
 					Text:       task.Name,
 					Status:     task.Status,
@@ -83,6 +134,14 @@ func listProjectTasks(targetProject string, showAll bool) error {
 					AssignedTo: task.AssignedTo,
 					DependsOn:  task.DependsOn,
 					Provider:   task.Behavior.Environment.Provider,
+
+					Priority:    task.Priority,
+					Due:         task.Due,
+					Start:       task.Start,
+					Labels:      task.Labels,
+					Estimate:    task.Estimate,
+					Description: task.Description,
+					Parent:      task.Parent,
 				})
 			}
 			payload, _ := json.Marshal(tasks)
@@ -95,9 +154,9 @@ func listProjectTasks(targetProject string, showAll bool) error {
 			return fmt.Errorf("failed to load project: %w", err)
 		}
 
-		tasks := make([]taskJSON, 0, len(legacy.Tasks))
+		tasks := make([]listTaskJSON, 0, len(legacy.Tasks))
 		for _, task := range legacy.Tasks {
-			tasks = append(tasks, taskJSON{
+			tasks = append(tasks, listTaskJSON{
 				ID:         task.ID,
 				Text:       task.Text,
 				Status:     GetTaskStatus(task),
@@ -113,12 +172,22 @@ func listProjectTasks(targetProject string, showAll bool) error {
 		return nil
 	}
 
-	taskViews, isV11, err := projectManager.GetTaskViews(targetProject)
+	var taskViews []TaskView
+	var isV11 bool
+	if at != nil {
+		taskViews, isV11, err = projectManager.GetTaskViewsAt(targetProject, *at)
+	} else {
+		taskViews, isV11, err = projectManager.GetTaskViews(targetProject)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to load project: %w", err)
 	}
 
-	fmt.Printf("Tasks in project '%s':\n", targetProject)
+	if at != nil {
+		fmt.Printf("Tasks in project '%s' as of %s:\n", targetProject, at.Format(time.RFC3339))
+	} else {
+		fmt.Printf("Tasks in project '%s':\n", targetProject)
+	}
 	// We might need to load metadata for archived status if not in TaskView
 	if !isV11 {
 		legacy, _ := projectManager.LoadProjectData(targetProject)
@@ -133,6 +202,11 @@ func listProjectTasks(targetProject string, showAll bool) error {
 		return nil
 	}
 
+	if hasSubtasks(taskViews) {
+		printTaskTree(taskViews)
+		return nil
+	}
+
 	// Separate incomplete and completed tasks
 	var incompleteTasks []TaskView
 	var completedTasks []TaskView
@@ -168,6 +242,46 @@ func listProjectTasks(targetProject string, showAll bool) error {
 	return nil
 }
 
+// printTaskTree lists tasks under their parents. Each top-level task goes
+// in the open or the completed block together with its subtasks, and
+// parents show how many of their subtasks are done.
+func printTaskTree(views []TaskView) {
+	statusByID := buildStatusIndex(views)
+	var open, completed []taskTreeRow
+	rootDone := false
+	for _, row := range taskTree(views) {
+		if row.Prefix == "" {
+			rootDone = row.Task.Status == "DONE"
+		}
+		if rootDone {
+			completed = append(completed, row)
+		} else {
+			open = append(open, row)
+		}
+	}
+
+	printRow := func(row taskTreeRow) {
+		line := fmt.Sprintf("  %s%s. [%s] %s", row.Prefix, row.Task.ID, getStatusIcon(row.Task.Status), row.Task.Text)
+		if len(row.Task.Subtasks) > 0 {
+			line += fmt.Sprintf(" (%s done)", subtaskProgress(row.Task, statusByID))
+		}
+		fmt.Println(line)
+	}
+
+	for _, row := range open {
+		printRow(row)
+	}
+	if len(completed) > 0 {
+		if len(open) > 0 {
+			fmt.Println()
+		}
+		fmt.Println("Completed tasks:")
+		for _, row := range completed {
+			printRow(row)
+		}
+	}
+}
+
 func getStatusIcon(status string) string {
 	switch status {
 	case "DONE":
@@ -182,7 +296,7 @@ func getStatusIcon(status string) string {
 }
 
 // listAllProjects displays tasks from all projects
-func listAllProjects(showAll bool) error {
+func listAllProjects(showAll bool, at *time.Time) error {
 	dataDir, err := getDataDir()
 	if err != nil {
 		return fmt.Errorf("failed to get data directory: %w", err)
@@ -212,27 +326,26 @@ func listAllProjects(showAll bool) error {
 	sort.Strings(projects)
 
 	if globalJSON {
-		type taskJSON struct {
-			ID         interface{} `json:"id"`
-			Text       string      `json:"text"`
-			Status     string      `json:"status"`
-			Done       bool        `json:"done"`
-			AssignedTo string      `json:"assigned_to,omitempty"`
-			DependsOn  interface{} `json:"depends_on,omitempty"`
-			Provider   string      `json:"provider,omitempty"`
-		}
-
 		type projectJSON struct {
-			Project string     `json:"project"`
-			Tasks   []taskJSON `json:"tasks"`
+			Project string         `json:"project"`
+			Tasks   []listTaskJSON `json:"tasks"`
 		}
 
 		result := make([]projectJSON, 0, len(projects))
 		for _, project := range projects {
+			if at != nil {
+				views, _, err := projectManager.GetTaskViewsAt(project, *at)
+				if err != nil {
+					continue
+				}
+				result = append(result, projectJSON{Project: project, Tasks: listTaskJSONFromViews(views)})
+				continue
+			}
+
 			if v11, err := projectManager.LoadProjectV11(project); err == nil {
-				tasks := make([]taskJSON, 0, len(v11.Tasks))
+				tasks := make([]listTaskJSON, 0, len(v11.Tasks))
 				for _, task := range v11.Tasks {
-					tasks = append(tasks, taskJSON{
+					tasks = append(tasks, listTaskJSON{
 						ID:         task.ID,
 						Text:       task.Name,
 						Status:     task.Status,
@@ -240,6 +353,14 @@ func listAllProjects(showAll bool) error {
 						AssignedTo: task.AssignedTo,
 						DependsOn:  task.DependsOn,
 						Provider:   task.Behavior.Environment.Provider,
+
+						Priority:    task.Priority,
+						Due:         task.Due,
+						Start:       task.Start,
+						Labels:      task.Labels,
+						Estimate:    task.Estimate,
+						Description: task.Description,
+						Parent:      task.Parent,
 					})
 				}
 				result = append(result, projectJSON{Project: project, Tasks: tasks})
@@ -250,9 +371,9 @@ func listAllProjects(showAll bool) error {
 			if err != nil {
 				continue
 			}
-			tasks := make([]taskJSON, 0, len(legacy.Tasks))
+			tasks := make([]listTaskJSON, 0, len(legacy.Tasks))
 			for _, task := range legacy.Tasks {
-				tasks = append(tasks, taskJSON{
+				tasks = append(tasks, listTaskJSON{
 					ID:         task.ID,
 					Text:       task.Text,
 					Status:     GetTaskStatus(task),
@@ -284,7 +405,7 @@ func listAllProjects(showAll bool) error {
 			}
 		}
 
-		err := listProjectTasks(project, showAll)
+		err := listProjectTasks(project, showAll, at)
 		if err != nil {
 			// Skip projects that can't be read, but continue with others
 			fmt.Printf("Error loading project '%s': %v\n", project, err)
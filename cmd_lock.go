@@ -29,15 +29,19 @@ var lockStatusCmd = &cobra.Command{
 		versionManager := NewVersionManager(version)
 		projectManager := NewProjectDataManager(dataDir, versionManager)
 
-		stale, lock, err := projectManager.IsLockStale(projectName)
+		state, err := projectManager.LockStatus(projectName)
 		if err != nil {
 			fmt.Printf("No active lock found for project '%s'.\n", projectName)
 			return nil
 		}
+		lock := state.Lock
+		if lock == nil {
+			lock = &Lock{}
+		}
 
 		fmt.Printf("Project: %s\n", projectName)
 		fmt.Printf("Status:  ")
-		if stale {
+		if state.Stale {
 			fmt.Println("STALE")
 		} else {
 			fmt.Println("LOCKED")
@@ -45,8 +49,14 @@ var lockStatusCmd = &cobra.Command{
 		fmt.Printf("Owner PID: %d\n", lock.PID)
 		fmt.Printf("Host:      %s\n", lock.Host)
 		fmt.Printf("Created:   %s\n", lock.CreatedAt.Format(time.RFC3339))
+		if !lock.CreatedAt.IsZero() {
+			fmt.Printf("Held for:  %s\n", time.Since(lock.CreatedAt).Round(time.Second))
+		}
+		if !lock.RenewedAt.IsZero() {
+			fmt.Printf("Renewed:   %s (%s ago)\n", lock.RenewedAt.Format(time.RFC3339), time.Since(lock.RenewedAt).Round(time.Second))
+		}
 
-		expiresAt := lock.CreatedAt.Add(time.Duration(lock.TTL) * time.Second)
+		expiresAt := lock.LastSeen().Add(time.Duration(lock.TTL) * time.Second)
 		remaining := time.Until(expiresAt)
 		if remaining > 0 {
 			fmt.Printf("TTL:       %d seconds (expires in %s)\n", lock.TTL, remaining.Round(time.Second))
@@ -54,6 +64,11 @@ var lockStatusCmd = &cobra.Command{
 			fmt.Printf("TTL:       %d seconds (EXPIRED)\n", lock.TTL)
 		}
 
+		fmt.Printf("Waiters:   %d\n", len(state.Waiters))
+		for _, w := range state.Waiters {
+			fmt.Printf("  - pid %d on host %s (waiting %s)\n", w.PID, w.Host, time.Since(w.CreatedAt).Round(time.Second))
+		}
+
 		return nil
 	},
 }
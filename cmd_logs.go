@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// runLogFollowPoll is how often --follow checks a log for new output.
+var runLogFollowPoll = 250 * time.Millisecond
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <task-id>",
+	Short: "Show the output of a task's runs",
+	Long: `Show the log of a task's latest run, or of run --attempt N. Each run is
+kept in <project>/runs/<task>/<attempt>.log, with every line stamped with its
+time and stream (stdout or stderr), and ends with the exit code. With
+--follow, output of a running attempt is printed as it arrives until it
+finishes.
+
+The settings runs.keep and runs.max_log_bytes limit how many runs are kept
+per task and how much output each keeps.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetProject, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+		if !projectExists(targetProject) {
+			return fmt.Errorf("project '%s' does not exist", targetProject)
+		}
+
+		attempt, _ := cmd.Flags().GetInt("attempt")
+		follow, _ := cmd.Flags().GetBool("follow")
+		if follow && globalJSON {
+			return fmt.Errorf("--follow cannot be combined with --json")
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return fmt.Errorf("failed to get data directory: %w", err)
+		}
+		projectManager := NewProjectDataManager(dataDir, NewVersionManager(version))
+
+		taskID := normalizeTaskID(args[0])
+		attempts, err := projectManager.RunLogAttempts(targetProject, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to list runs: %w", err)
+		}
+		if len(attempts) == 0 {
+			return fmt.Errorf("no runs logged for task %s", taskID)
+		}
+		if attempt == 0 {
+			attempt = attempts[len(attempts)-1]
+		} else if !slices.Contains(attempts, attempt) {
+			return fmt.Errorf("no log for attempt %d of task %s (kept: %v)", attempt, taskID, attempts)
+		}
+		path := projectManager.runLogPath(targetProject, taskID, attempt)
+
+		if globalJSON {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			payload, _ := json.Marshal(map[string]interface{}{
+				"project":  targetProject,
+				"task":     taskID,
+				"attempt":  attempt,
+				"attempts": attempts,
+				"path":     path,
+				"finished": runLogFinished(content),
+				"log":      string(content),
+			})
+			fmt.Println(string(payload))
+			return nil
+		}
+
+		if !follow {
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(os.Stdout, file)
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return followRunLog(ctx, os.Stdout, path)
+	},
+}
+
+func init() {
+	logsCmd.Flags().StringP("project", "p", "", "Show logs from this project instead of current")
+	logsCmd.Flags().Int("attempt", 0, "Attempt to show (default latest)")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing output until the run finishes")
+}
+
+// followRunLog copies the log at path to w, then keeps copying what is
+// appended until the log's footer arrives or ctx ends.
+func followRunLog(ctx context.Context, w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var recent []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			recent = append(recent, buf[:n]...)
+			if len(recent) > 64*1024 {
+				recent = recent[len(recent)-64*1024:]
+			}
+		}
+		if err == io.EOF {
+			if runLogFinished(recent) {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(runLogFollowPoll):
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
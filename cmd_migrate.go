@@ -1,9 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -26,6 +28,10 @@ var migrateV11Cmd = &cobra.Command{
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		force, _ := cmd.Flags().GetBool("force")
+		schema, _ := cmd.Flags().GetString("schema")
+		if !isSupportedSchemaVersion(schema) {
+			return fmt.Errorf("unsupported --schema %q (expected one of %s)", schema, strings.Join(supportedSchemaVersions(), ", "))
+		}
 
 		dataDir, err := getDataDir()
 		if err != nil {
@@ -60,7 +66,7 @@ var migrateV11Cmd = &cobra.Command{
 
 		// Map to v1.1
 		v11 := ProjectV11{
-			SchemaVersion: "1.1",
+			SchemaVersion: schema,
 			Project: ProjectMeta{
 				Name:      legacyConfig.Name,
 				Version:   "0.1.0",
@@ -69,7 +75,7 @@ var migrateV11Cmd = &cobra.Command{
 			},
 			Lock: LockConfig{
 				File:       ".quickplan.lock",
-				TTLSeconds: 300,
+				TTLSeconds: projectManager.lockTTL(projectName),
 			},
 			Tasks:  make([]TaskV11, len(legacyData.Tasks)),
 			Events: legacyEvents.Events,
@@ -106,6 +112,9 @@ var migrateV11Cmd = &cobra.Command{
 				Attempts:   attempts,
 				UpdatedAt:  now,
 			}
+			if !versionLess(schema, schemaV12) {
+				v11.Tasks[i].Description = legacyNotesDescription(t.Notes)
+			}
 
 			// If no events existed, create basic audit trail
 			if len(legacyEvents.Events) == 0 {
@@ -134,28 +143,322 @@ var migrateV11Cmd = &cobra.Command{
 			return nil
 		}
 
-		// Atomic write
-		tmpFile := v11File + ".tmp"
 		out, err := yaml.Marshal(v11)
 		if err != nil {
 			return err
 		}
-		if err := os.WriteFile(tmpFile, out, 0644); err != nil {
+		if err := writeFileAtomicWithBackup(v11File, out, 0644); err != nil {
 			return err
 		}
-		if err := os.Rename(tmpFile, v11File); err != nil {
-			return err
+
+		if _, err := projectManager.MigrateEmbeddedEvents(projectName); err != nil {
+			return fmt.Errorf("failed to move events into the event store: %w", err)
 		}
 
-		fmt.Printf("Successfully migrated project '%s' to schema v1.1\n", projectName)
+		fmt.Printf("Successfully migrated project '%s' to schema v%s\n", projectName, schema)
 		fmt.Println("Note: Legacy files (tasks.yaml, project.yml, events.yaml) were preserved.")
 		return nil
 	},
 }
 
+var migrateV12Cmd = &cobra.Command{
+	Use:   "v1.2",
+	Short: "Upgrade a v1.1 project.yaml to schema v1.2 in place",
+	Long: `Upgrade a v1.1 project.yaml to schema v1.2.
+
+The upgrade only rewrites schema_version and bumps the revision; comments,
+key order and unknown fields are preserved. A .bak copy of the previous file
+is kept next to it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		out, err := projectManager.UpgradeProjectToV12(projectName, dryRun)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			fmt.Println("--- DRY RUN: project.yaml ---")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		fmt.Printf("Successfully upgraded project '%s' to schema v1.2\n", projectName)
+		fmt.Println("Undo with 'quickplan migrate rollback'.")
+		return nil
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show schema versions, pending migrations and the migration journal",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		files, applied, err := versionManager.MigrationStatus(filepath.Join(dataDir, projectName))
+		if err != nil {
+			return err
+		}
+
+		if globalJSON {
+			type pendingJSON struct {
+				From        string `json:"from"`
+				To          string `json:"to"`
+				Description string `json:"description"`
+				Auto        bool   `json:"auto"`
+			}
+			type fileJSON struct {
+				MigrationFileStatus
+				Pending []pendingJSON `json:"pending"`
+			}
+			result := struct {
+				Project string            `json:"project"`
+				Files   []fileJSON        `json:"files"`
+				Applied []MigrationRecord `json:"applied"`
+			}{Project: projectName, Files: []fileJSON{}, Applied: applied}
+			for _, f := range files {
+				entry := fileJSON{MigrationFileStatus: f, Pending: []pendingJSON{}}
+				for _, m := range f.Pending {
+					entry.Pending = append(entry.Pending, pendingJSON{From: m.From, To: m.To, Description: m.Description, Auto: m.Auto})
+				}
+				result.Files = append(result.Files, entry)
+			}
+			payload, _ := json.Marshal(result)
+			fmt.Println(string(payload))
+			return nil
+		}
+
+		fmt.Printf("Project: %s\n", projectName)
+		for _, f := range files {
+			fmt.Printf("  %-13s %s (latest %s)\n", f.File, displayVersion(f.Version), f.Latest)
+			for _, m := range f.Pending {
+				mode := "opt-in, run 'quickplan migrate up'"
+				if m.Auto {
+					mode = "applied on next load"
+				}
+				fmt.Printf("    pending: %s -> %s  %s (%s)\n", displayVersion(m.From), m.To, m.Description, mode)
+			}
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("  No migrations applied yet.")
+			return nil
+		}
+		fmt.Println("  Applied:")
+		for _, r := range applied {
+			fmt.Printf("    %s  %-13s %s -> %s  %s\n", r.AppliedAt.Format("2006-01-02 15:04:05"), r.File, displayVersion(r.From), r.To, r.Description)
+		}
+		return nil
+	},
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply pending migrations, including opt-in schema upgrades",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		target, _ := cmd.Flags().GetString("to")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		out, records, err := projectManager.MigrateProject(projectName, target, dryRun)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			fmt.Printf("Project '%s' is up to date\n", projectName)
+			return nil
+		}
+
+		if dryRun {
+			fmt.Printf("--- DRY RUN: %s ---\n", records[0].File)
+			fmt.Println(string(out))
+			return nil
+		}
+
+		for _, r := range records {
+			fmt.Printf("Applied %s %s -> %s: %s\n", r.File, displayVersion(r.From), r.To, r.Description)
+		}
+		fmt.Printf("Backup of the previous file: %s\n", records[0].Backup)
+		return nil
+	},
+}
+
+var migrateRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Revert the most recently applied migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		steps, _ := cmd.Flags().GetInt("steps")
+		if steps < 1 {
+			return fmt.Errorf("--steps must be at least 1")
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		reverted, err := projectManager.RollbackMigrations(projectName, steps)
+		for _, r := range reverted {
+			fmt.Printf("Reverted %s %s -> %s\n", r.File, r.To, displayVersion(r.From))
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Printf("No migrations to roll back for project '%s'\n", projectName)
+			return nil
+		}
+		for _, r := range reverted {
+			if r.File == "tasks.yaml" {
+				fmt.Println("Note: automatic migrations are re-applied the next time this build loads the project.")
+				break
+			}
+		}
+		return nil
+	},
+}
+
+// legacyNotesDescription folds legacy task notes into a markdown list for
+// the description field (schema 1.2 and later).
+func legacyNotesDescription(notes []NoteEntry) string {
+	lines := make([]string, 0, len(notes))
+	for _, note := range notes {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", note.Text, note.Timestamp.Format("2006-01-02")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var migrateEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Move events embedded in project.yaml into the append-only event store",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		moved, err := projectManager.MigrateEmbeddedEvents(projectName)
+		if err != nil {
+			return err
+		}
+
+		if moved == 0 {
+			fmt.Printf("No embedded events to move for project '%s'\n", projectName)
+			return nil
+		}
+		fmt.Printf("Moved %d events from project.yaml to %s/\n", moved, filepath.Join(projectName, eventStoreDirName))
+		return nil
+	},
+}
+
+var migrateLayoutCmd = &cobra.Command{
+	Use:   "layout <single-file|task-files>",
+	Short: "Convert a v1.1 project between storage layouts",
+	Long: `Convert a v1.1 project between storage layouts.
+
+single-file keeps every task in project.yaml. task-files keeps one file per
+task under tasks/ (tasks/t-1.yaml, tasks/t-2.yaml, ...) so that edits to
+different tasks on separate git branches merge without conflicts.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return err
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		if err := projectManager.ConvertLayout(projectName, args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Converted project '%s' to the %s layout\n", projectName, args[0])
+		return nil
+	},
+}
+
 func init() {
+	migrateCmd.AddCommand(migrateEventsCmd)
+	migrateEventsCmd.Flags().StringP("project", "p", "", "Project to migrate")
+
 	migrateCmd.AddCommand(migrateV11Cmd)
 	migrateV11Cmd.Flags().StringP("project", "p", "", "Project to migrate")
 	migrateV11Cmd.Flags().Bool("dry-run", false, "Preview migration without writing")
 	migrateV11Cmd.Flags().Bool("force", false, "Overwrite existing project.yaml")
+	migrateV11Cmd.Flags().String("schema", schemaV11, "Target schema version (1.1, 1.2 or 1.3)")
+
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateStatusCmd.Flags().StringP("project", "p", "", "Project to inspect")
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateUpCmd.Flags().StringP("project", "p", "", "Project to migrate")
+	migrateUpCmd.Flags().String("to", "", "Target version (default: latest)")
+	migrateUpCmd.Flags().Bool("dry-run", false, "Preview the migrated file without writing")
+
+	migrateCmd.AddCommand(migrateRollbackCmd)
+	migrateRollbackCmd.Flags().StringP("project", "p", "", "Project to roll back")
+	migrateRollbackCmd.Flags().Int("steps", 1, "Number of migration steps to revert")
+
+	migrateCmd.AddCommand(migrateLayoutCmd)
+	migrateLayoutCmd.Flags().StringP("project", "p", "", "Project to convert")
+
+	migrateCmd.AddCommand(migrateV12Cmd)
+	migrateV12Cmd.Flags().StringP("project", "p", "", "Project to migrate")
+	migrateV12Cmd.Flags().Bool("dry-run", false, "Preview the upgraded project.yaml without writing")
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -83,14 +84,14 @@ type PluginResponse struct {
 	Message string `json:"message"`
 }
 
-func ExecutePlugin(pluginName string, req PluginRequest) (*PluginResponse, error) {
+func ExecutePlugin(ctx context.Context, pluginName string, req PluginRequest) (*PluginResponse, error) {
 	path := filepath.Join(getPluginsDir(), pluginName)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil, fmt.Errorf("plugin %s not found", pluginName)
 	}
 
 	input, _ := json.Marshal(req)
-	cmd := exec.Command(path)
+	cmd := exec.CommandContext(ctx, path)
 	cmd.Stdin = bytes.NewReader(input)
 
 	var stdout, stderr bytes.Buffer
@@ -98,6 +99,9 @@ func ExecutePlugin(pluginName string, req PluginRequest) (*PluginResponse, error
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("plugin stopped: %w", context.Cause(ctx))
+		}
 		return nil, fmt.Errorf("plugin execution failed: %w\nStderr: %s", err, stderr.String())
 	}
 
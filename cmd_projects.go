@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 )
@@ -42,7 +43,11 @@ By default, only active projects are shown. Use --all to see archived projects.`
 			current = "none"
 		}
 
-		fmt.Printf("Available projects:\n\n")
+		if filepath.Base(dataDir) == repoDirName {
+			fmt.Printf("Repository-local projects in %s:\n\n", dataDir)
+		} else {
+			fmt.Printf("Available projects:\n\n")
+		}
 		for i, project := range projects {
 			marker := " "
 			if project == current {
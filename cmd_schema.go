@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Publish the JSON Schema for project files",
+}
+
+var schemaExportCmd = &cobra.Command{
+	Use:   "export [v1.1|v1.2|v1.3|legacy]",
+	Short: "Print the JSON Schema for project.yaml or legacy tasks.yaml",
+	Long: `Print the JSON Schema (draft-07) for a project file format.
+
+v1.1, v1.2 and v1.3 describe project.yaml, legacy describes tasks.yaml.
+Point an editor at the output to get completion and linting, e.g. with the
+VS Code YAML extension:
+
+  quickplan schema export v1.3 > .quickplan/project.schema.json
+  # yaml-language-server: $schema=./project.schema.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kind := schemaKindV11
+		if len(args) == 1 {
+			kind = args[0]
+		}
+
+		schema, err := projectJSONSchema(kind)
+		if err != nil {
+			return err
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		out, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(out, '\n')
+
+		if output == "" {
+			_, err := os.Stdout.Write(out)
+			return err
+		}
+		if err := writeFileAtomic(output, out, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s schema to %s\n", kind, output)
+		return nil
+	},
+}
+
+func init() {
+	schemaCmd.AddCommand(schemaExportCmd)
+	schemaExportCmd.Flags().StringP("output", "o", "", "Write the schema to a file instead of stdout")
+}
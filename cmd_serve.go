@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the built-in remote API (pulses, pulse stream, blueprint registry)",
+	Long: `Serve hosts the remote API that pulse, monitor, sync push/pull and doctor
+talk to, so a team can run the whole loop locally without a separate service.
+
+Endpoints:
+  POST /api/v1/pulse           accept a status pulse
+  GET  /api/v1/pulse/stream    server-sent events stream of pulses
+  POST /api/v1/registry/push   publish an immutable blueprint version
+  GET  /api/v1/registry/pull   fetch a blueprint (?id=<id>[&version=<v>])
+  GET  /api/v1/info            service health
+
+Authentication uses the same headers the CLI sends: X-API-Key
+(QUICKPLAN_API_KEY) and Authorization: Bearer (QUICKPLAN_REMOTE_TOKEN).
+When neither is configured the server accepts all requests.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		registryDir, _ := cmd.Flags().GetString("registry-dir")
+		apiKey, _ := cmd.Flags().GetString("api-key")
+		token, _ := cmd.Flags().GetString("token")
+
+		if registryDir == "" {
+			dataDir, err := getDataDir()
+			if err != nil {
+				return err
+			}
+			registryDir = filepath.Join(dataDir, ".registry")
+		}
+
+		if !cmd.Flags().Changed("api-key") {
+			apiKey = strings.TrimSpace(globalSetting("api_key").Value)
+		}
+		if !cmd.Flags().Changed("token") {
+			token = strings.TrimSpace(os.Getenv("QUICKPLAN_REMOTE_TOKEN"))
+			if token == "" {
+				token = strings.TrimSpace(os.Getenv("QUICKPLAN_WEB_TOKEN"))
+			}
+		}
+
+		server, err := NewRemoteServer(registryDir, RemoteServerAuth{APIKey: apiKey, Token: token})
+		if err != nil {
+			return err
+		}
+
+		httpServer := &http.Server{
+			Addr:              addr,
+			Handler:           server.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- httpServer.ListenAndServe()
+		}()
+
+		if globalJSON {
+			fmt.Printf("{\"status\": \"listening\", \"addr\": \"%s\", \"registry\": \"%s\", \"auth\": %t}\n", addr, registryDir, server.Auth.enabled())
+		} else {
+			fmt.Printf("📡 QuickPlan remote API listening on %s\n", addr)
+			fmt.Printf("   Registry: %s\n", registryDir)
+			if server.Auth.enabled() {
+				fmt.Println("   Auth:     required (X-API-Key / Bearer)")
+			} else {
+				fmt.Println("   Auth:     disabled")
+			}
+		}
+
+		select {
+		case err := <-errCh:
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("server failed: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			// Open SSE streams keep connections busy; force them closed.
+			_ = httpServer.Close()
+		}
+		if !globalJSON {
+			fmt.Println("\n👋 Remote API stopped.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("addr", "localhost:8081", "Listen address")
+	serveCmd.Flags().String("registry-dir", "", "Blueprint registry directory (default: <datadir>/.registry)")
+	serveCmd.Flags().String("api-key", "", "Required X-API-Key value (default: $QUICKPLAN_API_KEY or config api_key)")
+	serveCmd.Flags().String("token", "", "Required bearer token (default: $QUICKPLAN_REMOTE_TOKEN)")
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var setCmd = &cobra.Command{
+	Use:     "set <task-id>",
+	Aliases: []string{"edit"},
+	Short:   "Change an existing task",
+	Long: `Change fields of an existing task. Only the flags you pass are changed;
+pass an empty value (e.g. --command "") to clear a field.
+
+With --edit, or when called as "quickplan edit", the task opens in $VISUAL
+or $EDITOR as YAML. Status cannot be changed this way; use complete and
+the other status commands, which check that the transition is allowed.
+
+Every change is validated like a freshly loaded project and recorded as a
+TASK_UPDATED event listing the changed fields.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetProject, err := getTargetProject(cmd)
+		if err != nil {
+			return err
+		}
+		if !projectExists(targetProject) {
+			return fmt.Errorf("project '%s' does not exist", targetProject)
+		}
+
+		dataDir, err := getDataDir()
+		if err != nil {
+			return fmt.Errorf("failed to get data directory: %w", err)
+		}
+
+		versionManager := NewVersionManager(version)
+		projectManager := NewProjectDataManager(dataDir, versionManager)
+
+		taskID := normalizeTaskID(args[0])
+		useEditor, _ := cmd.Flags().GetBool("edit")
+		if cmd.CalledAs() == "edit" {
+			useEditor = true
+		}
+
+		// The editor runs before the transaction so the lock is not held
+		// while someone types; the transaction then checks nobody changed
+		// the task in the meantime.
+		var edited interface{}
+		var original []byte
+		if useEditor {
+			if edited, original, err = editTaskInEditor(projectManager, targetProject, taskID); err != nil {
+				return err
+			}
+			if edited == nil {
+				fmt.Println("No changes made.")
+				return nil
+			}
+		}
+
+		var changes []string
+		err = projectManager.Update(targetProject, func(tx *ProjectTx) error {
+			if tx.IsV11() {
+				changes, err = setTaskV11(cmd, tx.V11(), taskID, edited, original)
+			} else {
+				changes, err = setTaskLegacy(cmd, tx.Legacy(), taskID, edited, original)
+			}
+			if err != nil || len(changes) == 0 {
+				return err
+			}
+			tx.MarkModified()
+			tx.AppendEvent(Event{
+				Type:    "TASK_UPDATED",
+				Actor:   "human",
+				TaskID:  taskID,
+				Message: "Task updated: " + strings.Join(changes, "; "),
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if globalJSON {
+			if changes == nil {
+				changes = []string{}
+			}
+			payload, _ := json.Marshal(map[string]interface{}{
+				"status":  "success",
+				"project": targetProject,
+				"task_id": taskID,
+				"changes": changes,
+			})
+			fmt.Println(string(payload))
+			return nil
+		}
+
+		if len(changes) == 0 {
+			fmt.Printf("Task %s unchanged.\n", taskID)
+			return nil
+		}
+		fmt.Printf("Updated task %s in project '%s':\n", taskID, targetProject)
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addSetFlags(setCmd)
+}
+
+// addSetFlags defines the flags of the set command.
+func addSetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("project", "p", "", "Change a task in this project instead of current")
+	cmd.Flags().BoolP("edit", "e", false, "Edit the task as YAML in $VISUAL or $EDITOR")
+	cmd.Flags().String("text", "", "Task text")
+	cmd.Flags().String("assigned-to", "", "Assign task to agent or user")
+	cmd.Flags().StringSlice("depends-on", []string{}, "Comma-separated list of task IDs this task depends on (replaces the list)")
+	cmd.Flags().String("role", "", "Role for the agent behavior")
+	cmd.Flags().String("lifecycle", "", "Lifecycle for the agent behavior (e.g., Atomic, Infinite)")
+	cmd.Flags().String("strategy", "", "Strategy for the agent behavior (e.g., TDD, Fast Prototype)")
+	cmd.Flags().String("timeout", "", "Stop a run that takes longer than this (e.g., 10m); empty for no limit")
+	cmd.Flags().String("loop-interval", "", "Pause between runs of an Infinite task (e.g., 30s, 5m)")
+	cmd.Flags().Int("max-iterations", 0, "End an Infinite task after this many runs, 0 for no limit")
+	cmd.Flags().Int("until-exit-code", -1, "End an Infinite task once a run exits with this code, negative to remove")
+	cmd.Flags().String("command", "", "Execution command for the task")
+	cmd.Flags().String("plugin", "", "Plugin name to execute for the task")
+	cmd.Flags().String("watch-path", "", "Physical file path to watch for dependency verification")
+	cmd.Flags().Int("max-attempts", 0, "Retry policy: maximum attempts, 0 removes the policy (v1.1)")
+	cmd.Flags().String("backoff", "", "Retry policy backoff: fixed, linear or exponential (v1.1)")
+	cmd.Flags().Int("base-seconds", 0, "Retry policy base delay in seconds (v1.1)")
+	cmd.Flags().String("priority", "", "Task priority: low, medium, high or urgent (schema 1.2)")
+	cmd.Flags().String("due", "", "Due date as 2026-03-01 or RFC3339 (schema 1.2)")
+	cmd.Flags().String("start", "", "Start date as 2026-03-01 or RFC3339; workers wait until then (schema 1.3)")
+	cmd.Flags().StringSlice("label", []string{}, "Labels; replaces the list (schema 1.2)")
+	cmd.Flags().String("estimate", "", "Effort estimate such as 90m, 4h, 3d or 2w (schema 1.2)")
+	cmd.Flags().String("description", "", "Markdown description (schema 1.2)")
+	cmd.Flags().String("recur", "", "Repeat the task on a cron schedule such as \"0 9 * * MON\"; empty stops it (schema 1.3)")
+	cmd.Flags().String("recur-tz", "", "Time zone for --recur, e.g. Europe/Berlin (default local)")
+	cmd.Flags().String("parent", "", "Make the task a subtask of this task ID; empty makes it top-level (schema 1.3)")
+}
+
+// normalizeTaskID accepts "3" as well as "t-3".
+func normalizeTaskID(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if _, err := strconv.Atoi(raw); err == nil {
+		return "t-" + raw
+	}
+	return raw
+}
+
+// setTaskV11 applies the flags, or the edited copy, to task taskID and
+// returns the changed fields.
+func setTaskV11(cmd *cobra.Command, project *ProjectV11, taskID string, edited interface{}, original []byte) ([]string, error) {
+	index := -1
+	for i := range project.Tasks {
+		if project.Tasks[i].ID == taskID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	before := project.Tasks[index]
+	after := before
+
+	if edited != nil {
+		if err := checkUnchangedSinceEdit(before, original); err != nil {
+			return nil, err
+		}
+		after = *edited.(*TaskV11)
+		if after.ID != before.ID {
+			return nil, fmt.Errorf("the task ID cannot be changed")
+		}
+		if after.Status != before.Status {
+			return nil, fmt.Errorf("status cannot be changed with set; use the status commands")
+		}
+	} else {
+		flags := cmd.Flags()
+		if flags.Changed("text") {
+			after.Name, _ = flags.GetString("text")
+		}
+		if flags.Changed("assigned-to") {
+			after.AssignedTo, _ = flags.GetString("assigned-to")
+		}
+		if flags.Changed("depends-on") {
+			deps, _ := flags.GetStringSlice("depends-on")
+			after.DependsOn = nil
+			for _, dep := range deps {
+				if dep = normalizeTaskID(dep); dep != "" {
+					after.DependsOn = append(after.DependsOn, dep)
+				}
+			}
+		}
+		if flags.Changed("watch-path") {
+			path, _ := flags.GetString("watch-path")
+			after.Watch.Paths = nil
+			if path != "" {
+				after.Watch.Paths = []string{path}
+			}
+		}
+		if err := applyBehaviorFlags(cmd, &after.Behavior); err != nil {
+			return nil, err
+		}
+		if err := applyRetryPolicyFlags(cmd, &after); err != nil {
+			return nil, err
+		}
+		if _, err := applyTaskMetadataFlags(cmd, &after); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := taskFieldDiff(before, after)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	after.UpdatedAt = time.Now()
+	project.Tasks[index] = after
+	return changes, nil
+}
+
+// setTaskLegacy is setTaskV11 for legacy tasks.yaml projects, which have no
+// retry policy or schema 1.2 and 1.3 metadata.
+func setTaskLegacy(cmd *cobra.Command, projectData *ProjectData, taskID string, edited interface{}, original []byte) ([]string, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(taskID, "t-"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid legacy task ID: %s", taskID)
+	}
+	index := -1
+	for i := range projectData.Tasks {
+		if projectData.Tasks[i].ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	before := projectData.Tasks[index]
+	after := before
+
+	if edited != nil {
+		if err := checkUnchangedSinceEdit(before, original); err != nil {
+			return nil, err
+		}
+		after = *edited.(*Task)
+		if after.ID != before.ID {
+			return nil, fmt.Errorf("the task ID cannot be changed")
+		}
+		if after.Status != before.Status || after.Done != before.Done {
+			return nil, fmt.Errorf("status cannot be changed with set; use the status commands")
+		}
+	} else {
+		flags := cmd.Flags()
+		for _, name := range []string{"max-attempts", "backoff", "base-seconds", "priority", "due", "start", "label", "estimate", "description", "parent", "recur", "recur-tz"} {
+			if flags.Changed(name) {
+				return nil, fmt.Errorf("--%s requires a v1.1 project (run 'quickplan migrate v1.1')", name)
+			}
+		}
+		if flags.Changed("text") {
+			after.Text, _ = flags.GetString("text")
+		}
+		if flags.Changed("assigned-to") {
+			after.AssignedTo, _ = flags.GetString("assigned-to")
+		}
+		if flags.Changed("depends-on") {
+			deps, _ := flags.GetStringSlice("depends-on")
+			after.DependsOn = nil
+			for _, dep := range deps {
+				if dep = strings.TrimPrefix(strings.TrimSpace(dep), "t-"); dep == "" {
+					continue
+				}
+				n, err := strconv.Atoi(dep)
+				if err != nil {
+					return nil, fmt.Errorf("invalid legacy task ID in --depends-on: %s", dep)
+				}
+				after.DependsOn = append(after.DependsOn, n)
+			}
+		}
+		if flags.Changed("watch-path") {
+			after.WatchPath, _ = flags.GetString("watch-path")
+		}
+		if err := applyBehaviorFlags(cmd, &after.Behavior); err != nil {
+			return nil, err
+		}
+	}
+
+	// Legacy projects have no validator, so check dependencies here.
+	known := make(map[int]bool, len(projectData.Tasks))
+	for _, task := range projectData.Tasks {
+		known[task.ID] = true
+	}
+	for _, dep := range after.DependsOn {
+		if dep == after.ID {
+			return nil, fmt.Errorf("task %s cannot depend on itself", taskID)
+		}
+		if !known[dep] {
+			return nil, fmt.Errorf("task %s depends on non-existent task t-%d", taskID, dep)
+		}
+	}
+
+	changes := taskFieldDiff(before, after)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	projectData.Tasks[index] = after
+	return changes, nil
+}
+
+// applyBehaviorFlags copies the behavior flags that were set onto behavior.
+// A negative --until-exit-code removes the setting.
+func applyBehaviorFlags(cmd *cobra.Command, behavior *AgentBehavior) error {
+	flags := cmd.Flags()
+	if flags.Changed("role") {
+		behavior.Role, _ = flags.GetString("role")
+	}
+	if flags.Changed("lifecycle") {
+		behavior.LifeCycle, _ = flags.GetString("lifecycle")
+	}
+	if flags.Changed("strategy") {
+		behavior.Strategy, _ = flags.GetString("strategy")
+	}
+	if flags.Changed("command") {
+		behavior.Command, _ = flags.GetString("command")
+	}
+	if flags.Changed("plugin") {
+		behavior.Plugin, _ = flags.GetString("plugin")
+	}
+	if flags.Changed("timeout") {
+		behavior.Timeout, _ = flags.GetString("timeout")
+		if _, err := taskTimeout(*behavior); err != nil {
+			return err
+		}
+	}
+	if flags.Changed("loop-interval") {
+		behavior.LoopInterval, _ = flags.GetString("loop-interval")
+		if _, err := loopInterval(*behavior); err != nil {
+			return err
+		}
+	}
+	if flags.Changed("max-iterations") {
+		behavior.MaxIterations, _ = flags.GetInt("max-iterations")
+	}
+	if flags.Changed("until-exit-code") {
+		code, _ := flags.GetInt("until-exit-code")
+		behavior.UntilExitCode = nil
+		if code >= 0 {
+			behavior.UntilExitCode = &code
+		}
+	}
+	return nil
+}
+
+// applyRetryPolicyFlags edits the task's retry policy. --max-attempts 0
+// removes it.
+func applyRetryPolicyFlags(cmd *cobra.Command, task *TaskV11) error {
+	flags := cmd.Flags()
+	if !flags.Changed("max-attempts") && !flags.Changed("backoff") && !flags.Changed("base-seconds") {
+		return nil
+	}
+	policy := RetryPolicy{}
+	if task.RetryPolicy != nil {
+		policy = *task.RetryPolicy
+	}
+	if flags.Changed("max-attempts") {
+		policy.MaxAttempts, _ = flags.GetInt("max-attempts")
+		if policy.MaxAttempts == 0 {
+			task.RetryPolicy = nil
+			return nil
+		}
+	}
+	if flags.Changed("backoff") {
+		policy.Backoff, _ = flags.GetString("backoff")
+		switch policy.Backoff {
+		case "fixed", "linear", "exponential":
+		default:
+			return fmt.Errorf("invalid backoff %q (use fixed, linear or exponential)", policy.Backoff)
+		}
+	}
+	if flags.Changed("base-seconds") {
+		policy.BaseSeconds, _ = flags.GetInt("base-seconds")
+	}
+	if policy.MaxAttempts < 1 {
+		return fmt.Errorf("a retry policy needs --max-attempts of at least 1")
+	}
+	task.RetryPolicy = &policy
+	return nil
+}
+
+// taskFieldDiff lists the fields that differ between two tasks as
+// `key: old -> new`, with nested keys joined by dots.
+func taskFieldDiff(before, after interface{}) []string {
+	old, new := flattenTaskFields(before), flattenTaskFields(after)
+	keys := make(map[string]bool)
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		if k != "updated_at" {
+			sorted = append(sorted, k)
+		}
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, k := range sorted {
+		if reflect.DeepEqual(old[k], new[k]) {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", k, formatFieldValue(old[k]), formatFieldValue(new[k])))
+	}
+	return changes
+}
+
+// flattenTaskFields turns a task into dotted YAML keys and leaf values.
+func flattenTaskFields(task interface{}) map[string]interface{} {
+	data, err := yaml.Marshal(task)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	flat := make(map[string]interface{})
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		if nested, ok := value.(map[string]interface{}); ok {
+			for k, v := range nested {
+				walk(prefix+k+".", v)
+			}
+			return
+		}
+		flat[strings.TrimSuffix(prefix, ".")] = value
+	}
+	walk("", fields)
+	return flat
+}
+
+func formatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "(unset)"
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fmt.Sprint(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// editTaskInEditor opens task taskID as YAML in the user's editor. It
+// returns the edited task (*TaskV11 or *Task) and the YAML it started from,
+// or a nil task when the file was saved unchanged.
+func editTaskInEditor(projectManager *ProjectDataManager, projectName, taskID string) (interface{}, []byte, error) {
+	var task, edited interface{}
+	if v11, err := projectManager.LoadProjectV11(projectName); err == nil {
+		for i := range v11.Tasks {
+			if v11.Tasks[i].ID == taskID {
+				task, edited = v11.Tasks[i], &TaskV11{}
+			}
+		}
+	} else {
+		projectData, err := projectManager.LoadProjectData(projectName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load project data: %w", err)
+		}
+		id, _ := strconv.Atoi(strings.TrimPrefix(taskID, "t-"))
+		for i := range projectData.Tasks {
+			if projectData.Tasks[i].ID == id {
+				task, edited = projectData.Tasks[i], &Task{}
+			}
+		}
+	}
+	if task == nil {
+		return nil, nil, fmt.Errorf("task %s not found", taskID)
+	}
+
+	original, err := yaml.Marshal(task)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmp, err := os.CreateTemp("", "quickplan-"+taskID+"-*.yaml")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(original); err != nil {
+		tmp.Close()
+		return nil, nil, err
+	}
+	tmp.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Run through the shell so editors given with arguments ("code --wait") work.
+	run := exec.Command("sh", "-c", editor+` "$1"`, "sh", tmp.Name())
+	run.Stdin, run.Stdout, run.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := run.Run(); err != nil {
+		return nil, nil, fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.Equal(data, original) {
+		return nil, original, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(edited); err != nil {
+		return nil, nil, fmt.Errorf("edited task is not valid: %w", err)
+	}
+	return edited, original, nil
+}
+
+// checkUnchangedSinceEdit fails when the stored task no longer matches the
+// YAML the editor was opened with.
+func checkUnchangedSinceEdit(current interface{}, original []byte) error {
+	data, err := yaml.Marshal(current)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, original) {
+		return fmt.Errorf("the task changed while it was being edited; run the command again")
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newSetTestCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "set"}
+	addSetFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	return cmd
+}
+
+func TestSetTaskV11_RecordsFieldDiff(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	cmd := newSetTestCmd(t, "--command", "make test", "--depends-on", "1", "--max-attempts", "3")
+	var changes []string
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		var err error
+		changes, err = setTaskV11(cmd, tx.V11(), "t-2", nil, nil)
+		tx.MarkModified()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+
+	want := []string{
+		`behavior.command: (unset) -> "make test"`,
+		"depends_on: (unset) -> [t-1]",
+		"retry_policy.max_attempts: (unset) -> 3",
+	}
+	for _, w := range want {
+		if !strings.Contains(strings.Join(changes, "\n"), w) {
+			t.Fatalf("expected change %q, got %v", w, changes)
+		}
+	}
+
+	v11, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	task := v11.Tasks[1]
+	if task.Behavior.Command != "make test" || len(task.DependsOn) != 1 || task.DependsOn[0] != "t-1" {
+		t.Fatalf("task not updated: %+v", task)
+	}
+}
+
+func TestSetTaskV11_RejectsDependencyCycle(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	for _, step := range []struct {
+		id   string
+		deps string
+	}{{"t-2", "t-1"}, {"t-1", "t-2"}} {
+		cmd := newSetTestCmd(t, "--depends-on", step.deps)
+		err := pdm.Update(projectName, func(tx *ProjectTx) error {
+			changes, err := setTaskV11(cmd, tx.V11(), step.id, nil, nil)
+			if len(changes) > 0 {
+				tx.MarkModified()
+			}
+			return err
+		})
+		if step.id == "t-1" {
+			if err == nil || !strings.Contains(err.Error(), "cycle") {
+				t.Fatalf("expected a cycle error, got %v", err)
+			}
+		} else if err != nil {
+			t.Fatalf("first set failed: %v", err)
+		}
+	}
+
+	v11, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(v11.Tasks[0].DependsOn) != 0 {
+		t.Fatalf("rejected change was saved: %v", v11.Tasks[0].DependsOn)
+	}
+}
+
+func TestSetTaskLegacy_ValidatesDependencies(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	projectData, err := pdm.LoadProjectData(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	projectData.Tasks = []Task{{ID: 1, Text: "one", Status: "TODO"}, {ID: 2, Text: "two", Status: "TODO"}}
+
+	if _, err := setTaskLegacy(newSetTestCmd(t, "--depends-on", "7"), projectData, "t-2", nil, nil); err == nil {
+		t.Fatalf("expected error for missing dependency")
+	}
+	if _, err := setTaskLegacy(newSetTestCmd(t, "--priority", "high"), projectData, "t-2", nil, nil); err == nil {
+		t.Fatalf("expected error for schema 1.2 flag on legacy project")
+	}
+
+	changes, err := setTaskLegacy(newSetTestCmd(t, "--depends-on", "t-1", "--text", "two!"), projectData, "2", nil, nil)
+	if err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if len(changes) != 2 || projectData.Tasks[1].Text != "two!" || projectData.Tasks[1].DependsOn[0] != 1 {
+		t.Fatalf("unexpected result: %v %+v", changes, projectData.Tasks[1])
+	}
+}
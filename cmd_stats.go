@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -18,7 +19,18 @@ var statsCmd = &cobra.Command{
 		dataDir, _ := getDataDir()
 		projectManager := NewProjectDataManager(dataDir, NewVersionManager(version))
 
-		views, _, err := projectManager.GetTaskViews(projectName)
+		atValue, _ := cmd.Flags().GetString("at")
+		at, err := atFlag(atValue)
+		if err != nil {
+			return err
+		}
+
+		var views []TaskView
+		if at != nil {
+			views, _, err = projectManager.GetTaskViewsAt(projectName, *at)
+		} else {
+			views, _, err = projectManager.GetTaskViews(projectName)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to load tasks: %w", err)
 		}
@@ -28,7 +40,11 @@ var statsCmd = &cobra.Command{
 			counts[v.Status]++
 		}
 
-		fmt.Printf("📊 Statistics for project '%s':\n", projectName)
+		if at != nil {
+			fmt.Printf("📊 Statistics for project '%s' as of %s:\n", projectName, at.Format(time.RFC3339))
+		} else {
+			fmt.Printf("📊 Statistics for project '%s':\n", projectName)
+		}
 		fmt.Printf("  Total Tasks:  %d\n", len(views))
 		fmt.Printf("  Todo:         %d\n", counts["TODO"])
 		fmt.Printf("  Pending:      %d\n", counts["PENDING"])
@@ -38,15 +54,12 @@ var statsCmd = &cobra.Command{
 		fmt.Printf("  Failed:       %d\n", counts["FAILED"])
 
 		// Event counts
-		eventLog, err := projectManager.LoadEvents(projectName)
-		if err == nil {
-			fmt.Printf("  Total Events: %d\n", len(eventLog.Events))
-		} else {
-			// Check v1.1 embedded events
-			v11, err := projectManager.LoadProjectV11(projectName)
-			if err == nil {
-				fmt.Printf("  Total Events: %d (embedded)\n", len(v11.Events))
+		if at != nil {
+			if count, err := projectManager.countEventsAt(projectName, *at); err == nil {
+				fmt.Printf("  Total Events: %d\n", count)
 			}
+		} else if _, total, err := projectManager.TailEvents(projectName, 0); err == nil {
+			fmt.Printf("  Total Events: %d\n", total)
 		}
 
 		return nil
@@ -55,4 +68,5 @@ var statsCmd = &cobra.Command{
 
 func init() {
 	statsCmd.Flags().StringP("project", "p", "", "Project name")
+	statsCmd.Flags().String("at", "", "Show statistics as of this time (RFC3339, date, or duration ago like 36h or 7d)")
 }
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,6 +20,9 @@ import (
 type BackgroundRunner struct {
 	Logger         *swarm.EventLogger
 	ProjectManager *ProjectDataManager
+	// Stop, when closed, ends Infinite task loops after their current
+	// iteration and hands the tasks back as PENDING.
+	Stop <-chan struct{}
 }
 
 type executionPlan struct {
@@ -33,29 +39,117 @@ func (br *BackgroundRunner) RunTask(project, agentID string, task *TaskView) err
 		return err
 	}
 
+	if _, err := taskTimeout(task.Behavior); err != nil {
+		br.logExecutionError(agentID, "Task has an invalid timeout", err, "")
+		_ = br.finalizeTask(project, agentID, task, "FAILED", err.Error())
+		return err
+	}
+
+	ctx, release := br.watchTask(project, task.ID, agentID)
+	defer release()
+
+	var runner swarm.Runner
+	if plan.PluginName == "" {
+		runner = swarm.GetRunner(project, agentID, task)
+		if local, ok := runner.(*swarm.LocalRunner); ok && br.ProjectManager != nil {
+			local.DisableSandbox = br.ProjectManager.SettingBool(project, "disable_local_sandbox")
+			local.KillGrace = br.ProjectManager.SettingDuration(project, "runner.kill_grace")
+		}
+		if br.Logger != nil {
+			runner.SetLogger(br.Logger)
+		}
+		if err := runner.Setup(ctx, task); err != nil {
+			runErr := fmt.Errorf("runner setup failed: %w", err)
+			br.logExecutionError(agentID, "Task execution failed", runErr, "")
+			_ = br.finalizeTask(project, agentID, task, "FAILED", runErr.Error())
+			return runErr
+		}
+		// The workspace lives as long as the task runs, across all
+		// iterations of an Infinite loop.
+		defer func() { _ = runner.Teardown(context.Background(), task) }()
+	}
+
+	if task.Behavior.LifeCycle == lifecycleInfinite {
+		return br.runLoop(ctx, project, agentID, task, plan, runner)
+	}
+	output, runErr := br.execute(ctx, project, agentID, task, plan, runner)
+	return br.finish(project, agentID, task, output, runErr)
+}
+
+// execute runs the task's command or plugin once, within behavior.timeout,
+// logging its output as the task's next attempt. A run that times out is
+// recorded as a TASK_TIMED_OUT event.
+func (br *BackgroundRunner) execute(ctx context.Context, project, agentID string, task *TaskView, plan executionPlan, runner swarm.Runner) (string, error) {
+	timeout, _ := taskTimeout(task.Behavior)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s", errTaskTimedOut, timeout))
+		defer cancel()
+	}
+
+	var stdout, stderr io.Writer
+	runLog := br.openRunLog(project, agentID, task, plan)
+	if runLog != nil {
+		stdout, stderr = runLog.Stream("stdout"), runLog.Stream("stderr")
+	}
+
 	var (
 		output string
 		runErr error
 	)
-
 	if plan.PluginName != "" {
-		output, runErr = executePluginForTask(task, plan.PluginName)
+		output, runErr = executePluginForTask(ctx, task, plan.PluginName)
+		if stdout != nil && output != "" {
+			_, _ = io.WriteString(stdout, output+"\n")
+		}
 	} else {
-		runner := swarm.GetRunner(project, agentID, task)
-		if br.Logger != nil {
-			runner.SetLogger(br.Logger)
+		runner.SetOutput(stdout, stderr)
+		output, runErr = runner.Execute(ctx, plan.Command, task)
+	}
+	if runLog != nil {
+		if err := runLog.Close(runErr, stdout, stderr); err != nil {
+			br.logExecutionError(agentID, "Failed to write run log", err, "")
 		}
+	}
 
-		if err := runner.Setup(task); err != nil {
-			runErr = fmt.Errorf("runner setup failed: %w", err)
-		} else {
-			output, runErr = runner.Execute(plan.Command, task)
-		}
+	if errors.Is(runErr, errTaskTimedOut) {
+		message := fmt.Sprintf("Run exceeded its %s timeout and was stopped", timeout)
+		br.recordEvent(project, agentID, Event{Type: "TASK_TIMED_OUT", Actor: agentID, TaskID: task.ID, Message: message})
+		SendPulseWithMessage(project, agentID, task.ID, "IN_PROGRESS", "IN_PROGRESS", "TASK_TIMED_OUT", message)
+	}
+	return output, runErr
+}
 
-		// Teardown if it's an atomic lifecycle.
-		if task.Behavior.LifeCycle == "Atomic" || task.Behavior.LifeCycle == "" {
-			_ = runner.Teardown(task)
+// openRunLog starts the log for this attempt, or returns nil when the task
+// is not stored in a project or the log cannot be created.
+func (br *BackgroundRunner) openRunLog(project, agentID string, task *TaskView, plan executionPlan) *runLog {
+	if br.ProjectManager == nil || task.ID == "" || task.ID == "default" {
+		return nil
+	}
+	command := plan.Command
+	if plan.PluginName != "" {
+		command = "plugin:" + plan.PluginName
+	}
+	log, err := br.ProjectManager.openRunLog(project, task.ID, agentID, command)
+	if err != nil {
+		br.logExecutionError(agentID, "Failed to open run log", err, "")
+		return nil
+	}
+	return log
+}
+
+// finish finalizes the task as DONE, or FAILED with runErr, which lets the
+// retry policy pick up failures and timeouts alike. A run stopped because
+// its task left IN_PROGRESS leaves the status to whoever moved it.
+func (br *BackgroundRunner) finish(project, agentID string, task *TaskView, output string, runErr error) error {
+	if errors.Is(runErr, errTaskReleased) {
+		if br.Logger != nil {
+			br.Logger.Log("INFO", "Swarm", "Task left IN_PROGRESS; run stopped", map[string]interface{}{
+				"agent": agentID,
+				"task":  task.ID,
+			})
 		}
+		return nil
 	}
 
 	finalStatus := "DONE"
@@ -231,6 +325,12 @@ func runSwarmToCompletion(projectName string, workers int, pollInterval, maxIdle
 			close(stopCh)
 		})
 	}
+	if runner.Stop == nil {
+		// Let Infinite loops end with the swarm.
+		withStop := *runner
+		withStop.Stop = stopCh
+		runner = &withStop
+	}
 	markProgress := func() {
 		lastProgMu.Lock()
 		lastProgAt = time.Now()
@@ -279,6 +379,19 @@ func runSwarmToCompletion(projectName string, workers int, pollInterval, maxIdle
 				}
 
 				if task == nil {
+					// Recover tasks of workers that died without finishing them.
+					if reaped, reapErr := projectManager.ReapExpiredLeases(projectName, "swarm", time.Now()); reapErr != nil {
+						if logger != nil {
+							logger.Log("ERROR", "Swarm", "Failed to reap expired leases", map[string]interface{}{
+								"agent": workerID,
+								"error": reapErr.Error(),
+							})
+						}
+					} else if reaped > 0 {
+						markProgress()
+						continue
+					}
+
 					snapshot, snapErr := projectManager.GetExecutionSnapshot(projectName)
 					if snapErr != nil {
 						if logger != nil {
@@ -335,7 +448,7 @@ func validateProjectExecutionContracts(projectManager *ProjectDataManager, proje
 	var missing []string
 	for _, task := range views {
 		status := canonicalStatus(task.Status)
-		if status == "DONE" || status == "FAILED" || status == "CANCELLED" {
+		if status == "DONE" || status == "FAILED" || status == "CANCELLED" || len(task.Subtasks) > 0 {
 			continue
 		}
 		if _, err := resolveTaskExecution(&task); err != nil {
@@ -373,7 +486,7 @@ func resolveTaskExecution(task *TaskView) (executionPlan, error) {
 	return executionPlan{}, fmt.Errorf("task %s has no execution contract", task.ID)
 }
 
-func executePluginForTask(task *TaskView, pluginName string) (string, error) {
+func executePluginForTask(ctx context.Context, task *TaskView, pluginName string) (string, error) {
 	req := PluginRequest{
 		TaskID:       task.ID,
 		Role:         task.Behavior.Role,
@@ -381,7 +494,7 @@ func executePluginForTask(task *TaskView, pluginName string) (string, error) {
 		AllowedPaths: collectAllowedPaths(task),
 	}
 
-	resp, err := ExecutePlugin(pluginName, req)
+	resp, err := ExecutePlugin(ctx, pluginName, req)
 	if err != nil {
 		return "", err
 	}
@@ -454,21 +567,32 @@ func runSupervisor(projectName string, logger *swarm.EventLogger) {
 					// 1. Generate Remedy
 					healTaskText := fmt.Sprintf("REMEDY: Resolve blocker in Task %s", task.ID)
 
-					// 2. Inject (v1.1 or legacy handled by manager)
-					if task.IsV11 {
-						v11, _ := projectManager.LoadProjectV11(projectName)
-						v11.Tasks = append(v11.Tasks, TaskV11{
-							ID:     fmt.Sprintf("remedy-%d", time.Now().Unix()),
-							Name:   healTaskText,
-							Status: "TODO",
-							Behavior: AgentBehavior{
-								Role: "Senior Troubleshooter",
-							},
-							UpdatedAt: time.Now(),
-						})
-						projectManager.SaveProjectV11(projectName, v11)
-					} else {
-						legacy, _ := projectManager.LoadProjectData(projectName)
+					// 2. Inject (v1.1 or legacy) in one locked load-modify-save
+					injectErr := projectManager.Update(projectName, func(tx *ProjectTx) error {
+						tx.MarkModified()
+						if tx.IsV11() {
+							v11 := tx.V11()
+							remedyID := fmt.Sprintf("remedy-%d", time.Now().Unix())
+							v11.Tasks = append(v11.Tasks, TaskV11{
+								ID:     remedyID,
+								Name:   healTaskText,
+								Status: "TODO",
+								Behavior: AgentBehavior{
+									Role: "Senior Troubleshooter",
+								},
+								UpdatedAt: time.Now(),
+							})
+							tx.AppendEvent(Event{
+								Type:       "TASK_CREATED",
+								Actor:      "supervisor",
+								TaskID:     remedyID,
+								NextStatus: "TODO",
+								Message:    fmt.Sprintf("Task created: %s", healTaskText),
+							})
+							return nil
+						}
+
+						legacy := tx.Legacy()
 						maxID := 0
 						for _, t := range legacy.Tasks {
 							if t.ID > maxID {
@@ -481,7 +605,15 @@ func runSupervisor(projectName string, logger *swarm.EventLogger) {
 							Created:  time.Now(),
 							Behavior: AgentBehavior{Role: "Senior Troubleshooter"},
 						})
-						projectManager.SaveProjectData(projectName, legacy)
+						return nil
+					})
+					if injectErr != nil {
+						if logger != nil {
+							logger.Log("ERROR", "Supervisor", fmt.Sprintf("Failed to inject remedy for %s", task.ID), map[string]interface{}{"error": injectErr.Error()})
+						} else {
+							fmt.Printf("🛡️ Supervisor: Failed to inject remedy for %s: %v\n", task.ID, injectErr)
+						}
+						continue
 					}
 					if logger != nil {
 						logger.Log("INFO", "Supervisor", fmt.Sprintf("Injected remedy for %s", task.ID), nil)
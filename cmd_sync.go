@@ -43,7 +43,7 @@ var pushCmd = &cobra.Command{
 
 		if v11, err := projectManager.LoadProjectV11(targetProject); err == nil {
 			blueprintFormat = "v1.1"
-			blueprintSchemaVersion = "1.1"
+			blueprintSchemaVersion = v11.SchemaVersion
 			yamlData, err = yaml.Marshal(v11)
 			if err != nil {
 				return err
@@ -64,10 +64,7 @@ var pushCmd = &cobra.Command{
 			blueprintVersion = "1"
 		}
 
-		registryURL := os.Getenv("QUICKPLAN_REGISTRY_URL")
-		if registryURL == "" {
-			registryURL = "http://localhost:8081" // Default for local dev
-		}
+		registryURL := projectManager.Setting(targetProject, "registry_url").Value
 
 		blueprint := struct {
 			ID            string `json:"id"`
@@ -135,10 +132,7 @@ var pullCmd = &cobra.Command{
 			localProjectName = blueprintID
 		}
 
-		registryURL := os.Getenv("QUICKPLAN_REGISTRY_URL")
-		if registryURL == "" {
-			registryURL = "http://localhost:8081"
-		}
+		registryURL := projectSetting(localProjectName, "registry_url").Value
 
 		req, err := http.NewRequest(http.MethodGet, registryURL+"/api/v1/registry/pull?id="+blueprintID, nil)
 		if err != nil {
@@ -183,11 +177,17 @@ var pullCmd = &cobra.Command{
 		format := strings.ToLower(strings.TrimSpace(blueprint.Format))
 		schemaVersion := strings.TrimSpace(blueprint.SchemaVersion)
 		content := strings.TrimSpace(blueprint.YAMLContent)
-		if schemaVersion == "1.1" || format == "v1.1" || strings.Contains(content, "schema_version: \"1.1\"") || strings.Contains(content, "schema_version: '1.1'") || strings.Contains(content, "schema_version: 1.1") {
+		isV11 := isSupportedSchemaVersion(schemaVersion) || format == "v1.1"
+		for _, v := range supportedSchemaVersions() {
+			if strings.Contains(content, "schema_version: \""+v+"\"") || strings.Contains(content, "schema_version: '"+v+"'") || strings.Contains(content, "schema_version: "+v) {
+				isV11 = true
+			}
+		}
+		if isV11 {
 			targetFile = filepath.Join(projectDir, "project.yaml")
 		}
 
-		if err := os.WriteFile(targetFile, []byte(blueprint.YAMLContent), 0644); err != nil {
+		if err := writeFileAtomicWithBackup(targetFile, []byte(blueprint.YAMLContent), 0644); err != nil {
 			return err
 		}
 
@@ -196,6 +196,25 @@ var pullCmd = &cobra.Command{
 	},
 }
 
+// reportVerifyDiagnostics prints diagnostics, as a JSON array with --json,
+// and fails when any of them is an error.
+func reportVerifyDiagnostics(filePath string, diagnostics []Diagnostic) error {
+	if globalJSON {
+		if diagnostics == nil {
+			diagnostics = []Diagnostic{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(diagnostics)
+	} else if len(diagnostics) > 0 {
+		printDiagnostics(os.Stdout, diagnostics)
+	}
+	if hasErrorDiagnostics(diagnostics) {
+		return fmt.Errorf("%s failed validation", filePath)
+	}
+	return nil
+}
+
 var verifyCmd = &cobra.Command{
 	Use:   "verify [file.yaml]",
 	Short: "Verify a project YAML against the blueprint schema",
@@ -210,22 +229,25 @@ var verifyCmd = &cobra.Command{
 		var schemaProbe struct {
 			SchemaVersion string `yaml:"schema_version"`
 		}
-		if err := yaml.Unmarshal(data, &schemaProbe); err != nil {
-			return fmt.Errorf("invalid YAML format: %w", err)
-		}
-
-		if schemaProbe.SchemaVersion == "1.1" {
-			var projectV11 ProjectV11
-			if err := yaml.Unmarshal(data, &projectV11); err != nil {
-				return fmt.Errorf("invalid v1.1 YAML format: %w", err)
+		probeErr := yaml.Unmarshal(data, &schemaProbe)
+		if probeErr != nil || schemaProbe.SchemaVersion != "" {
+			_, diagnostics := DiagnoseProjectV11(filePath, data)
+			diagnostics = mergeDiagnostics(diagnostics, schemaDiagnostics(filePath, data, "v"+schemaProbe.SchemaVersion))
+			if err := reportVerifyDiagnostics(filePath, diagnostics); err != nil {
+				return err
 			}
-			if err := ValidateProjectV11(&projectV11); err != nil {
-				return fmt.Errorf("v1.1 validation failed: %w", err)
+			if !globalJSON {
+				fmt.Printf("✅ Project DNA verified: %s is v%s protocol compatible\n", filePath, schemaProbe.SchemaVersion)
 			}
-			fmt.Printf("✅ Project DNA verified: %s is v1.1 protocol compatible\n", filePath)
 			return nil
 		}
 
+		if diagnostics := schemaDiagnostics(filePath, data, schemaKindLegacy); len(diagnostics) > 0 || globalJSON {
+			if err := reportVerifyDiagnostics(filePath, diagnostics); err != nil || globalJSON {
+				return err
+			}
+		}
+
 		var projectData ProjectData
 		if err := yaml.Unmarshal(data, &projectData); err != nil {
 			return fmt.Errorf("invalid legacy YAML format: %w", err)
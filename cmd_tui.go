@@ -51,6 +51,7 @@ var (
 type model struct {
 	projectName string
 	tasks       []TaskView
+	prefixes    []string // tree indentation drawn before each task
 	cursor      int
 	logs        []string
 	ready       bool
@@ -62,18 +63,24 @@ type model struct {
 	logFile     *os.File
 	logReader   *bufio.Reader
 	dataDir     string
+	at          *time.Time // historical view; nil shows live state
 }
 
-type tasksUpdatedMsg []TaskView
+// tuiRunLogLines is how much of the selected task's last run log the
+// details pane shows.
+const tuiRunLogLines = 8
+
+type tasksUpdatedMsg []taskTreeRow
 type logMsg string
 type errMsg error
 
 // --- Init ---
 
-func initialModel(projectName, dataDir string) model {
+func initialModel(projectName, dataDir string, at *time.Time) model {
 	m := model{
 		projectName: projectName,
 		dataDir:     dataDir,
+		at:          at,
 		tasks:       []TaskView{},
 		logs:        []string{},
 	}
@@ -135,7 +142,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.SetContent(m.renderDetails())
 
 	case tasksUpdatedMsg:
-		m.tasks = msg
+		m.tasks = make([]TaskView, len(msg))
+		m.prefixes = make([]string, len(msg))
+		for i, row := range msg {
+			m.tasks[i], m.prefixes[i] = row.Task, row.Prefix
+		}
 		// Keep cursor in bounds
 		if m.cursor >= len(m.tasks) {
 			m.cursor = len(m.tasks) - 1
@@ -179,7 +190,11 @@ func (m model) View() string {
 	}
 
 	// Layout
-	header := headerStyle.Render(fmt.Sprintf("QuickPlan Control Room: %s", m.projectName))
+	title := fmt.Sprintf("QuickPlan Control Room: %s", m.projectName)
+	if m.at != nil {
+		title += fmt.Sprintf(" (as of %s)", m.at.Format(time.RFC3339))
+	}
+	header := headerStyle.Render(title)
 
 	// Left Pane: Task List
 	taskList := m.renderTaskList()
@@ -226,7 +241,7 @@ func (m model) renderTaskList() string {
 			statusIcon = "✖"
 		}
 
-		line := fmt.Sprintf("%s %s %s", cursor, statusIcon, task.Text)
+		line := fmt.Sprintf("%s %s%s %s", cursor, m.prefixes[i], statusIcon, task.Text)
 		if m.cursor == i {
 			line = lipgloss.NewStyle().Foreground(highlight).Render(line)
 		}
@@ -253,11 +268,22 @@ func (m model) renderDetails() string {
 	s.WriteString(fmt.Sprintf("ID: %s\n", task.ID))
 	s.WriteString(fmt.Sprintf("Status: %s\n", task.Status))
 	s.WriteString(fmt.Sprintf("Assigned To: %s\n", task.AssignedTo))
+	if task.Parent != "" {
+		s.WriteString(fmt.Sprintf("Parent: %s\n", task.Parent))
+	}
 	s.WriteString("\n--- Behavior ---\n")
 	s.WriteString(fmt.Sprintf("Role: %s\n", task.Behavior.Role))
 	s.WriteString(fmt.Sprintf("Strategy: %s\n", task.Behavior.Strategy))
 	s.WriteString(fmt.Sprintf("Environment: %s (%s)\n", task.Behavior.Environment.Provider, task.Behavior.Environment.Image))
 
+	if len(task.Subtasks) > 0 {
+		statusByID := buildStatusIndex(m.tasks)
+		s.WriteString(fmt.Sprintf("\n--- Subtasks (%s done) ---\n", subtaskProgress(task, statusByID)))
+		for _, id := range task.Subtasks {
+			s.WriteString(fmt.Sprintf("- %s [%s]\n", id, statusByID[id]))
+		}
+	}
+
 	if len(task.DependsOn) > 0 {
 		s.WriteString("\n--- Dependencies ---\n")
 		for _, dep := range task.DependsOn {
@@ -265,6 +291,17 @@ func (m model) renderDetails() string {
 		}
 	}
 
+	// Run logs only exist for the present, so skip them in a historical view.
+	if m.at == nil {
+		projectManager := NewProjectDataManager(m.dataDir, NewVersionManager(version))
+		if attempt, content, err := projectManager.latestRunLog(m.projectName, task.ID); err == nil && attempt > 0 {
+			s.WriteString(fmt.Sprintf("\n--- Last run (attempt %d; quickplan logs %s) ---\n", attempt, task.ID))
+			for _, line := range runLogTail(content, tuiRunLogLines) {
+				s.WriteString(line + "\n")
+			}
+		}
+	}
+
 	return s.String()
 }
 
@@ -272,26 +309,31 @@ func (m model) renderDetails() string {
 
 func (m model) loadTasksCmd() tea.Cmd {
 	return func() tea.Msg {
-		projectManager := NewProjectDataManager(m.dataDir, NewVersionManager(version))
-		views, _, err := projectManager.GetTaskViews(m.projectName)
-		if err != nil {
-			return errMsg(err)
-		}
-		return tasksUpdatedMsg(views)
+		return m.fetchTasks()
 	}
 }
 
 func (m model) tickTasksCmd() tea.Cmd {
 	return tea.Tick(1*time.Second, func(t time.Time) tea.Msg {
-		projectManager := NewProjectDataManager(m.dataDir, NewVersionManager(version))
-		views, _, err := projectManager.GetTaskViews(m.projectName)
-		if err != nil {
-			return errMsg(err)
-		}
-		return tasksUpdatedMsg(views)
+		return m.fetchTasks()
 	})
 }
 
+func (m model) fetchTasks() tea.Msg {
+	projectManager := NewProjectDataManager(m.dataDir, NewVersionManager(version))
+	var views []TaskView
+	var err error
+	if m.at != nil {
+		views, _, err = projectManager.GetTaskViewsAt(m.projectName, *m.at)
+	} else {
+		views, _, err = projectManager.GetTaskViews(m.projectName)
+	}
+	if err != nil {
+		return errMsg(err)
+	}
+	return tasksUpdatedMsg(taskTree(views))
+}
+
 // waitForLogCmd opens the file and seeks to end
 func (m *model) waitForLogCmd() tea.Cmd {
 	return func() tea.Msg {
@@ -358,7 +400,13 @@ var tuiCmd = &cobra.Command{
 			return err
 		}
 
-		p := tea.NewProgram(initialModel(projectName, dataDir), tea.WithAltScreen())
+		atValue, _ := cmd.Flags().GetString("at")
+		at, err := atFlag(atValue)
+		if err != nil {
+			return err
+		}
+
+		p := tea.NewProgram(initialModel(projectName, dataDir, at), tea.WithAltScreen())
 		if _, err := p.Run(); err != nil {
 			return fmt.Errorf("error running TUI: %w", err)
 		}
@@ -368,4 +416,5 @@ var tuiCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(tuiCmd)
+	tuiCmd.Flags().String("at", "", "Show tasks as they were at this time (RFC3339, date, or duration ago like 36h or 7d)")
 }
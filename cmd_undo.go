@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -45,14 +46,40 @@ var undoCmd = &cobra.Command{
 		versionManager := NewVersionManager(version)
 		projectManager := NewProjectDataManager(dataDir, versionManager)
 
-		if err := projectManager.SaveProjectData(undoData.ProjectName, &undoData.Data); err != nil {
+		// Put back only the deleted tasks, under the project lock, so edits
+		// made since the deletion are kept.
+		restored := 0
+		err = projectManager.Update(undoData.ProjectName, func(tx *ProjectTx) error {
+			if tx.IsV11() {
+				return fmt.Errorf("project '%s' was migrated to v1.1 after the deletion", undoData.ProjectName)
+			}
+			current := tx.Legacy()
+			existing := make(map[int]bool, len(current.Tasks))
+			for _, task := range current.Tasks {
+				existing[task.ID] = true
+			}
+			for _, task := range undoData.Data.Tasks {
+				if !existing[task.ID] {
+					current.Tasks = append(current.Tasks, task)
+					restored++
+				}
+			}
+			if restored > 0 {
+				sort.SliceStable(current.Tasks, func(i, j int) bool {
+					return current.Tasks[i].ID < current.Tasks[j].ID
+				})
+				tx.MarkModified()
+			}
+			return nil
+		})
+		if err != nil {
 			return fmt.Errorf("failed to restore project data: %w", err)
 		}
 
 		// Delete backup file after successful undo
 		os.Remove(undoBackupPath)
 
-		fmt.Printf("Successfully restored tasks for project '%s'\n", undoData.ProjectName)
+		fmt.Printf("Successfully restored %d tasks for project '%s'\n", restored, undoData.ProjectName)
 		return nil
 	},
 }
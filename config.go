@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Setting sources, from highest to lowest precedence. Flags sit above all of
+// them and are applied by the command that owns the flag.
+const (
+	sourceEnv     = "env"
+	sourceProject = "project"
+	sourceGlobal  = "global"
+	sourceDefault = "default"
+)
+
+// Setting value kinds, used to validate values before they are stored.
+const (
+	settingString   = "string"
+	settingInt      = "int"
+	settingDuration = "duration"
+	settingBool     = "bool"
+)
+
+// ConfigSetting describes one configurable value.
+type ConfigSetting struct {
+	Key         string
+	Env         string
+	Default     string
+	Kind        string
+	Description string
+	Choices     []string // accepted values, if restricted
+	Min         string   // lowest accepted int value, 1 if empty
+	GlobalOnly  bool     // cannot be overridden in project.yml
+	Secret      bool     // masked by `config list`
+}
+
+// configSettings lists every key `config get/set/list` accepts.
+var configSettings = []ConfigSetting{
+	{Key: "data_dir", Env: "QUICKPLAN_DATADIR", Kind: settingString, GlobalOnly: true,
+		Description: "Directory holding projects (default ~/.local/share/quickplan)"},
+	{Key: "web_url", Env: "QUICKPLAN_WEB_URL", Default: "http://localhost:8081", Kind: settingString, GlobalOnly: true,
+		Description: "Dashboard that receives agent pulses"},
+	{Key: "registry_url", Env: "QUICKPLAN_REGISTRY_URL", Default: "http://localhost:8081", Kind: settingString, GlobalOnly: true,
+		Description: "Remote base URL for sync and remote health checks"},
+	{Key: "api_key", Env: "QUICKPLAN_API_KEY", Kind: settingString, GlobalOnly: true, Secret: true,
+		Description: "X-API-Key sent to remote services and required by serve"},
+	{Key: "disable_local_sandbox", Env: "QUICKPLAN_DISABLE_LOCAL_SANDBOX", Default: "false", Kind: settingBool, GlobalOnly: true,
+		Description: "Run local commands without namespace isolation"},
+	{Key: "runner.kill_grace", Env: "QUICKPLAN_RUNNER_KILL_GRACE", Default: "5s", Kind: settingDuration,
+		Description: "Time a stopped command gets between SIGTERM and SIGKILL"},
+	{Key: "runs.keep", Env: "QUICKPLAN_RUNS_KEEP", Default: "20", Kind: settingInt, Min: "0",
+		Description: "Run logs kept per task; older attempts are deleted, 0 keeps all"},
+	{Key: "runs.max_log_bytes", Env: "QUICKPLAN_RUNS_MAX_LOG_BYTES", Default: "1048576", Kind: settingInt, Min: "0",
+		Description: "Output kept per run log before the rest is dropped, 0 for no limit"},
+	{Key: "daemon.max_agents", Env: "QUICKPLAN_DAEMON_MAX_AGENTS", Default: "2", Kind: settingInt,
+		Description: "Concurrent daemon agents per project"},
+	{Key: "daemon.poll_interval", Env: "QUICKPLAN_DAEMON_POLL_INTERVAL", Default: "30s", Kind: settingDuration, GlobalOnly: true,
+		Description: "How often the daemon rescans projects"},
+	{Key: "lease.ttl", Env: "QUICKPLAN_LEASE_TTL", Default: "2m", Kind: settingDuration,
+		Description: "How long a running task's lease lasts unless its worker renews it"},
+	{Key: "lock.ttl", Env: "QUICKPLAN_LOCK_TTL", Default: "5m", Kind: settingDuration,
+		Description: "Lifetime of a project lock before it counts as stale"},
+	{Key: "scheduler.policy", Env: "QUICKPLAN_SCHEDULER_POLICY", Default: policyPriority, Kind: settingString,
+		Choices:     schedulingPolicyNames,
+		Description: "Order runnable tasks are handed out in: fifo, priority or critical-path"},
+	{Key: "scheduler.aging", Env: "QUICKPLAN_SCHEDULER_AGING", Default: "1h", Kind: settingDuration,
+		Description: "Waiting time that raises a task one priority level, up to high"},
+}
+
+// ResolvedSetting is the effective value of a setting and where it came from.
+type ResolvedSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// lookupSetting returns the definition of key.
+func lookupSetting(key string) (ConfigSetting, error) {
+	for _, s := range configSettings {
+		if s.Key == key {
+			return s, nil
+		}
+	}
+	return ConfigSetting{}, fmt.Errorf("unknown config key %q (see 'quickplan config list')", key)
+}
+
+// validate checks that value parses as the setting's kind.
+func (s ConfigSetting) validate(value string) error {
+	if len(s.Choices) > 0 {
+		for _, choice := range s.Choices {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s, got %q", s.Key, strings.Join(s.Choices, ", "), value)
+	}
+	switch s.Kind {
+	case settingInt:
+		lowest := 1
+		if s.Min != "" {
+			lowest, _ = strconv.Atoi(s.Min)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < lowest {
+			if lowest == 1 {
+				return fmt.Errorf("%s must be a positive integer, got %q", s.Key, value)
+			}
+			return fmt.Errorf("%s must be an integer of at least %d, got %q", s.Key, lowest, value)
+		}
+	case settingDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%s must be a positive duration such as 30s or 5m, got %q", s.Key, value)
+		}
+	case settingBool:
+		if _, err := parseSettingBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", s.Key, value)
+		}
+	}
+	return nil
+}
+
+// parseSettingBool accepts the spellings env vars traditionally use.
+func parseSettingBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// globalConfigPath returns ~/.config/quickplan/config.yaml, honoring
+// XDG_CONFIG_HOME.
+func globalConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "quickplan", "config.yaml"), nil
+	}
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(usr.HomeDir, ".config", "quickplan", "config.yaml"), nil
+}
+
+// loadGlobalConfig reads the global config file. A missing file is empty.
+func loadGlobalConfig() (map[string]string, error) {
+	path, err := globalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	values := make(map[string]string)
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// saveGlobalConfig writes the global config file.
+func saveGlobalConfig(values map[string]string) error {
+	path, err := globalConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// projectSettings reads the settings overrides from a project's project.yml
+// without creating it or taking the project lock, so it is safe to call
+// while acquiring that lock.
+func (pdm *ProjectDataManager) projectSettings(projectName string) map[string]string {
+	if projectName == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(pdm.dataDir, projectName, "project.yml"))
+	if err != nil {
+		return nil
+	}
+	var config struct {
+		Settings map[string]string `yaml:"settings"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	return config.Settings
+}
+
+// resolveSetting applies env > project > global > default for key. Values
+// that fail validation are skipped, so a typo in a config file falls back to
+// the next source instead of breaking every command.
+func resolveSetting(setting ConfigSetting, projectValues, globalValues map[string]string) ResolvedSetting {
+	resolved := ResolvedSetting{Key: setting.Key, Value: setting.Default, Source: sourceDefault}
+	candidates := []struct {
+		source string
+		value  string
+		ok     bool
+	}{
+		{source: sourceGlobal},
+		{source: sourceProject},
+		{source: sourceEnv},
+	}
+	candidates[0].value, candidates[0].ok = globalValues[setting.Key]
+	if !setting.GlobalOnly {
+		candidates[1].value, candidates[1].ok = projectValues[setting.Key]
+	}
+	if setting.Env != "" {
+		candidates[2].value, candidates[2].ok = os.LookupEnv(setting.Env)
+	}
+
+	for _, c := range candidates {
+		value := strings.TrimSpace(c.value)
+		if !c.ok || value == "" || setting.validate(value) != nil {
+			continue
+		}
+		resolved.Value = value
+		resolved.Source = c.source
+	}
+	return resolved
+}
+
+// Setting returns the effective value of key for projectName. An empty
+// project name resolves without project overrides.
+func (pdm *ProjectDataManager) Setting(projectName, key string) ResolvedSetting {
+	setting, err := lookupSetting(key)
+	if err != nil {
+		return ResolvedSetting{Key: key, Source: sourceDefault}
+	}
+	globalValues, _ := loadGlobalConfig()
+	return resolveSetting(setting, pdm.projectSettings(projectName), globalValues)
+}
+
+// Settings returns the effective value of every known setting.
+func (pdm *ProjectDataManager) Settings(projectName string) []ResolvedSetting {
+	globalValues, _ := loadGlobalConfig()
+	projectValues := pdm.projectSettings(projectName)
+	resolved := make([]ResolvedSetting, 0, len(configSettings))
+	for _, setting := range configSettings {
+		resolved = append(resolved, resolveSetting(setting, projectValues, globalValues))
+	}
+	return resolved
+}
+
+// SettingInt returns key as an integer, falling back to its default.
+func (pdm *ProjectDataManager) SettingInt(projectName, key string) int {
+	n, _ := strconv.Atoi(pdm.Setting(projectName, key).Value)
+	return n
+}
+
+// SettingDuration returns key as a duration.
+func (pdm *ProjectDataManager) SettingDuration(projectName, key string) time.Duration {
+	d, _ := time.ParseDuration(pdm.Setting(projectName, key).Value)
+	return d
+}
+
+// SettingBool returns key as a boolean.
+func (pdm *ProjectDataManager) SettingBool(projectName, key string) bool {
+	b, _ := parseSettingBool(pdm.Setting(projectName, key).Value)
+	return b
+}
+
+// lockTTL returns the configured lock lifetime for projectName in seconds.
+func (pdm *ProjectDataManager) lockTTL(projectName string) int {
+	ttl := int(pdm.SettingDuration(projectName, "lock.ttl") / time.Second)
+	if ttl < 1 {
+		return 1
+	}
+	return ttl
+}
+
+// SetProjectSetting stores a validated override in project.yml. An empty
+// value removes the override.
+func (pdm *ProjectDataManager) SetProjectSetting(projectName, key, value string) error {
+	setting, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if setting.GlobalOnly {
+		return fmt.Errorf("%s can only be set globally", key)
+	}
+	if value != "" {
+		if err := setting.validate(value); err != nil {
+			return err
+		}
+	}
+
+	config, err := pdm.LoadProjectConfig(projectName)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		delete(config.Settings, key)
+	} else {
+		if config.Settings == nil {
+			config.Settings = make(map[string]string)
+		}
+		config.Settings[key] = value
+	}
+	// Only the key goes into the history message, never the value.
+	return pdm.saveProjectConfig(projectName, config, "Change setting "+key)
+}
+
+// setGlobalSetting stores a validated value in the global config file. An
+// empty value removes it.
+func setGlobalSetting(key, value string) error {
+	setting, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if value != "" {
+		if err := setting.validate(value); err != nil {
+			return err
+		}
+	}
+
+	values, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		delete(values, key)
+	} else {
+		values[key] = value
+	}
+	return saveGlobalConfig(values)
+}
+
+// globalSetting resolves key without a project, for code that runs before a
+// data directory is known.
+func globalSetting(key string) ResolvedSetting {
+	setting, err := lookupSetting(key)
+	if err != nil {
+		return ResolvedSetting{Key: key, Source: sourceDefault}
+	}
+	globalValues, _ := loadGlobalConfig()
+	return resolveSetting(setting, nil, globalValues)
+}
+
+// projectSetting resolves key for projectName in the current data directory.
+func projectSetting(projectName, key string) ResolvedSetting {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return globalSetting(key)
+	}
+	return NewProjectDataManager(dataDir, NewVersionManager(version)).Setting(projectName, key)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSetting_Precedence(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("QUICKPLAN_DAEMON_MAX_AGENTS", "")
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	if got := pdm.Setting(projectName, "daemon.max_agents"); got.Value != "2" || got.Source != sourceDefault {
+		t.Fatalf("expected default 2, got %+v", got)
+	}
+
+	if err := setGlobalSetting("daemon.max_agents", "4"); err != nil {
+		t.Fatalf("global set failed: %v", err)
+	}
+	if got := pdm.Setting(projectName, "daemon.max_agents"); got.Value != "4" || got.Source != sourceGlobal {
+		t.Fatalf("expected global 4, got %+v", got)
+	}
+
+	if err := pdm.SetProjectSetting(projectName, "daemon.max_agents", "6"); err != nil {
+		t.Fatalf("project set failed: %v", err)
+	}
+	if got := pdm.SettingInt(projectName, "daemon.max_agents"); got != 6 {
+		t.Fatalf("expected project override 6, got %d", got)
+	}
+	if got := pdm.SettingInt("", "daemon.max_agents"); got != 4 {
+		t.Fatalf("expected global value without a project, got %d", got)
+	}
+
+	t.Setenv("QUICKPLAN_DAEMON_MAX_AGENTS", "8")
+	if got := pdm.Setting(projectName, "daemon.max_agents"); got.Value != "8" || got.Source != sourceEnv {
+		t.Fatalf("expected env 8, got %+v", got)
+	}
+
+	if err := pdm.SetProjectSetting(projectName, "daemon.max_agents", ""); err != nil {
+		t.Fatalf("project unset failed: %v", err)
+	}
+	if settings := pdm.projectSettings(projectName); len(settings) != 0 {
+		t.Fatalf("expected override removed, got %v", settings)
+	}
+}
+
+func TestSetting_Validation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("QUICKPLAN_LOCK_TTL", "")
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	if err := setGlobalSetting("lock.ttl", "soon"); err == nil {
+		t.Fatal("expected invalid duration to be rejected")
+	}
+	if err := setGlobalSetting("nope", "1"); err == nil {
+		t.Fatal("expected unknown key to be rejected")
+	}
+	if err := pdm.SetProjectSetting(projectName, "data_dir", "/tmp"); err == nil {
+		t.Fatal("expected data_dir to be global only")
+	}
+
+	if err := pdm.SetProjectSetting(projectName, "lock.ttl", "90s"); err != nil {
+		t.Fatalf("project set failed: %v", err)
+	}
+	if got := pdm.lockTTL(projectName); got != 90 {
+		t.Fatalf("expected lock TTL 90s, got %d", got)
+	}
+
+	// A hand-edited bad value falls through to the next source.
+	t.Setenv("QUICKPLAN_LOCK_TTL", "forever")
+	if got := pdm.Setting(projectName, "lock.ttl"); got.Source != sourceProject {
+		t.Fatalf("expected invalid env value skipped, got %+v", got)
+	}
+
+	// Settings where 0 means "no limit" accept it; the others do not.
+	t.Setenv("QUICKPLAN_RUNS_KEEP", "0")
+	if got := pdm.SettingInt(projectName, "runs.keep"); got != 0 {
+		t.Fatalf("expected runs.keep 0 from the environment, got %d", got)
+	}
+	if err := pdm.SetProjectSetting(projectName, "runs.keep", "-1"); err == nil {
+		t.Fatal("expected a negative runs.keep to be rejected")
+	}
+	if err := pdm.SetProjectSetting(projectName, "daemon.max_agents", "0"); err == nil {
+		t.Fatal("expected daemon.max_agents 0 to be rejected")
+	}
+}
+
+func TestSetting_GlobalOnlyIgnoresProjectOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	overrides := map[string]string{
+		"web_url":               "http://attacker.example",
+		"registry_url":          "http://attacker.example",
+		"api_key":               "committed-secret",
+		"disable_local_sandbox": "true",
+	}
+	for key, value := range overrides {
+		t.Setenv(configSettingEnv(t, key), "")
+		if err := pdm.SetProjectSetting(projectName, key, value); err == nil {
+			t.Fatalf("expected %s to be global only", key)
+		}
+	}
+
+	// A project.yml from a cloned repository cannot override them either.
+	config, err := pdm.LoadProjectConfig(projectName)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	config.Settings = overrides
+	if err := pdm.SaveProjectConfig(projectName, config); err != nil {
+		t.Fatalf("save config failed: %v", err)
+	}
+	for key := range overrides {
+		if got := pdm.Setting(projectName, key); got.Source != sourceDefault {
+			t.Fatalf("expected project override of %s to be ignored, got %+v", key, got)
+		}
+	}
+	if pdm.SettingBool(projectName, "disable_local_sandbox") {
+		t.Fatal("expected the sandbox to stay enabled")
+	}
+}
+
+func configSettingEnv(t *testing.T, key string) string {
+	t.Helper()
+	setting, err := lookupSetting(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return setting.Env
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression: minute, hour, day of
+// month, month and day of week. Each field is a bit set of allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Cron matches a day when either day field matches, unless one of them
+	// is "*", in which case only the other counts.
+	domAny, dowAny bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronDayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// parseCron parses a standard five-field cron expression such as
+// "0 9 * * MON" or "*/15 8-18 * * 1-5", or one of the @daily style macros.
+func parseCron(expr string) (*cronSchedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields (minute hour day month weekday)", expr)
+	}
+
+	s := &cronSchedule{domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err == nil {
+		if s.hour, err = parseCronField(fields[1], 0, 23, nil); err == nil {
+			if s.dom, err = parseCronField(fields[2], 1, 31, nil); err == nil {
+				if s.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err == nil {
+					s.dow, err = parseCronField(fields[4], 0, 7, cronDayNames)
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	// 7 is Sunday too.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges (a-b),
+// steps (*/n, a-b/n, a/n) and, where names is set, names.
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	value := func(raw string) (int, error) {
+		if n, ok := names[strings.ToUpper(raw)]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("%q is not between %d and %d", raw, min, max)
+		}
+		return n, nil
+	}
+
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = value(b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("range %q runs backwards", rangePart)
+			}
+		default:
+			n, err := value(rangePart)
+			if err != nil {
+				return 0, err
+			}
+			lo = n
+			if !hasStep {
+				hi = n
+			}
+		}
+
+		for n := lo; n <= hi; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first time after t that matches the schedule, in t's
+// location, or the zero time if none does within five years (e.g. Feb 30).
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Thursday.
+	from := time.Date(2026, 3, 5, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 9 * * MON", time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 5, 10, 45, 0, 0, time.UTC)},
+		{"0 8-18/4 * * 1-5", time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 3, 6, 10, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches (the 15th, or a Friday).
+		{"0 0 15 * FRI", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		schedule, err := parseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("%q: parse failed: %v", tc.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: expected %s, got %s", tc.expr, tc.want, got)
+		}
+	}
+
+	never, _ := parseCron("0 0 30 2 *")
+	if got := never.Next(from); !got.IsZero() {
+		t.Errorf("expected Feb 30 never to fire, got %s", got)
+	}
+}
+
+func TestParseCron_RejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "* * * * FUNDAY"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is one validation finding, positioned in the source file so
+// editors and CI can point at it.
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// String renders the diagnostic in the file:line:column form compilers use.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s]", d.File, d.Line, d.Column, d.Severity, d.Message, d.Code)
+}
+
+// finding is a validation result located by task index, key and sequence
+// item rather than by line, so the same rules serve ValidateProjectV11 on
+// decoded structs and DiagnoseProjectV11 on YAML source. Task -1 means the
+// top level; Item -1 means the key's value as a whole.
+type finding struct {
+	Severity string
+	Code     string
+	Message  string
+	Task     int
+	Key      string
+	Item     int
+}
+
+func errorAt(task int, key string, item int, code, format string, args ...interface{}) finding {
+	return finding{Severity: SeverityError, Code: code, Message: fmt.Sprintf(format, args...), Task: task, Key: key, Item: item}
+}
+
+func warningAt(task int, key string, item int, code, format string, args ...interface{}) finding {
+	return finding{Severity: SeverityWarning, Code: code, Message: fmt.Sprintf(format, args...), Task: task, Key: key, Item: item}
+}
+
+// checkProjectV11 runs every schema v1.1 and later rule and returns all
+// findings instead of stopping at the first.
+func checkProjectV11(project *ProjectV11) []finding {
+	var findings []finding
+	if !isSupportedSchemaVersion(project.SchemaVersion) {
+		findings = append(findings, errorAt(-1, "schema_version", -1, "schema-version",
+			"unsupported schema version: %s (expected %s)", project.SchemaVersion, strings.Join(supportedSchemaVersions(), ", ")))
+	}
+	if !isValidLayout(project.Layout) {
+		findings = append(findings, errorAt(-1, "layout", -1, "layout-invalid",
+			"unknown layout: %s (expected %s)", project.Layout, strings.Join(projectLayouts, " or ")))
+	}
+
+	taskIDs := make(map[string]int)
+	for i, task := range project.Tasks {
+		// 1. Unique task ids
+		if task.ID == "" {
+			findings = append(findings, errorAt(i, "id", -1, "task-id-empty", "task ID cannot be empty"))
+		} else if first, ok := taskIDs[task.ID]; ok {
+			findings = append(findings, errorAt(i, "id", -1, "task-id-duplicate",
+				"duplicate task ID: %s (first used by task #%d)", task.ID, first+1))
+		} else {
+			taskIDs[task.ID] = i
+		}
+
+		if task.Name == "" {
+			findings = append(findings, warningAt(i, "name", -1, "task-name-empty", "task %s has no name", task.ID))
+		}
+
+		// 2. Valid status enum
+		if !isValidStatus(task.Status) {
+			findings = append(findings, errorAt(i, "status", -1, "task-status-invalid",
+				"invalid status for task %s: %s", task.ID, task.Status))
+		}
+
+		// 3. Task keys added after schema 1.1
+		findings = append(findings, taskSchemaFindings(project.SchemaVersion, i, task)...)
+		findings = append(findings, behaviorFindings(i, task)...)
+	}
+
+	// 4. depends_on references exist and no cycles
+	for i, task := range project.Tasks {
+		for j, depID := range task.DependsOn {
+			if _, ok := taskIDs[depID]; !ok {
+				findings = append(findings, errorAt(i, "depends_on", j, "dependency-missing",
+					"task %s depends on non-existent task %s", task.ID, depID))
+			}
+		}
+	}
+
+	for _, cycle := range dependencyCycles(project.Tasks) {
+		findings = append(findings, errorAt(cycle.Task, "depends_on", cycle.Item, "dependency-cycle",
+			"dependency cycle detected: %s", strings.Join(cycle.Path, " -> ")))
+	}
+
+	// 5. Subtask parents exist and do not loop
+	findings = append(findings, taskParentFindings(project.Tasks)...)
+
+	// 6. Recurrence schedules parse
+	findings = append(findings, recurrenceFindings(project.Tasks)...)
+
+	return findings
+}
+
+// dependencyCycle is one cycle, closed by DependsOn[Item] of Tasks[Task].
+type dependencyCycle struct {
+	Path []string
+	Task int
+	Item int
+}
+
+// dependencyCycles walks depends_on depth-first in task order and returns
+// every cycle it closes, each as the path of task IDs from the first task
+// on the cycle back to itself.
+func dependencyCycles(tasks []TaskV11) []dependencyCycle {
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		if _, ok := index[task.ID]; !ok {
+			index[task.ID] = i
+		}
+	}
+
+	visited := make(map[string]bool)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles []dependencyCycle
+
+	var visit func(id string)
+	visit = func(id string) {
+		visited[id] = true
+		onStack[id] = true
+		stack = append(stack, id)
+
+		i := index[id]
+		for j, depID := range tasks[i].DependsOn {
+			if _, ok := index[depID]; !ok {
+				continue
+			}
+			if onStack[depID] {
+				start := len(stack) - 1
+				for stack[start] != depID {
+					start--
+				}
+				path := append(append([]string{}, stack[start:]...), depID)
+				cycles = append(cycles, dependencyCycle{Path: path, Task: i, Item: j})
+			} else if !visited[depID] {
+				visit(depID)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		onStack[id] = false
+	}
+
+	for _, task := range tasks {
+		if !visited[task.ID] {
+			visit(task.ID)
+		}
+	}
+	return cycles
+}
+
+// firstError turns the first error-severity finding into an error.
+func firstError(findings []finding) error {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return errors.New(f.Message)
+		}
+	}
+	return nil
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// DiagnoseProjectV11 validates project.yaml source and returns every error
+// and warning with its line and column. The decoded project is returned as
+// well; it is nil when the file is not even valid YAML.
+func DiagnoseProjectV11(file string, data []byte) (*ProjectV11, []Diagnostic) {
+	var diagnostics []Diagnostic
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, append(diagnostics, yamlErrorDiagnostics(file, "yaml-syntax", err)...)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, append(diagnostics, Diagnostic{File: file, Line: 1, Column: 1, Severity: SeverityError, Code: "yaml-root", Message: "project.yaml must be a mapping"})
+	}
+	root := doc.Content[0]
+
+	var project ProjectV11
+	diagnostics = append(diagnostics, decodeProjectSections(file, root, &project)...)
+
+	var tasks []*yaml.Node
+	if tasksNode := mappingValue(root, "tasks"); tasksNode != nil && tasksNode.Kind == yaml.SequenceNode {
+		tasks = tasksNode.Content
+	}
+
+	for _, f := range unknownKeyFindings(root, tasks, &project) {
+		diagnostics = append(diagnostics, locateFinding(file, root, tasks, f))
+	}
+	for _, f := range checkProjectV11(&project) {
+		diagnostics = append(diagnostics, locateFinding(file, root, tasks, f))
+	}
+	return &project, diagnostics
+}
+
+// decodeProjectSections decodes project.yaml one top-level key, and one
+// task, at a time. A value that fails to decode (say, an unparseable
+// timestamp) then costs only its own section and is reported at its node,
+// instead of aborting the whole decode at an unknown position.
+func decodeProjectSections(file string, root *yaml.Node, project *ProjectV11) []Diagnostic {
+	var diagnostics []Diagnostic
+	decodeAt := func(node *yaml.Node, err error) {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			diagnostics = append(diagnostics, yamlErrorDiagnostics(file, "yaml-type", err)...)
+			return
+		}
+		diagnostics = append(diagnostics, Diagnostic{File: file, Line: node.Line, Column: node.Column, Severity: SeverityError, Code: "yaml-type", Message: err.Error()})
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value == "tasks" && value.Kind == yaml.SequenceNode {
+			for _, item := range value.Content {
+				var task TaskV11
+				if err := item.Decode(&task); err != nil {
+					decodeAt(item, err)
+				}
+				project.Tasks = append(project.Tasks, task)
+			}
+			continue
+		}
+		section := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, value}}
+		if err := section.Decode(project); err != nil {
+			decodeAt(value, err)
+		}
+	}
+	return diagnostics
+}
+
+// yamlErrorDiagnostics splits a yaml.v3 error into one diagnostic per line
+// it mentions.
+func yamlErrorDiagnostics(file, code string, err error) []Diagnostic {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	var diagnostics []Diagnostic
+	for _, message := range messages {
+		d := Diagnostic{File: file, Line: 1, Column: 1, Severity: SeverityError, Code: code, Message: strings.TrimPrefix(message, "yaml: ")}
+		if m := yamlErrorLine.FindStringSubmatch(message); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Message = m[2]
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// unknownKeyFindings warns about keys this build ignores, which are usually
+// typos such as depends-on for depends_on.
+func unknownKeyFindings(root *yaml.Node, tasks []*yaml.Node, project *ProjectV11) []finding {
+	var findings []finding
+	known := yamlKeys(reflect.TypeOf(ProjectV11{}))
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if key := root.Content[i].Value; !known[key] {
+			findings = append(findings, warningAt(-1, key, -2, "unknown-key", "unknown key %q is ignored", key))
+		}
+	}
+
+	known = yamlKeys(reflect.TypeOf(TaskV11{}))
+	for t, task := range tasks {
+		if task.Kind != yaml.MappingNode {
+			continue
+		}
+		id := ""
+		if t < len(project.Tasks) {
+			id = project.Tasks[t].ID
+		}
+		for i := 0; i+1 < len(task.Content); i += 2 {
+			if key := task.Content[i].Value; !known[key] {
+				findings = append(findings, warningAt(t, key, -2, "unknown-key", "unknown key %q in task %s is ignored", key, id))
+			}
+		}
+	}
+	return findings
+}
+
+// yamlKeys returns the YAML keys a struct type decodes.
+func yamlKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// locateFinding resolves a finding to the most specific node available:
+// the sequence item, the key's value, the task, or the document root. Item
+// -2 points at the key itself.
+func locateFinding(file string, root *yaml.Node, tasks []*yaml.Node, f finding) Diagnostic {
+	node := root
+	if f.Task >= 0 && f.Task < len(tasks) {
+		node = tasks[f.Task]
+	}
+	if f.Key != "" && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != f.Key {
+				continue
+			}
+			value := node.Content[i+1]
+			switch {
+			case f.Item == -2:
+				node = node.Content[i]
+			case f.Item >= 0 && value.Kind == yaml.SequenceNode && f.Item < len(value.Content):
+				node = value.Content[f.Item]
+			default:
+				node = value
+			}
+			break
+		}
+	}
+	return Diagnostic{File: file, Line: node.Line, Column: node.Column, Severity: f.Severity, Code: f.Code, Message: f.Message}
+}
+
+// hasErrorDiagnostics reports whether any diagnostic is an error.
+func hasErrorDiagnostics(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// printDiagnostics writes diagnostics one per line followed by a summary.
+func printDiagnostics(w io.Writer, diagnostics []Diagnostic) {
+	errorCount, warningCount := 0, 0
+	for _, d := range diagnostics {
+		fmt.Fprintln(w, d.String())
+		if d.Severity == SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", errorCount, warningCount)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseProjectV11_CollectsPositionedFindings(t *testing.T) {
+	source := `schema_version: "1.1"
+project:
+  name: demo
+tasks:
+  - id: t-1
+    name: one
+    status: TODO
+    depends_on: [t-3]
+  - id: t-1
+    name: dup
+    status: SLEEPING
+  - id: t-3
+    name: three
+    status: TODO
+    depends-on: [t-1]
+    depends_on:
+      - t-missing
+      - t-1
+`
+	_, diagnostics := DiagnoseProjectV11("project.yaml", []byte(source))
+
+	byCode := make(map[string]Diagnostic)
+	for _, d := range diagnostics {
+		if _, ok := byCode[d.Code]; !ok {
+			byCode[d.Code] = d
+		}
+	}
+
+	want := map[string]struct {
+		line, column int
+		severity     string
+	}{
+		"task-id-duplicate":   {9, 9, SeverityError},
+		"task-status-invalid": {11, 13, SeverityError},
+		"unknown-key":         {15, 5, SeverityWarning},
+		"dependency-missing":  {17, 9, SeverityError},
+		"dependency-cycle":    {18, 9, SeverityError},
+	}
+	for code, w := range want {
+		d, ok := byCode[code]
+		if !ok {
+			t.Fatalf("missing %s diagnostic in %v", code, diagnostics)
+		}
+		if d.Line != w.line || d.Column != w.column || d.Severity != w.severity {
+			t.Errorf("%s at %d:%d (%s), want %d:%d (%s)", code, d.Line, d.Column, d.Severity, w.line, w.column, w.severity)
+		}
+	}
+
+	if msg := byCode["dependency-cycle"].Message; !strings.Contains(msg, "t-1 -> t-3 -> t-1") {
+		t.Errorf("cycle message does not name the path: %s", msg)
+	}
+}
+
+func TestDiagnoseProjectV11_SyntaxErrorHasLine(t *testing.T) {
+	source := "schema_version: \"1.1\"\ntasks:\n  - id: t-1\n    name: [unclosed\n"
+	project, diagnostics := DiagnoseProjectV11("project.yaml", []byte(source))
+	if project != nil {
+		t.Fatal("expected no project for unparseable YAML")
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Code != "yaml-syntax" || diagnostics[0].Line < 2 {
+		t.Fatalf("expected one positioned syntax diagnostic, got %+v", diagnostics)
+	}
+}
+
+func TestDependencyCycles_NamesEveryCycle(t *testing.T) {
+	cycles := dependencyCycles([]TaskV11{
+		{ID: "a", DependsOn: []string{"b"}},
+		{ID: "b", DependsOn: []string{"a"}},
+		{ID: "c", DependsOn: []string{"c"}},
+		{ID: "d", DependsOn: []string{"a"}},
+	})
+	if len(cycles) != 2 {
+		t.Fatalf("expected two cycles, got %+v", cycles)
+	}
+	if got := strings.Join(cycles[0].Path, " -> "); got != "a -> b -> a" {
+		t.Errorf("unexpected first cycle %s", got)
+	}
+	if got := strings.Join(cycles[1].Path, " -> "); got != "c -> c" {
+		t.Errorf("unexpected second cycle %s", got)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Agenda buckets, in display order.
+const (
+	agendaOverdue  = "overdue"
+	agendaToday    = "today"
+	agendaThisWeek = "this_week"
+	agendaLater    = "later"
+)
+
+var agendaBuckets = []string{agendaOverdue, agendaToday, agendaThisWeek, agendaLater}
+
+// AgendaItem is one dated, unfinished task on the agenda.
+type AgendaItem struct {
+	Project  string     `json:"project"`
+	TaskID   string     `json:"task_id"`
+	Text     string     `json:"text"`
+	Status   string     `json:"status"`
+	Priority string     `json:"priority,omitempty"`
+	Due      *time.Time `json:"due,omitempty"`
+	Start    *time.Time `json:"start,omitempty"`
+	Bucket   string     `json:"bucket"`
+}
+
+// date is the day the item is planned for: its due date, or its start
+// date when it has none.
+func (item AgendaItem) date() time.Time {
+	if item.Due != nil {
+		return *item.Due
+	}
+	return *item.Start
+}
+
+// isTaskOpen reports whether a task still needs work.
+func isTaskOpen(status string) bool {
+	switch canonicalStatus(status) {
+	case "DONE", "CANCELLED":
+		return false
+	}
+	return true
+}
+
+// agendaBucket places a task due (or starting) at date relative to now. A
+// start date in the past only means the task can be worked on, so it
+// lands in today rather than overdue. The week ends on Sunday.
+func agendaBucket(date time.Time, isDue bool, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	tomorrow := today.AddDate(0, 0, 1)
+	daysLeft := (7 - int(today.Weekday())) % 7
+	nextWeek := tomorrow.AddDate(0, 0, daysLeft)
+
+	switch {
+	case isDue && date.Before(now):
+		return agendaOverdue
+	case date.Before(tomorrow):
+		return agendaToday
+	case date.Before(nextWeek):
+		return agendaThisWeek
+	}
+	return agendaLater
+}
+
+// Agenda collects the open tasks with a due or start date from projects,
+// bucketed relative to now and sorted by date, then priority. Legacy
+// projects have no dates and are skipped.
+func (pdm *ProjectDataManager) Agenda(projects []string, now time.Time) []AgendaItem {
+	var items []AgendaItem
+	for _, project := range projects {
+		v11, err := pdm.LoadProjectV11(project)
+		if err != nil {
+			continue
+		}
+		for _, task := range v11.Tasks {
+			if (task.Due == nil && task.Start == nil) || !isTaskOpen(task.Status) {
+				continue
+			}
+			item := AgendaItem{
+				Project:  project,
+				TaskID:   task.ID,
+				Text:     task.Name,
+				Status:   task.Status,
+				Priority: task.Priority,
+				Due:      task.Due,
+				Start:    task.Start,
+			}
+			item.Bucket = agendaBucket(item.date(), task.Due != nil, now)
+			items = append(items, item)
+		}
+	}
+
+	sortAgenda(items)
+	return items
+}
+
+// sortAgenda orders agenda items by date, then priority.
+func sortAgenda(items []AgendaItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].date(), items[j].date()
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return priorityRank(items[i].Priority) > priorityRank(items[j].Priority)
+	})
+}
+
+type overduePulse struct {
+	taskID, status, message string
+}
+
+// ReportOverdueTasks records a TASK_OVERDUE event and pulse for every open
+// task whose due date has passed by now. The reported due date is kept in
+// the task's overdue_notified, so each due date is reported once and moving
+// it re-arms the report. Projects below schema 1.3 cannot hold the marker
+// and are skipped.
+func (pdm *ProjectDataManager) ReportOverdueTasks(projectName, actor string, now time.Time) (int, error) {
+	var pulses []overduePulse
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if !tx.IsV11() || versionLess(tx.V11().SchemaVersion, schemaV13) {
+			return nil
+		}
+
+		for i := range tx.V11().Tasks {
+			task := &tx.V11().Tasks[i]
+			if task.Due == nil || !task.Due.Before(now) || !isTaskOpen(task.Status) {
+				continue
+			}
+			if task.OverdueNotified != nil && task.OverdueNotified.Equal(*task.Due) {
+				continue
+			}
+			due := *task.Due
+			task.OverdueNotified = &due
+			tx.MarkModified()
+
+			stamp := due.Format(time.RFC3339)
+			message := fmt.Sprintf("Due %s passed with task %s", stamp, task.Status)
+			tx.AppendEvent(Event{
+				Timestamp: now,
+				Type:      "TASK_OVERDUE",
+				Actor:     actor,
+				TaskID:    task.ID,
+				Message:   message,
+			})
+			pulses = append(pulses, overduePulse{task.ID, task.Status, message})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, p := range pulses {
+		SendPulseWithMessage(projectName, actor, p.taskID, p.status, p.status, "TASK_OVERDUE", p.message)
+	}
+	return len(pulses), nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAgendaBucket(t *testing.T) {
+	// A Wednesday afternoon; the week ends on Sunday the 8th.
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC)
+	cases := []struct {
+		date  time.Time
+		isDue bool
+		want  string
+	}{
+		{now.Add(-time.Hour), true, agendaOverdue},
+		{now.Add(-48 * time.Hour), false, agendaToday},
+		{time.Date(2026, 3, 4, 23, 59, 0, 0, time.UTC), true, agendaToday},
+		{time.Date(2026, 3, 8, 23, 59, 0, 0, time.UTC), true, agendaThisWeek},
+		{time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), true, agendaLater},
+	}
+	for _, tc := range cases {
+		if got := agendaBucket(tc.date, tc.isDue, now); got != tc.want {
+			t.Errorf("%s (due=%v): expected %s, got %s", tc.date, tc.isDue, tc.want, got)
+		}
+	}
+}
+
+func TestAgenda_CollectsOpenDatedTasksAcrossProjects(t *testing.T) {
+	pdm, legacyProject, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	now := time.Now()
+	yesterday, nextMonth := now.AddDate(0, 0, -1), now.AddDate(0, 1, 0)
+	for _, project := range []string{"alpha", "beta"} {
+		if err := pdm.CreateProject(project); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+	save := func(project string, tasks []TaskV11) {
+		v11 := &ProjectV11{SchemaVersion: schemaV12, Project: ProjectMeta{Name: project, CreatedAt: now}, Tasks: tasks}
+		if err := pdm.SaveProjectV11(project, v11); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+	save("alpha", []TaskV11{
+		{ID: "t-1", Name: "later", Status: "TODO", Due: &nextMonth},
+		{ID: "t-2", Name: "finished", Status: "DONE", Due: &yesterday},
+		{ID: "t-3", Name: "undated", Status: "TODO"},
+	})
+	save("beta", []TaskV11{
+		{ID: "t-1", Name: "late", Status: "IN_PROGRESS", Due: &yesterday},
+	})
+
+	items := pdm.Agenda([]string{"alpha", "beta", legacyProject}, now)
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Project+"/"+item.TaskID+":"+item.Bucket)
+	}
+	if want := "beta/t-1:overdue alpha/t-1:later"; strings.Join(got, " ") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestReportOverdueTasks_ReportsEachDueDateOnce(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	v11 := &ProjectV11{
+		SchemaVersion: schemaV13,
+		Project:       ProjectMeta{Name: projectName, CreatedAt: now},
+		Tasks: []TaskV11{
+			{ID: "t-1", Name: "late", Status: "TODO", Due: &past},
+			{ID: "t-2", Name: "done", Status: "DONE", Due: &past},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	for i, want := range []int{1, 0} {
+		n, err := pdm.ReportOverdueTasks(projectName, "daemon", now)
+		if err != nil {
+			t.Fatalf("report %d failed: %v", i, err)
+		}
+		if n != want {
+			t.Fatalf("report %d: expected %d overdue tasks, got %d", i, want, n)
+		}
+	}
+	reloaded, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if notified := reloaded.Tasks[0].OverdueNotified; notified == nil || !notified.Equal(past) {
+		t.Fatalf("expected overdue_notified to record the reported due date, got %v", notified)
+	}
+
+	// Moving the deadline re-arms the report once it passes again.
+	err = pdm.Update(projectName, func(tx *ProjectTx) error {
+		later := now.Add(30 * time.Minute)
+		tx.V11().Tasks[0].Due = &later
+		tx.MarkModified()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if n, _ := pdm.ReportOverdueTasks(projectName, "daemon", now); n != 0 {
+		t.Fatalf("expected no report before the new deadline, got %d", n)
+	}
+	if n, _ := pdm.ReportOverdueTasks(projectName, "daemon", now.Add(time.Hour)); n != 1 {
+		t.Fatalf("expected the new deadline to be reported, got %d", n)
+	}
+
+	stored, err := pdm.eventStore(projectName).ReadAll()
+	if err != nil {
+		t.Fatalf("read events failed: %v", err)
+	}
+	overdue := 0
+	for _, event := range stored {
+		if event.Type == "TASK_OVERDUE" {
+			overdue++
+			if event.TaskID != "t-1" || event.NextStatus != "" {
+				t.Fatalf("unexpected overdue event: %+v", event)
+			}
+		}
+	}
+	if overdue != 2 {
+		t.Fatalf("expected 2 TASK_OVERDUE events, got %d", overdue)
+	}
+}
+
+// TestReportOverdueTasks_TrustsOverdueNotified expects the marker alone,
+// without any TASK_OVERDUE event in the log, to suppress a repeat report.
+func TestReportOverdueTasks_TrustsOverdueNotified(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	v11 := &ProjectV11{
+		SchemaVersion: schemaV13,
+		Project:       ProjectMeta{Name: projectName, CreatedAt: now},
+		Tasks:         []TaskV11{{ID: "t-1", Name: "late", Status: "TODO", Due: &past, OverdueNotified: &past}},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if n, err := pdm.ReportOverdueTasks(projectName, "daemon", now); err != nil || n != 0 {
+		t.Fatalf("expected no report for an already notified due date, got %d (%v)", n, err)
+	}
+}
+
+func TestClaimNextRunnableTask_WaitsForStartDate(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	v11 := &ProjectV11{
+		SchemaVersion: schemaV13,
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks:         []TaskV11{{ID: "t-1", Name: "later", Status: "TODO", Start: &tomorrow}},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	claimed, err := pdm.ClaimNextRunnableTask(projectName, "worker-1")
+	if err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if claimed != nil {
+		t.Fatalf("expected no claim before the start date, got %+v", claimed)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// ReplayStatusMismatch: the stored status differs from the replayed one.
+	ReplayStatusMismatch = "status"
+	// ReplayUntracked: the task exists but no event ever set its status.
+	ReplayUntracked = "untracked"
+	// ReplayMissing: events describe a task that is not in the project.
+	ReplayMissing = "missing"
+)
+
+// ReplayDivergence is one disagreement between stored state and the event log.
+type ReplayDivergence struct {
+	TaskID   string `json:"task_id"`
+	Kind     string `json:"kind"`
+	Stored   string `json:"stored,omitempty"`
+	Replayed string `json:"replayed,omitempty"`
+}
+
+// ReplayReport summarizes an event log replay.
+type ReplayReport struct {
+	Project        string             `json:"project"`
+	EventsReplayed int                `json:"events_replayed"`
+	TasksReplayed  int                `json:"tasks_replayed"`
+	Divergences    []ReplayDivergence `json:"divergences"`
+	Repaired       int                `json:"repaired"`
+}
+
+// replayTaskStatuses folds an event stream into the status of every task it
+// mentions. Any event carrying NextStatus moves the task; TASK_DELETED
+// forgets it.
+func replayTaskStatuses(events []Event) map[string]string {
+	statuses := make(map[string]string)
+	for _, event := range events {
+		if event.TaskID == "" {
+			continue
+		}
+		switch {
+		case event.Type == "TASK_DELETED":
+			delete(statuses, event.TaskID)
+		case event.NextStatus != "":
+			statuses[event.TaskID] = event.NextStatus
+		case event.Type == "TASK_CREATED":
+			if _, ok := statuses[event.TaskID]; !ok {
+				statuses[event.TaskID] = "TODO"
+			}
+		}
+	}
+	return statuses
+}
+
+// diffReplay compares stored task views with replayed statuses. Statuses
+// are compared in canonical form, so TODO and PENDING agree.
+func diffReplay(views []TaskView, replayed map[string]string) []ReplayDivergence {
+	var divergences []ReplayDivergence
+	seen := make(map[string]bool, len(views))
+	for _, view := range views {
+		seen[view.ID] = true
+		status, ok := replayed[view.ID]
+		if !ok {
+			divergences = append(divergences, ReplayDivergence{TaskID: view.ID, Kind: ReplayUntracked, Stored: view.Status})
+			continue
+		}
+		if canonicalStatus(status) != canonicalStatus(view.Status) {
+			divergences = append(divergences, ReplayDivergence{TaskID: view.ID, Kind: ReplayStatusMismatch, Stored: view.Status, Replayed: status})
+		}
+	}
+
+	var missing []string
+	for id := range replayed {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	for _, id := range missing {
+		divergences = append(divergences, ReplayDivergence{TaskID: id, Kind: ReplayMissing, Replayed: replayed[id]})
+	}
+	return divergences
+}
+
+// ReplayEvents rebuilds task statuses from the project's event log alone and
+// diffs them against stored state. With repair set, status mismatches are
+// corrected in a single transaction, each recorded as a TASK_STATUS_REPAIRED
+// event so a later replay agrees. Untracked and missing tasks are reported
+// but never repaired: the log does not hold enough to recreate or drop them.
+func (pdm *ProjectDataManager) ReplayEvents(projectName string, repair bool) (*ReplayReport, error) {
+	eventLog, err := pdm.loadCanonicalEventLog(projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event log: %w", err)
+	}
+	replayed := replayTaskStatuses(eventLog.Events)
+
+	report := &ReplayReport{
+		Project:        projectName,
+		EventsReplayed: len(eventLog.Events),
+		TasksReplayed:  len(replayed),
+	}
+
+	if !repair {
+		views, _, err := pdm.GetTaskViews(projectName)
+		if err != nil {
+			return nil, err
+		}
+		report.Divergences = diffReplay(views, replayed)
+		return report, nil
+	}
+
+	err = pdm.Update(projectName, func(tx *ProjectTx) error {
+		report.Divergences = diffReplay(tx.Views(), replayed)
+		report.Repaired = 0
+
+		remaining := report.Divergences[:0]
+		for _, d := range report.Divergences {
+			if d.Kind != ReplayStatusMismatch {
+				remaining = append(remaining, d)
+				continue
+			}
+			if _, err := tx.applyStatus(d.TaskID, d.Replayed, ""); err != nil {
+				return err
+			}
+			tx.AppendEvent(Event{
+				Type:       "TASK_STATUS_REPAIRED",
+				Actor:      "system:replay",
+				TaskID:     d.TaskID,
+				PrevStatus: d.Stored,
+				NextStatus: d.Replayed,
+				Message:    "Status restored from event log replay",
+			})
+			report.Repaired++
+		}
+		report.Divergences = remaining
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplayTaskStatuses_FoldsEventStream(t *testing.T) {
+	statuses := replayTaskStatuses([]Event{
+		{Type: "TASK_CREATED", TaskID: "t-1"},
+		{Type: "TASK_STATUS_CHANGED", TaskID: "t-1", PrevStatus: "TODO", NextStatus: "IN_PROGRESS"},
+		{Type: "TASK_CREATED", TaskID: "t-2", NextStatus: "TODO"},
+		{Type: "TASK_DELETED", TaskID: "t-2"},
+		{Type: "NOTE", Message: "no task"},
+	})
+	if len(statuses) != 1 || statuses["t-1"] != "IN_PROGRESS" {
+		t.Fatalf("unexpected replay result: %+v", statuses)
+	}
+}
+
+func TestReplayEvents_DetectsAndRepairsDivergence(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks: []TaskV11{
+			{ID: "t-1", Name: "one", Status: "TODO"},
+			{ID: "t-2", Name: "two", Status: "TODO"},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if err := pdm.UpdateTaskStatus(projectName, "t-1", "IN_PROGRESS", "agent-1"); err != nil {
+		t.Fatalf("status update failed: %v", err)
+	}
+
+	// Corrupt the stored status behind the event log's back.
+	stored, _ := pdm.LoadProjectV11(projectName)
+	stored.Tasks[0].Status = "DONE"
+	if err := pdm.SaveProjectV11(projectName, stored); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	report, err := pdm.ReplayEvents(projectName, false)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	var mismatch *ReplayDivergence
+	for i, d := range report.Divergences {
+		if d.Kind == ReplayStatusMismatch {
+			mismatch = &report.Divergences[i]
+		}
+	}
+	if mismatch == nil || mismatch.TaskID != "t-1" || mismatch.Stored != "DONE" || mismatch.Replayed != "IN_PROGRESS" {
+		t.Fatalf("expected t-1 mismatch, got %+v", report.Divergences)
+	}
+
+	report, err = pdm.ReplayEvents(projectName, true)
+	if err != nil {
+		t.Fatalf("repair failed: %v", err)
+	}
+	if report.Repaired != 1 {
+		t.Fatalf("expected one repair, got %d", report.Repaired)
+	}
+	for _, d := range report.Divergences {
+		if d.Kind != ReplayUntracked || d.TaskID != "t-2" {
+			t.Fatalf("unexpected divergence left after repair: %+v", d)
+		}
+	}
+
+	repaired, _ := pdm.LoadProjectV11(projectName)
+	if repaired.Tasks[0].Status != "IN_PROGRESS" {
+		t.Fatalf("expected repaired status IN_PROGRESS, got %s", repaired.Tasks[0].Status)
+	}
+
+	report, _ = pdm.ReplayEvents(projectName, false)
+	for _, d := range report.Divergences {
+		if d.Kind == ReplayStatusMismatch {
+			t.Fatalf("replay after repair still diverges: %+v", d)
+		}
+	}
+}
@@ -0,0 +1,536 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	eventStoreDirName      = "events"
+	eventSegmentPrefix     = "events-"
+	eventSegmentSuffix     = ".jsonl"
+	eventWriterFile        = ".writer"
+	defaultEventSegmentMax = 4 << 20
+)
+
+// eventStoreMu serializes appends within this process; the store's flock
+// serializes them across processes.
+var eventStoreMu sync.Mutex
+
+// StoredEvent is an event together with its project-wide sequence number.
+type StoredEvent struct {
+	Seq int64
+	Event
+}
+
+// eventRecord is the on-disk JSONL form of a StoredEvent.
+type eventRecord struct {
+	Seq        int64     `json:"seq"`
+	Timestamp  time.Time `json:"ts"`
+	Type       string    `json:"type"`
+	Actor      string    `json:"actor"`
+	TaskID     string    `json:"task_id,omitempty"`
+	PrevStatus string    `json:"prev_status,omitempty"`
+	NextStatus string    `json:"next_status,omitempty"`
+	Message    string    `json:"message,omitempty"`
+}
+
+func (r eventRecord) stored() StoredEvent {
+	return StoredEvent{
+		Seq: r.Seq,
+		Event: Event{
+			Timestamp:  r.Timestamp,
+			Type:       r.Type,
+			Actor:      r.Actor,
+			TaskID:     r.TaskID,
+			PrevStatus: r.PrevStatus,
+			NextStatus: r.NextStatus,
+			Message:    r.Message,
+		},
+	}
+}
+
+// EventStore is the append-only event log of a v1.1 project: a directory of
+// JSONL segments holding one event per line. Each writer, meaning each copy
+// of the data directory, appends only to its own segments
+// (events-<writer>-000001.jsonl, ...), so when the directory is committed,
+// history recorded in two clones merges without conflicts. A writer's newest
+// segment is rotated once it grows past maxSegmentBytes. Segments named
+// events-000001.jsonl predate writer IDs and are still read.
+type EventStore struct {
+	dir             string
+	maxSegmentBytes int64
+}
+
+// NewEventStore returns the event store rooted at dir.
+func NewEventStore(dir string) *EventStore {
+	return &EventStore{dir: dir, maxSegmentBytes: defaultEventSegmentMax}
+}
+
+// eventStore returns the event store of a project.
+func (pdm *ProjectDataManager) eventStore(projectName string) *EventStore {
+	return NewEventStore(filepath.Join(pdm.dataDir, projectName, eventStoreDirName))
+}
+
+// eventSegment names one segment file. writer is empty for segments written
+// before writer IDs.
+type eventSegment struct {
+	writer string
+	index  int
+}
+
+func (s *EventStore) segmentPath(segment eventSegment) string {
+	name := fmt.Sprintf("%s%06d%s", eventSegmentPrefix, segment.index, eventSegmentSuffix)
+	if segment.writer != "" {
+		name = fmt.Sprintf("%s%s-%06d%s", eventSegmentPrefix, segment.writer, segment.index, eventSegmentSuffix)
+	}
+	return filepath.Join(s.dir, name)
+}
+
+// segments returns the existing segments ordered by writer, then index.
+func (s *EventStore) segments() ([]eventSegment, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read event store: %w", err)
+	}
+
+	var segments []eventSegment
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, eventSegmentPrefix) || !strings.HasSuffix(name, eventSegmentSuffix) {
+			continue
+		}
+		var segment eventSegment
+		rest := strings.TrimSuffix(strings.TrimPrefix(name, eventSegmentPrefix), eventSegmentSuffix)
+		if writer, index, ok := strings.Cut(rest, "-"); ok {
+			segment.writer, rest = writer, index
+		}
+		if segment.index, err = strconv.Atoi(rest); err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		if segments[i].writer != segments[j].writer {
+			return segments[i].writer < segments[j].writer
+		}
+		return segments[i].index < segments[j].index
+	})
+	return segments, nil
+}
+
+// writerID returns the ID this copy of the store appends under, creating it
+// on first use. The ID file is local state and never committed, so every
+// clone picks its own. The caller must hold the store lock.
+func (s *EventStore) writerID() (string, error) {
+	path := filepath.Join(s.dir, eventWriterFile)
+	if data, err := os.ReadFile(path); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read event writer ID: %w", err)
+	}
+
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate event writer ID: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+	if err := writeFileAtomic(path, []byte(id+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to save event writer ID: %w", err)
+	}
+	return id, nil
+}
+
+// Append writes events to the end of this writer's newest segment and
+// returns the sequence number of the last one, which continues from the
+// highest number any writer used. Only the tail of each writer's newest
+// segment is read.
+func (s *EventStore) Append(events ...Event) (int64, error) {
+	eventStoreMu.Lock()
+	defer eventStoreMu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create event store: %w", err)
+	}
+	lockF, err := os.OpenFile(filepath.Join(s.dir, ".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open event store lock: %w", err)
+	}
+	defer lockF.Close()
+	if err := lockFile(lockF); err != nil {
+		return 0, fmt.Errorf("failed to lock event store: %w", err)
+	}
+	defer unlockFile(lockF)
+
+	writer, err := s.writerID()
+	if err != nil {
+		return 0, err
+	}
+	segments, err := s.segments()
+	if err != nil {
+		return 0, err
+	}
+	lastSeq, err := s.lastSeq(segments, writer)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return lastSeq, nil
+	}
+
+	own := eventSegment{writer: writer, index: 1}
+	for _, segment := range segments {
+		if segment.writer == writer {
+			own = segment
+		}
+	}
+	path := s.segmentPath(own)
+	if info, err := os.Stat(path); err == nil && info.Size() >= s.maxSegmentBytes {
+		own.index++
+		path = s.segmentPath(own)
+	}
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		lastSeq++
+		if event.Timestamp.IsZero() {
+			event.Timestamp = time.Now()
+		}
+		line, err := json.Marshal(eventRecord{
+			Seq:        lastSeq,
+			Timestamp:  event.Timestamp,
+			Type:       event.Type,
+			Actor:      event.Actor,
+			TaskID:     event.TaskID,
+			PrevStatus: event.PrevStatus,
+			NextStatus: event.NextStatus,
+			Message:    event.Message,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal event: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	_, statErr := os.Stat(path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open event segment: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("failed to append events: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("failed to sync events: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	if os.IsNotExist(statErr) {
+		if err := syncDir(s.dir); err != nil {
+			return 0, err
+		}
+	}
+	return lastSeq, nil
+}
+
+// LastSeq returns the sequence number of the newest event, or 0 when the
+// store is empty.
+func (s *EventStore) LastSeq() (int64, error) {
+	segments, err := s.segments()
+	if err != nil {
+		return 0, err
+	}
+	return s.lastSeq(segments, "")
+}
+
+// lastSeq returns the highest sequence number across all writers, scanning
+// each writer's segments newest first for its last complete record. A torn
+// record that a crashed process left in one of repairWriter's segments is
+// cut off so the next append starts on a clean line; callers passing a
+// repairWriter must hold the store lock.
+func (s *EventStore) lastSeq(segments []eventSegment, repairWriter string) (int64, error) {
+	var last int64
+	found := make(map[string]bool)
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if found[segment.writer] {
+			continue
+		}
+		path := s.segmentPath(segment)
+		rec, end, size, err := lastSegmentRecord(path)
+		if err != nil {
+			return 0, err
+		}
+		if repairWriter != "" && segment.writer == repairWriter && end < size {
+			if err := os.Truncate(path, end); err != nil {
+				return 0, fmt.Errorf("failed to repair event segment: %w", err)
+			}
+		}
+		if rec != nil {
+			found[segment.writer] = true
+			last = max(last, rec.Seq)
+		}
+	}
+	return last, nil
+}
+
+// lastSegmentRecord reads a segment backwards until it finds the last
+// newline-terminated record. It returns that record, the offset just past
+// it and the segment size.
+func lastSegmentRecord(path string) (*eventRecord, int64, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to open event segment: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	size := info.Size()
+
+	var tail []byte
+	pos := size
+	for pos > 0 {
+		n := min(int64(4096), pos)
+		pos -= n
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return nil, 0, 0, fmt.Errorf("failed to read event segment: %w", err)
+		}
+		tail = append(chunk, tail...)
+
+		lastNL := bytes.LastIndexByte(tail, '\n')
+		if lastNL < 0 {
+			continue
+		}
+		start := bytes.LastIndexByte(tail[:lastNL], '\n')
+		if start < 0 && pos > 0 {
+			continue
+		}
+
+		var rec eventRecord
+		if err := json.Unmarshal(tail[start+1:lastNL], &rec); err != nil {
+			return nil, 0, 0, fmt.Errorf("corrupt event record in %s: %w", filepath.Base(path), err)
+		}
+		return &rec, pos + int64(lastNL) + 1, size, nil
+	}
+	return nil, 0, size, nil
+}
+
+// readSegment returns every complete record in a segment. A trailing record
+// without a newline is an in-flight or torn append and is skipped.
+func readSegment(path string) ([]StoredEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event segment: %w", err)
+	}
+	defer f.Close()
+
+	var events []StoredEvent
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event segment: %w", err)
+		}
+		var rec eventRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("corrupt event record in %s: %w", filepath.Base(path), err)
+		}
+		events = append(events, rec.stored())
+	}
+}
+
+// ReadAll returns the whole log in sequence order.
+func (s *EventStore) ReadAll() ([]StoredEvent, error) {
+	segments, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []StoredEvent
+	for _, segment := range segments {
+		records, err := readSegment(s.segmentPath(segment))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, records...)
+	}
+	sortStoredEvents(events)
+	return events, nil
+}
+
+// Tail returns up to the last n events, reading only the segments needed:
+// the last n events of the log are among the last n of each writer.
+func (s *EventStore) Tail(n int) ([]StoredEvent, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	segments, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []StoredEvent
+	read := make(map[string]int)
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if read[segment.writer] >= n {
+			continue
+		}
+		records, err := readSegment(s.segmentPath(segment))
+		if err != nil {
+			return nil, err
+		}
+		read[segment.writer] += len(records)
+		events = append(events, records...)
+	}
+	sortStoredEvents(events)
+	if len(events) > n {
+		events = events[len(events)-n:]
+	}
+	return events, nil
+}
+
+// sortStoredEvents orders events by sequence number. Histories recorded on
+// two branches can reuse the same numbers once merged; those are ordered by
+// time.
+func sortStoredEvents(events []StoredEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Seq != events[j].Seq {
+			return events[i].Seq < events[j].Seq
+		}
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+}
+
+// numberEvents assigns sequence numbers to events from a source without
+// them (the legacy sidecar or events embedded in project.yaml).
+func numberEvents(events []Event) []StoredEvent {
+	stored := make([]StoredEvent, len(events))
+	for i, event := range events {
+		stored[i] = StoredEvent{Seq: int64(i + 1), Event: event}
+	}
+	return stored
+}
+
+// moveEmbeddedEvents moves events embedded in a v1.1 project into the event
+// store and clears them from v11. When the store already has history, the
+// embedded events are appended after it with new sequence numbers; those
+// the store already holds, left by an interrupted move, are skipped. The
+// caller must hold the project lock and save v11 afterwards.
+func (pdm *ProjectDataManager) moveEmbeddedEvents(projectName string, v11 *ProjectV11) (int, error) {
+	if len(v11.Events) == 0 {
+		return 0, nil
+	}
+	store := pdm.eventStore(projectName)
+	stored, err := store.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[Event]bool, len(stored))
+	for _, event := range stored {
+		seen[sameEventKey(event.Event)] = true
+	}
+	var missing []Event
+	for _, event := range v11.Events {
+		if !seen[sameEventKey(event)] {
+			missing = append(missing, event)
+		}
+	}
+	if len(missing) > 0 {
+		if _, err := store.Append(missing...); err != nil {
+			return 0, fmt.Errorf("failed to move embedded events: %w", err)
+		}
+	}
+	v11.Events = nil
+	return len(missing), nil
+}
+
+// sameEventKey normalizes an event for duplicate detection: a timestamp
+// read back from the store has lost its monotonic clock reading and may
+// differ in location.
+func sameEventKey(event Event) Event {
+	event.Timestamp = event.Timestamp.UTC().Round(0)
+	return event
+}
+
+// MigrateEmbeddedEvents moves the events embedded in a v1.1 project.yaml into
+// the project's event store and returns how many were moved.
+func (pdm *ProjectDataManager) MigrateEmbeddedEvents(projectName string) (int, error) {
+	moved := 0
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if !tx.IsV11() {
+			return fmt.Errorf("project '%s' is not a v1.1 project", projectName)
+		}
+		if len(tx.V11().Events) == 0 {
+			return nil
+		}
+		var err error
+		moved, err = pdm.moveEmbeddedEvents(projectName, tx.V11())
+		if err != nil {
+			return err
+		}
+		tx.MarkModified()
+		return nil
+	})
+	return moved, err
+}
+
+// TailEvents returns up to the last n events of a project along with the
+// total number of events, whatever storage the project uses.
+func (pdm *ProjectDataManager) TailEvents(projectName string, n int) ([]StoredEvent, int64, error) {
+	v11File := filepath.Join(pdm.dataDir, projectName, "project.yaml")
+	if _, err := os.Stat(v11File); err == nil {
+		store := pdm.eventStore(projectName)
+		lastSeq, err := store.LastSeq()
+		if err != nil {
+			return nil, 0, err
+		}
+		if lastSeq > 0 {
+			events, err := store.Tail(n)
+			return events, lastSeq, err
+		}
+	}
+
+	eventLog, err := pdm.loadCanonicalEventLog(projectName)
+	if err != nil {
+		return nil, 0, err
+	}
+	all := numberEvents(eventLog.Events)
+	total := int64(len(all))
+	if n <= 0 {
+		return nil, total, nil
+	}
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+	return all, total, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEventStore_AppendAssignsSequenceNumbers(t *testing.T) {
+	store := NewEventStore(filepath.Join(t.TempDir(), "events"))
+
+	last, err := store.Append(Event{Type: "A"}, Event{Type: "B"})
+	if err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+	if last != 2 {
+		t.Fatalf("expected last seq 2, got %d", last)
+	}
+	if last, _ = store.Append(Event{Type: "C"}); last != 3 {
+		t.Fatalf("expected last seq 3, got %d", last)
+	}
+
+	events, err := store.ReadAll()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(events) != 3 || events[0].Seq != 1 || events[2].Seq != 3 || events[2].Type != "C" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if events[0].Timestamp.IsZero() {
+		t.Fatal("expected append to stamp missing timestamps")
+	}
+}
+
+func TestEventStore_RotatesSegments(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "events")
+	store := NewEventStore(dir)
+	store.maxSegmentBytes = 200
+
+	for i := 0; i < 10; i++ {
+		if _, err := store.Append(Event{Type: "TICK", Actor: "test", Message: "padding padding padding"}); err != nil {
+			t.Fatalf("append %d failed: %v", i, err)
+		}
+	}
+
+	segments, _ := store.segments()
+	if len(segments) < 2 {
+		t.Fatalf("expected rotation into several segments, got %v", segments)
+	}
+
+	tail, err := store.Tail(3)
+	if err != nil {
+		t.Fatalf("tail failed: %v", err)
+	}
+	if len(tail) != 3 || tail[0].Seq != 8 || tail[2].Seq != 10 {
+		t.Fatalf("unexpected tail: %+v", tail)
+	}
+	if last, _ := store.LastSeq(); last != 10 {
+		t.Fatalf("expected last seq 10 across segments, got %d", last)
+	}
+}
+
+func TestEventStore_RepairsTornTail(t *testing.T) {
+	store := NewEventStore(filepath.Join(t.TempDir(), "events"))
+	if _, err := store.Append(Event{Type: "A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a writer that crashed halfway through a record.
+	segments, _ := store.segments()
+	f, _ := os.OpenFile(store.segmentPath(segments[0]), os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"seq":2,"ts":"2026-`)
+	f.Close()
+
+	events, err := store.ReadAll()
+	if err != nil || len(events) != 1 {
+		t.Fatalf("expected torn record to be skipped, got %+v (err=%v)", events, err)
+	}
+
+	last, err := store.Append(Event{Type: "B"})
+	if err != nil {
+		t.Fatalf("append after torn write failed: %v", err)
+	}
+	if last != 2 {
+		t.Fatalf("expected seq 2 after repair, got %d", last)
+	}
+	events, err = store.ReadAll()
+	if err != nil || len(events) != 2 || events[1].Type != "B" {
+		t.Fatalf("unexpected events after repair: %+v (err=%v)", events, err)
+	}
+}
+
+func TestEventStore_ClonesAppendToTheirOwnSegments(t *testing.T) {
+	dirA := filepath.Join(t.TempDir(), "events")
+	storeA := NewEventStore(dirA)
+	if _, err := storeA.Append(Event{Type: "BASE"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A clone copies the committed segments but not the writer ID.
+	dirB := filepath.Join(t.TempDir(), "events")
+	if err := os.MkdirAll(dirB, 0755); err != nil {
+		t.Fatal(err)
+	}
+	segments, _ := storeA.segments()
+	base := filepath.Base(storeA.segmentPath(segments[0]))
+	data, _ := os.ReadFile(filepath.Join(dirA, base))
+	if err := os.WriteFile(filepath.Join(dirB, base), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	storeB := NewEventStore(dirB)
+
+	if _, err := storeA.Append(Event{Timestamp: time.Unix(100, 0), Type: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storeB.Append(Event{Timestamp: time.Unix(200, 0), Type: "B"}); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.ReadFile(filepath.Join(dirB, base)); !bytes.Equal(after, data) {
+		t.Fatal("expected clone B to leave clone A's segment untouched")
+	}
+
+	// Only A changed its segment, so merging is copying B's new one over.
+	segments, _ = storeB.segments()
+	for _, segment := range segments {
+		name := filepath.Base(storeB.segmentPath(segment))
+		if name == base {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dirA, name)); err == nil {
+			t.Fatalf("expected %s to exist only in clone B", name)
+		}
+		data, _ := os.ReadFile(filepath.Join(dirB, name))
+		if err := os.WriteFile(filepath.Join(dirA, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, err := storeA.ReadAll()
+	if err != nil || len(events) != 3 || events[0].Type != "BASE" || events[1].Type != "A" || events[2].Type != "B" {
+		t.Fatalf("expected the merged history in order, got %+v (err=%v)", events, err)
+	}
+	if tail, _ := storeA.Tail(1); len(tail) != 1 || tail[0].Type != "B" {
+		t.Fatalf("expected tail to see the merged segment, got %+v", tail)
+	}
+	if last, err := storeA.Append(Event{Type: "AFTER"}); err != nil || last != 3 {
+		t.Fatalf("expected numbering to continue after the merge, got %d (err=%v)", last, err)
+	}
+}
+
+func TestAppendEventV11_MovesEmbeddedEventsFirst(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks:         []TaskV11{{ID: "t-1", Name: "one", Status: "TODO"}},
+		Events: []Event{
+			{Timestamp: time.Unix(100, 0), Type: "TASK_CREATED", Actor: "human", TaskID: "t-1"},
+			{Timestamp: time.Unix(200, 0), Type: "NOTE", Actor: "human"},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	if err := pdm.AppendEvent(projectName, Event{Type: "AFTER", Actor: "test"}); err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+
+	stored, _ := pdm.LoadProjectV11(projectName)
+	if len(stored.Events) != 0 {
+		t.Fatalf("expected embedded events to be moved out, got %d", len(stored.Events))
+	}
+
+	events, total, err := pdm.TailEvents(projectName, 10)
+	if err != nil {
+		t.Fatalf("tail failed: %v", err)
+	}
+	if total != 3 || len(events) != 3 {
+		t.Fatalf("expected 3 events, got total=%d events=%+v", total, events)
+	}
+	if events[0].Type != "TASK_CREATED" || events[2].Type != "AFTER" || events[2].Seq != 3 {
+		t.Fatalf("expected embedded history before new event, got %+v", events)
+	}
+
+	if moved, err := pdm.MigrateEmbeddedEvents(projectName); err != nil || moved != 0 {
+		t.Fatalf("expected second migration to be a no-op, got moved=%d err=%v", moved, err)
+	}
+}
+
+func TestMigrateEmbeddedEvents_KeepsEventsWhenStoreHasHistory(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	leftover := Event{Timestamp: time.Unix(100, 0), Type: "TASK_CREATED", Actor: "human", TaskID: "t-1"}
+	if _, err := pdm.eventStore(projectName).Append(leftover, Event{Timestamp: time.Unix(150, 0), Type: "NOTE", Actor: "worker-1"}); err != nil {
+		t.Fatal(err)
+	}
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks:         []TaskV11{{ID: "t-1", Name: "one", Status: "TODO"}},
+		Events:        []Event{leftover, {Timestamp: time.Unix(200, 0), Type: "MERGED", Actor: "human", TaskID: "t-1"}},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	moved, err := pdm.MigrateEmbeddedEvents(projectName)
+	if err != nil || moved != 1 {
+		t.Fatalf("expected only the event missing from the store to move, got %d (%v)", moved, err)
+	}
+	events, err := pdm.eventStore(projectName).ReadAll()
+	if err != nil || len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v (%v)", events, err)
+	}
+	if events[2].Type != "MERGED" || events[2].Seq != 3 {
+		t.Fatalf("expected the embedded event appended as seq 3, got %+v", events[2])
+	}
+	if stored, _ := pdm.LoadProjectV11(projectName); len(stored.Events) != 0 {
+		t.Fatalf("expected embedded events cleared, got %d", len(stored.Events))
+	}
+}
@@ -102,13 +102,17 @@ func TestAppendEventV11(t *testing.T) {
 		t.Fatalf("Failed to append event to v1.1: %v", err)
 	}
 
-	// Verify project.yaml contains the event
+	// Verify the event went to the event store, not project.yaml
 	reloaded, _ := pdm.LoadProjectV11(projectName)
-	if len(reloaded.Events) != 1 {
-		t.Errorf("Expected 1 embedded event, got %d", len(reloaded.Events))
+	if len(reloaded.Events) != 0 {
+		t.Errorf("Expected no embedded events, got %d", len(reloaded.Events))
 	}
-	if reloaded.Events[0].Type != "TEST_EVENT" {
-		t.Errorf("Expected TEST_EVENT, got %s", reloaded.Events[0].Type)
+	stored, err := pdm.eventStore(projectName).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read event store: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Type != "TEST_EVENT" || stored[0].Seq != 1 {
+		t.Errorf("Expected TEST_EVENT with seq 1 in event store, got %+v", stored)
 	}
 
 	// Verify events.yaml sidecar does NOT exist
@@ -41,6 +41,7 @@ type ExecutionProjectionSnapshotJSON struct {
 	Failed      int    `json:"failed"`
 	Cancelled   int    `json:"cancelled"`
 	Runnable    int    `json:"runnable"`
+	Groups      int    `json:"groups,omitempty"`
 	AllTerminal bool   `json:"all_terminal"`
 	Summary     string `json:"summary"`
 }
@@ -61,7 +62,7 @@ func (pdm *ProjectDataManager) BuildExecutionProjectionBundle(projectName string
 		limit = defaultExecutionProjectionLimit
 	}
 
-	eventLog, err := pdm.loadCanonicalEventLog(projectName)
+	stored, totalEvents, err := pdm.TailEvents(projectName, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -71,16 +72,10 @@ func (pdm *ProjectDataManager) BuildExecutionProjectionBundle(projectName string
 		return nil, err
 	}
 
-	totalEvents := len(eventLog.Events)
-	startIndex := 0
-	if totalEvents > limit {
-		startIndex = totalEvents - limit
-	}
-
-	events := make([]ExecutionProjectionEvent, 0, totalEvents-startIndex)
-	for idx, event := range eventLog.Events[startIndex:] {
+	events := make([]ExecutionProjectionEvent, 0, len(stored))
+	for _, event := range stored {
 		events = append(events, ExecutionProjectionEvent{
-			Sequence:   startIndex + idx + 1,
+			Sequence:   int(event.Seq),
 			Timestamp:  event.Timestamp,
 			Type:       event.Type,
 			Actor:      event.Actor,
@@ -93,9 +88,9 @@ func (pdm *ProjectDataManager) BuildExecutionProjectionBundle(projectName string
 
 	window := ExecutionProjectionEventWindow{
 		Limit:          limit,
-		TotalEvents:    totalEvents,
+		TotalEvents:    int(totalEvents),
 		IncludedEvents: len(events),
-		Truncated:      startIndex > 0,
+		Truncated:      int(totalEvents) > len(events),
 	}
 	if len(events) > 0 {
 		window.StartSequence = events[0].Sequence
@@ -117,6 +112,7 @@ func (pdm *ProjectDataManager) BuildExecutionProjectionBundle(projectName string
 			Failed:      snapshot.Failed,
 			Cancelled:   snapshot.Cancelled,
 			Runnable:    snapshot.Runnable,
+			Groups:      snapshot.Groups,
 			AllTerminal: snapshot.AllTerminal,
 			Summary:     snapshot.Summary(),
 		},
@@ -128,6 +124,19 @@ func (pdm *ProjectDataManager) loadCanonicalEventLog(projectName string) (*Event
 	projectPath := filepath.Join(pdm.dataDir, projectName)
 	v11File := filepath.Join(projectPath, "project.yaml")
 	if _, err := os.Stat(v11File); err == nil {
+		stored, err := pdm.eventStore(projectName).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(stored) > 0 {
+			events := make([]Event, len(stored))
+			for i, event := range stored {
+				events[i] = event.Event
+			}
+			return &EventLog{SchemaVersion: "events-0.1", Events: events}, nil
+		}
+
+		// Not migrated yet: history is still embedded in project.yaml.
 		v11, loadErr := pdm.LoadProjectV11(projectName)
 		if loadErr != nil {
 			return nil, loadErr
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Git-backed history: when a project's sync_source.type is "git", every
+// save through ProjectDataManager is committed in the project directory,
+// authored by the actor of the events it carries. Commits stay local; no
+// remote is ever contacted.
+const (
+	syncSourceGit = "git"
+	// historyAuthor signs commits for saves that carry no event.
+	historyAuthor = "quickplan"
+	// historyEmailDomain completes actor names into author emails.
+	historyEmailDomain = "quickplan.local"
+)
+
+// HistoryEntry is one commit in a project's history.
+type HistoryEntry struct {
+	Rev     string    `json:"rev"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// historyEnabled reports whether projectName keeps git history. It reads
+// project.yml directly so it never creates the file or takes the lock.
+func (pdm *ProjectDataManager) historyEnabled(projectName string) bool {
+	data, err := os.ReadFile(filepath.Join(pdm.dataDir, projectName, "project.yml"))
+	if err != nil {
+		return false
+	}
+	var config ProjectConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return false
+	}
+	return config.SyncSource.Type == syncSourceGit
+}
+
+// historySnapshot returns the task views a save is about to replace, or
+// nil when the project keeps no history.
+func (pdm *ProjectDataManager) historySnapshot(projectName string) ([]TaskView, bool) {
+	if !pdm.historyEnabled(projectName) {
+		return nil, false
+	}
+	views, _, _ := pdm.GetTaskViews(projectName)
+	return views, true
+}
+
+// recordHistory commits the project directory after a save. The save has
+// already succeeded, so a failing commit is reported as a warning rather
+// than undoing it.
+func (pdm *ProjectDataManager) recordHistory(projectName string, before []TaskView, events []Event) {
+	after, _, _ := pdm.GetTaskViews(projectName)
+	lines, author := describeTaskChanges(before, after, events)
+	if err := pdm.commitHistory(projectName, lines, author); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record git history for project '%s': %v\n", projectName, err)
+	}
+}
+
+// recordHistoryLine commits a change that is not a task edit, such as a
+// migration, with a fixed message.
+func (pdm *ProjectDataManager) recordHistoryLine(projectName, line string) {
+	if !pdm.historyEnabled(projectName) {
+		return
+	}
+	if err := pdm.commitHistory(projectName, []string{line}, "human"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record git history for project '%s': %v\n", projectName, err)
+	}
+}
+
+// describeTaskChanges returns one message line per change, e.g.
+// "t-4: PENDING -> IN_PROGRESS by worker-2", and the commit author. Events
+// describe the change when there are any; otherwise the task lists are
+// compared.
+func describeTaskChanges(before, after []TaskView, events []Event) ([]string, string) {
+	var lines []string
+	author := historyAuthor
+	for _, event := range events {
+		if author == historyAuthor && event.Actor != "" {
+			author = event.Actor
+		}
+		change := event.Type
+		if event.PrevStatus != "" && event.NextStatus != "" {
+			change = fmt.Sprintf("%s -> %s", event.PrevStatus, event.NextStatus)
+		} else if event.Message != "" {
+			change = event.Message
+		}
+		line := change
+		if event.TaskID != "" {
+			line = event.TaskID + ": " + change
+		}
+		if event.Actor != "" {
+			line += " by " + event.Actor
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		return lines, author
+	}
+
+	previous := make(map[string]TaskView, len(before))
+	for _, view := range before {
+		previous[view.ID] = view
+	}
+	seen := make(map[string]bool, len(after))
+	for _, view := range after {
+		seen[view.ID] = true
+		old, ok := previous[view.ID]
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("%s: added", view.ID))
+		case old.Status != view.Status:
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", view.ID, old.Status, view.Status))
+		case fmt.Sprintf("%+v", old) != fmt.Sprintf("%+v", view):
+			lines = append(lines, fmt.Sprintf("%s: updated", view.ID))
+		}
+	}
+	for _, view := range before {
+		if !seen[view.ID] {
+			lines = append(lines, fmt.Sprintf("%s: deleted", view.ID))
+		}
+	}
+	return lines, author
+}
+
+// commitHistory stages and commits the project directory. Only paths inside
+// it are committed, so a project in a repository-local .quickplan/ leaves
+// the rest of the enclosing repository alone.
+func (pdm *ProjectDataManager) commitHistory(projectName string, lines []string, author string) error {
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	if err := ensureHistoryRepo(projectPath, filepath.Base(pdm.dataDir) == repoDirName); err != nil {
+		return err
+	}
+
+	if _, err := runGit(projectPath, nil, "add", "-A", "--", "."); err != nil {
+		return err
+	}
+	if _, err := runGit(projectPath, nil, "diff", "--cached", "--quiet", "--", "."); err == nil {
+		return nil // nothing changed on disk
+	}
+
+	subject := "Update project"
+	if len(lines) > 0 {
+		subject = lines[0]
+	}
+	args := []string{"commit", "--quiet", "--no-verify", "-m", subject}
+	if len(lines) > 1 {
+		subject = fmt.Sprintf("%s (+%d more)", lines[0], len(lines)-1)
+		args = []string{"commit", "--quiet", "--no-verify", "-m", subject, "-m", strings.Join(lines, "\n")}
+	}
+	args = append(args, "--", ".")
+	_, err := runGit(projectPath, historyIdentity(author), args...)
+	return err
+}
+
+// historyEmailUnsafe matches what may not appear in the local part of a
+// generated author email.
+var historyEmailUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// historyIdentity sets the author to actor and the committer to quickplan,
+// so commits work without any git user configuration.
+func historyIdentity(actor string) []string {
+	name := strings.TrimSpace(actor)
+	if name == "" {
+		name = historyAuthor
+	}
+	local := strings.Trim(historyEmailUnsafe.ReplaceAllString(name, "-"), "-")
+	if local == "" {
+		local = historyAuthor
+	}
+	return []string{
+		"GIT_AUTHOR_NAME=" + name,
+		"GIT_AUTHOR_EMAIL=" + local + "@" + historyEmailDomain,
+		"GIT_COMMITTER_NAME=" + historyAuthor,
+		"GIT_COMMITTER_EMAIL=" + historyAuthor + "@" + historyEmailDomain,
+	}
+}
+
+// ensureHistoryRepo makes sure projectPath has a repository to commit to
+// and writes the project's .gitignore. A repository-local project (shared)
+// commits to the repository enclosing it; any other project gets its own,
+// so a data directory under a dotfiles repository never commits there.
+func ensureHistoryRepo(projectPath string, shared bool) error {
+	toplevel, err := runGit(projectPath, nil, "rev-parse", "--show-toplevel")
+	own := false
+	if err == nil {
+		resolved, _ := filepath.EvalSymlinks(projectPath)
+		own = filepath.Clean(toplevel) == filepath.Clean(resolved)
+	}
+	if err != nil || (!shared && !own) {
+		if _, err := runGit(projectPath, nil, "init", "--quiet"); err != nil {
+			return err
+		}
+	}
+	ignorePath := filepath.Join(projectPath, ".gitignore")
+	if _, err := os.Stat(ignorePath); os.IsNotExist(err) {
+		if err := writeFileAtomic(ignorePath, []byte(runtimeGitignore), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGit runs git in dir with signing and pagers disabled and returns its
+// trimmed output.
+func runGit(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-c", "commit.gpgSign=false", "--no-pager"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// EnableHistory sets the project's sync source to git and records the
+// current state as the first commit.
+func (pdm *ProjectDataManager) EnableHistory(projectName string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git history needs the git executable: %w", err)
+	}
+	config, err := pdm.LoadProjectConfig(projectName)
+	if err != nil {
+		return err
+	}
+	if config.SyncSource.Type != syncSourceGit {
+		config.SyncSource = SyncSource{Type: syncSourceGit}
+		// The first commit below includes the changed project.yml.
+		if err := pdm.saveProjectConfig(projectName, config, ""); err != nil {
+			return err
+		}
+	}
+
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return err
+	}
+	defer pdm.ReleaseLock(projectName)
+	return pdm.commitHistory(projectName, []string{"Start git history"}, "human")
+}
+
+// History lists the project's commits, newest first. With taskID only
+// commits that changed that task are returned.
+func (pdm *ProjectDataManager) History(projectName, taskID string, limit int) ([]HistoryEntry, error) {
+	if !pdm.historyEnabled(projectName) {
+		return nil, fmt.Errorf("project '%s' has no git history (enable it with 'quickplan history --enable')", projectName)
+	}
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+
+	args := []string{"log", "--format=%H%x1f%an%x1f%aI%x1f%s"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	if taskID != "" {
+		args = append(args, "--extended-regexp", "--grep=^"+regexp.QuoteMeta(taskID)+":")
+	}
+	args = append(args, "--", ".")
+	out, err := runGit(projectPath, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		entries = append(entries, HistoryEntry{Rev: fields[0], Author: fields[1], Date: date, Subject: fields[3]})
+	}
+	return entries, nil
+}
+
+// historyRestoreExcludes are left alone by RevertHistory: the event log is
+// append-only and project.yml holds the history setting itself.
+var historyRestoreExcludes = []string{":(exclude)events", ":(exclude)events.yaml", ":(exclude)project.yml"}
+
+// RevertHistory restores the project's tasks to their state right after
+// commit rev and records that as a new commit. Events are kept, and the
+// revision keeps increasing so compare-and-swap writers notice the change.
+// The restored state must load; otherwise nothing is changed.
+func (pdm *ProjectDataManager) RevertHistory(projectName, rev string) (*HistoryEntry, error) {
+	if !pdm.historyEnabled(projectName) {
+		return nil, fmt.Errorf("project '%s' has no git history (enable it with 'quickplan history --enable')", projectName)
+	}
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return nil, err
+	}
+	defer pdm.ReleaseLock(projectName)
+
+	out, err := runGit(projectPath, nil, "log", "-1", "--format=%H%x1f%an%x1f%aI%x1f%s", rev, "--")
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %s: %w", rev, err)
+	}
+	fields := strings.Split(out, "\x1f")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unknown revision %s", rev)
+	}
+	date, _ := time.Parse(time.RFC3339, fields[2])
+	target := &HistoryEntry{Rev: fields[0], Author: fields[1], Date: date, Subject: fields[3]}
+
+	// Commit anything pending first so the restore can be undone cleanly.
+	if err := pdm.commitHistory(projectName, nil, historyAuthor); err != nil {
+		return nil, err
+	}
+	current, err := pdm.currentRevision(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := append([]string{"restore", "--source=" + target.Rev, "--staged", "--worktree", "--", "."}, historyRestoreExcludes...)
+	if _, err := runGit(projectPath, nil, restore...); err != nil {
+		return nil, err
+	}
+	if err := pdm.resaveAfterRevert(projectName, current); err != nil {
+		runGit(projectPath, nil, "restore", "--source=HEAD", "--staged", "--worktree", "--", ".")
+		return nil, fmt.Errorf("restoring %s would leave the project unusable: %w", shortRev(target.Rev), err)
+	}
+
+	line := fmt.Sprintf("Revert to %s: %s", shortRev(target.Rev), target.Subject)
+	if err := pdm.commitHistory(projectName, []string{line}, "human"); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// currentRevision returns the stored revision of the project.
+func (pdm *ProjectDataManager) currentRevision(projectName string) (int64, error) {
+	if v11, err := pdm.LoadProjectV11(projectName); err == nil {
+		return v11.Revision, nil
+	} else if !os.IsNotExist(err) {
+		return 0, err
+	}
+	legacy, err := pdm.LoadProjectData(projectName)
+	if err != nil {
+		return 0, err
+	}
+	return legacy.Revision, nil
+}
+
+// resaveAfterRevert loads the restored files and writes them back past
+// revision, which validates them and keeps the revision monotonic.
+func (pdm *ProjectDataManager) resaveAfterRevert(projectName string, revision int64) error {
+	if v11, err := pdm.LoadProjectV11(projectName); err == nil {
+		if err := ValidateProjectV11(v11); err != nil {
+			return err
+		}
+		v11.Revision = revision
+		return pdm.writeProjectV11Locked(projectName, v11, false)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	legacy, err := pdm.LoadProjectData(projectName)
+	if err != nil {
+		return err
+	}
+	legacy.Revision = revision
+	return pdm.writeProjectDataLocked(projectName, legacy, false)
+}
+
+// shortRev abbreviates a commit hash for messages.
+func shortRev(rev string) string {
+	if len(rev) > 8 {
+		return rev[:8]
+	}
+	return rev
+}
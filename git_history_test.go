@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestGitHistory_CommitsSavesAndReverts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	if err := pdm.EnableHistory(projectName); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if err := pdm.UpdateTaskStatus(projectName, "t-1", "IN_PROGRESS", "worker-2"); err != nil {
+		t.Fatalf("status update failed: %v", err)
+	}
+	project, _ := pdm.LoadProjectV11(projectName)
+	project.Tasks = append(project.Tasks, TaskV11{ID: "t-3", Name: "three", Status: "TODO"})
+	if err := pdm.SaveProjectV11(projectName, project); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	entries, err := pdm.History(projectName, "", 0)
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 commits, got %+v", entries)
+	}
+	if entries[0].Subject != "t-3: added" {
+		t.Errorf("unexpected subject for a plain save: %q", entries[0].Subject)
+	}
+	if entries[1].Subject != "t-1: TODO -> IN_PROGRESS by worker-2" || entries[1].Author != "worker-2" {
+		t.Errorf("expected status commit authored by worker-2, got %+v", entries[1])
+	}
+
+	taskEntries, err := pdm.History(projectName, "t-1", 0)
+	if err != nil || len(taskEntries) != 1 {
+		t.Fatalf("expected one commit touching t-1, got %+v (%v)", taskEntries, err)
+	}
+
+	revision := project.Revision
+	if _, err := pdm.RevertHistory(projectName, entries[2].Rev); err != nil {
+		t.Fatalf("revert failed: %v", err)
+	}
+	reverted, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load after revert failed: %v", err)
+	}
+	if len(reverted.Tasks) != 2 || reverted.Tasks[0].Status != "TODO" {
+		t.Fatalf("expected the seeded state back, got %+v", reverted.Tasks)
+	}
+	if reverted.Revision <= revision {
+		t.Fatalf("expected revision to keep increasing, got %d after %d", reverted.Revision, revision)
+	}
+	entries, _ = pdm.History(projectName, "", 1)
+	if len(entries) != 1 || !strings.HasPrefix(entries[0].Subject, "Revert to ") {
+		t.Fatalf("expected a revert commit on top, got %+v", entries)
+	}
+}
+
+func TestGitHistory_CommitsSettingsAndArchiving(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	if err := pdm.EnableHistory(projectName); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if err := pdm.SetProjectSetting(projectName, "lease.ttl", "5m"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, err := pdm.ToggleArchived(projectName); err != nil {
+		t.Fatalf("archive failed: %v", err)
+	}
+
+	entries, err := pdm.History(projectName, "", 0)
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+	var subjects []string
+	for _, entry := range entries {
+		subjects = append(subjects, entry.Subject)
+	}
+	if got := strings.Join(subjects, "|"); got != "Archive the project|Change setting lease.ttl|Start git history" {
+		t.Fatalf("unexpected commits %q", got)
+	}
+}
+
+func TestDescribeTaskChanges_FromDiff(t *testing.T) {
+	before := []TaskView{{ID: "t-1", Status: "TODO"}, {ID: "t-2", Status: "TODO", Text: "old"}}
+	after := []TaskView{{ID: "t-1", Status: "DONE"}, {ID: "t-3", Status: "TODO"}}
+	lines, author := describeTaskChanges(before, after, nil)
+	want := []string{"t-1: TODO -> DONE", "t-3: added", "t-2: deleted"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") || author != historyAuthor {
+		t.Fatalf("unexpected description %v by %s", lines, author)
+	}
+}
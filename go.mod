@@ -8,6 +8,7 @@ require (
 	github.com/charmbracelet/huh v0.5.0
 	github.com/charmbracelet/lipgloss v0.11.0
 	github.com/daytonaio/daytona/libs/sdk-go v0.145.0
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/spf13/cobra v1.8.0
 	golang.org/x/crypto v0.48.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -40,7 +41,6 @@ require (
 	github.com/daytonaio/daytona/libs/toolbox-api-client-go v0.145.0 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/erikgeiser/coninput v0.0.0-20211004153227-1c3628e74d0f // indirect
-	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/google/uuid v1.6.0 // indirect
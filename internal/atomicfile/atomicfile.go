@@ -0,0 +1,67 @@
+// Package atomicfile replaces files so that a crash or a full disk leaves
+// either the old or the new content in place, never a truncated file.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// WriteFile durably replaces path with data.
+// The data is written to a temp file in the same directory, fsynced, renamed
+// over path and the directory is fsynced, so a crash or a full disk leaves
+// either the old or the new file in place, never a truncated one.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", path, err)
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	committed = true
+
+	return SyncDir(dir)
+}
+
+// SyncDir fsyncs a directory so a preceding rename survives a crash.
+func SyncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		// Directories cannot be opened for sync on Windows.
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %w", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+	return nil
+}
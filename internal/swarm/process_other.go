@@ -0,0 +1,17 @@
+//go:build !unix
+
+package swarm
+
+import "os/exec"
+
+// Without process groups, only the shell itself can be stopped.
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -1,22 +1,52 @@
 package swarm
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/daytonaio/daytona/libs/sdk-go/pkg/daytona"
 	"github.com/daytonaio/daytona/libs/sdk-go/pkg/types"
 )
 
-// Runner defines the interface for isolated task execution environments
+// Runner defines the interface for isolated task execution environments.
+// Execute stops the command when ctx is done and returns an error wrapping
+// context.Cause(ctx).
 type Runner interface {
-	Setup(task *TaskView) error
-	Execute(command string, task *TaskView) (string, error)
-	Teardown(task *TaskView) error
+	Setup(ctx context.Context, task *TaskView) error
+	Execute(ctx context.Context, command string, task *TaskView) (string, error)
+	Teardown(ctx context.Context, task *TaskView) error
 	SetLogger(logger *EventLogger)
+	// SetOutput copies the command's stdout and stderr to the given
+	// writers as it runs, in addition to returning the combined output.
+	SetOutput(stdout, stderr io.Writer)
+}
+
+// DefaultKillGrace is how long a stopped command has to exit after SIGTERM
+// before its process group is killed.
+const DefaultKillGrace = 5 * time.Second
+
+// ExitError reports a command that ran but exited non-zero in a remote
+// environment. Like *exec.ExitError, it exposes the code through ExitCode.
+type ExitError struct {
+	Runner string
+	Code   int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s execution failed: exit code %d", e.Runner, e.Code)
+}
+
+// ExitCode returns the command's exit code.
+func (e *ExitError) ExitCode() int {
+	return e.Code
 }
 
 // LocalRunner executes tasks on the local machine
@@ -25,13 +55,25 @@ type LocalRunner struct {
 	AgentID   string
 	Workspace string
 	Logger    *EventLogger
+	// DisableSandbox runs commands without namespace isolation, like
+	// QUICKPLAN_DISABLE_LOCAL_SANDBOX=1.
+	DisableSandbox bool
+	// KillGrace overrides DefaultKillGrace.
+	KillGrace time.Duration
+	Stdout    io.Writer
+	Stderr    io.Writer
 }
 
 func (r *LocalRunner) SetLogger(logger *EventLogger) {
 	r.Logger = logger
 }
 
-func (r *LocalRunner) Setup(task *TaskView) error {
+func (r *LocalRunner) SetOutput(stdout, stderr io.Writer) {
+	r.Stdout = stdout
+	r.Stderr = stderr
+}
+
+func (r *LocalRunner) Setup(ctx context.Context, task *TaskView) error {
 	taskID := "default"
 	if task != nil && task.ID != "" {
 		taskID = task.ID
@@ -53,9 +95,9 @@ func (r *LocalRunner) Setup(task *TaskView) error {
 	return nil
 }
 
-func (r *LocalRunner) Execute(command string, task *TaskView) (string, error) {
+func (r *LocalRunner) Execute(ctx context.Context, command string, task *TaskView) (string, error) {
 	if r.Workspace == "" {
-		if err := r.Setup(task); err != nil {
+		if err := r.Setup(ctx, task); err != nil {
 			return "", err
 		}
 	}
@@ -73,9 +115,11 @@ func (r *LocalRunner) Execute(command string, task *TaskView) (string, error) {
 
 	cmd := exec.Command("sh", "-lc", command)
 	cmd.Dir = r.Workspace
-	applyLocalSandbox(cmd, r.Workspace)
+	if !r.DisableSandbox {
+		applyLocalSandbox(cmd, r.Workspace)
+	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := r.run(ctx, cmd)
 	if err != nil {
 		if r.Logger != nil {
 			r.Logger.Log("ERROR", "LocalRunner", "Command execution failed", map[string]interface{}{
@@ -88,7 +132,52 @@ func (r *LocalRunner) Execute(command string, task *TaskView) (string, error) {
 	return string(output), nil
 }
 
-func (r *LocalRunner) Teardown(task *TaskView) error {
+// run runs cmd in its own process group and returns its combined output.
+// When ctx is done first, the group gets SIGTERM, then SIGKILL after the
+// grace period.
+func (r *LocalRunner) run(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	grace := r.KillGrace
+	if grace <= 0 {
+		grace = DefaultKillGrace
+	}
+
+	output := &lockedBuffer{}
+	cmd.Stdout = teeOutput(output, r.Stdout)
+	cmd.Stderr = teeOutput(output, r.Stderr)
+	// Stop waiting for stray descendants that escaped the group and still
+	// hold the output pipe.
+	cmd.WaitDelay = grace
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	select {
+	case err := <-exited:
+		if errors.Is(err, exec.ErrWaitDelay) {
+			// The shell succeeded; only a background child lingered.
+			err = nil
+		}
+		return output.Bytes(), err
+	case <-ctx.Done():
+	}
+
+	_ = terminateProcessGroup(cmd)
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-exited:
+	case <-timer.C:
+		_ = killProcessGroup(cmd)
+		<-exited
+	}
+	return output.Bytes(), fmt.Errorf("command stopped: %w", context.Cause(ctx))
+}
+
+func (r *LocalRunner) Teardown(ctx context.Context, task *TaskView) error {
 	if r.Workspace == "" {
 		return nil
 	}
@@ -100,6 +189,32 @@ func (r *LocalRunner) Teardown(task *TaskView) error {
 	return os.RemoveAll(r.Workspace)
 }
 
+// lockedBuffer collects output written from the stdout and stderr copying
+// goroutines at once.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
+
+func teeOutput(combined *lockedBuffer, w io.Writer) io.Writer {
+	if w == nil {
+		return combined
+	}
+	return io.MultiWriter(combined, w)
+}
+
 // DaytonaRunner executes tasks in ephemeral sandboxes using Daytona
 type DaytonaRunner struct {
 	Project string
@@ -107,13 +222,20 @@ type DaytonaRunner struct {
 	Client  *daytona.Client
 	Sandbox *daytona.Sandbox
 	Logger  *EventLogger
+	Stdout  io.Writer
 }
 
 func (r *DaytonaRunner) SetLogger(logger *EventLogger) {
 	r.Logger = logger
 }
 
-func (r *DaytonaRunner) Setup(task *TaskView) error {
+// SetOutput receives the output once the command finishes, as the sandbox
+// API does not stream it; it arrives as stdout.
+func (r *DaytonaRunner) SetOutput(stdout, stderr io.Writer) {
+	r.Stdout = stdout
+}
+
+func (r *DaytonaRunner) Setup(ctx context.Context, task *TaskView) error {
 	client, err := daytona.NewClient()
 	if err != nil {
 		return fmt.Errorf("Daytona server unreachable or unauthenticated: %w", err)
@@ -140,7 +262,7 @@ func (r *DaytonaRunner) Setup(task *TaskView) error {
 		Image:             image,
 	}
 
-	sandbox, err := client.Create(context.Background(), params)
+	sandbox, err := client.Create(ctx, params)
 	if err != nil {
 		return fmt.Errorf("Daytona workspace creation failed: %w", err)
 	}
@@ -150,9 +272,9 @@ func (r *DaytonaRunner) Setup(task *TaskView) error {
 	return nil
 }
 
-func (r *DaytonaRunner) Execute(command string, task *TaskView) (string, error) {
+func (r *DaytonaRunner) Execute(ctx context.Context, command string, task *TaskView) (string, error) {
 	if r.Sandbox == nil {
-		if err := r.Setup(task); err != nil {
+		if err := r.Setup(ctx, task); err != nil {
 			return "", err
 		}
 	}
@@ -167,18 +289,26 @@ func (r *DaytonaRunner) Execute(command string, task *TaskView) (string, error)
 		r.Logger.Log("INFO", "DaytonaRunner", msg, nil)
 	}
 
-	response, err := r.Sandbox.Process.ExecuteCommand(context.Background(), command)
+	response, err := r.Sandbox.Process.ExecuteCommand(ctx, command)
+	if ctx.Err() != nil {
+		// The command may still be running remotely; Teardown removes
+		// the sandbox along with it.
+		return "", fmt.Errorf("command stopped: %w", context.Cause(ctx))
+	}
 	if err != nil {
 		return "", fmt.Errorf("Daytona execution failed: %w", err)
 	}
+	if r.Stdout != nil {
+		_, _ = io.WriteString(r.Stdout, response.Result)
+	}
 	if response.ExitCode != 0 {
-		return response.Result, fmt.Errorf("Daytona execution failed: exit code %d", response.ExitCode)
+		return response.Result, &ExitError{Runner: "Daytona", Code: response.ExitCode}
 	}
 
 	return response.Result, nil
 }
 
-func (r *DaytonaRunner) Teardown(task *TaskView) error {
+func (r *DaytonaRunner) Teardown(ctx context.Context, task *TaskView) error {
 	if r.Sandbox == nil {
 		return nil
 	}
@@ -189,7 +319,7 @@ func (r *DaytonaRunner) Teardown(task *TaskView) error {
 		r.Logger.Log("INFO", "DaytonaRunner", fmt.Sprintf("Destroying workspace %s", workspaceName), nil)
 	}
 
-	if err := r.Sandbox.Delete(context.Background()); err != nil {
+	if err := r.Sandbox.Delete(ctx); err != nil {
 		return fmt.Errorf("Daytona workspace deletion failed: %w", err)
 	}
 	r.Sandbox = nil
@@ -197,6 +327,10 @@ func (r *DaytonaRunner) Teardown(task *TaskView) error {
 	return nil
 }
 
+// RunnerProviders lists the environment providers GetRunner knows; any
+// other value runs locally.
+var RunnerProviders = []string{"local", "daytona"}
+
 // GetRunner returns the appropriate runner based on task behavior
 func GetRunner(project, agentID string, task *TaskView) Runner {
 	provider := task.Behavior.Environment.Provider
@@ -1,8 +1,14 @@
 package swarm
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLocalRunnerExecute_SupportsShellOperators(t *testing.T) {
@@ -11,7 +17,7 @@ func TestLocalRunnerExecute_SupportsShellOperators(t *testing.T) {
 	runner := &LocalRunner{Project: "p", AgentID: "a"}
 	task := &TaskView{ID: "t-1"}
 
-	out, err := runner.Execute("echo one && echo two", task)
+	out, err := runner.Execute(context.Background(), "echo one && echo two", task)
 	if err != nil {
 		t.Fatalf("execute failed: %v", err)
 	}
@@ -24,7 +30,54 @@ func TestLocalRunnerExecute_EmptyCommandFails(t *testing.T) {
 	runner := &LocalRunner{Project: "p", AgentID: "a"}
 	task := &TaskView{ID: "t-1"}
 
-	if _, err := runner.Execute("", task); err == nil {
+	if _, err := runner.Execute(context.Background(), "", task); err == nil {
 		t.Fatal("expected empty command error")
 	}
 }
+
+// TestLocalRunnerExecute_StopsProcessGroup starts a child that ignores
+// SIGTERM and expects both it and the shell to be gone once ctx ends.
+func TestLocalRunnerExecute_StopsProcessGroup(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	runner := &LocalRunner{Project: "p", AgentID: "a", KillGrace: 200 * time.Millisecond}
+	task := &TaskView{ID: "t-stop"}
+	defer runner.Teardown(context.Background(), task)
+
+	errStop := errors.New("stop requested")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(300*time.Millisecond, func() { cancel(errStop) })
+
+	start := time.Now()
+	_, err := runner.Execute(ctx, "sh -c 'trap \"\" TERM; echo $$ > "+pidFile+"; sleep 30' & wait", task)
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected the stop cause, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("command outlived its context by %s", elapsed)
+	}
+
+	raw, readErr := os.ReadFile(pidFile)
+	if readErr != nil {
+		t.Fatalf("child never started: %v", readErr)
+	}
+	pid := strings.TrimSpace(string(raw))
+	deadline := time.Now().Add(2 * time.Second)
+	for processRunning(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("child %s survived the group kill", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// processRunning reports whether pid exists and is not a zombie.
+func processRunning(pid string) bool {
+	stat, err := os.ReadFile("/proc/" + pid + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
@@ -1,5 +1,7 @@
 package swarm
 
+import "time"
+
 // EnvironmentConfig defines the execution environment for an agent.
 type EnvironmentConfig struct {
 	Provider string `yaml:"provider,omitempty"` // "local", "daytona"
@@ -8,13 +10,16 @@ type EnvironmentConfig struct {
 
 // AgentBehavior defines the "personality" and "loop rules" for an AI agent.
 type AgentBehavior struct {
-	Role         string            `yaml:"role,omitempty"`          // e.g., "Senior Go Architect"
-	LifeCycle    string            `yaml:"lifecycle,omitempty"`     // e.g., "Atomic" (one-shot) or "Infinite" (loop)
-	LoopInterval string            `yaml:"loop_interval,omitempty"` // e.g., "30s"
-	Strategy     string            `yaml:"strategy,omitempty"`      // e.g., "TDD" or "Fast Prototype"
-	Command      string            `yaml:"command,omitempty"`       // shell command for task execution
-	Plugin       string            `yaml:"plugin,omitempty"`        // plugin executable name
-	Environment  EnvironmentConfig `yaml:"environment,omitempty"`
+	Role          string            `yaml:"role,omitempty"`            // e.g., "Senior Go Architect"
+	LifeCycle     string            `yaml:"lifecycle,omitempty"`       // e.g., "Atomic" (one-shot) or "Infinite" (loop)
+	LoopInterval  string            `yaml:"loop_interval,omitempty"`   // e.g., "30s"
+	MaxIterations int               `yaml:"max_iterations,omitempty"`  // Infinite: stop after this many runs
+	UntilExitCode *int              `yaml:"until_exit_code,omitempty"` // Infinite: stop once a run exits with this code
+	Timeout       string            `yaml:"timeout,omitempty"`         // e.g., "10m"; a longer run is stopped and fails
+	Strategy      string            `yaml:"strategy,omitempty"`        // e.g., "TDD" or "Fast Prototype"
+	Command       string            `yaml:"command,omitempty"`         // shell command for task execution
+	Plugin        string            `yaml:"plugin,omitempty"`          // plugin executable name
+	Environment   EnvironmentConfig `yaml:"environment,omitempty"`
 }
 
 // TaskView is a unified view of a task regardless of schema version.
@@ -29,4 +34,19 @@ type TaskView struct {
 	RequiresFiles []string
 	Behavior      AgentBehavior
 	IsV11         bool
+	UpdatedAt     time.Time // last change; legacy tasks report their creation time
+
+	// Lease of the worker holding the task while IN_PROGRESS
+	LeaseOwner     string
+	LeaseExpiresAt *time.Time
+
+	// Schema 1.2 and 1.3 metadata; empty for older projects
+	Priority    string
+	Due         *time.Time
+	Start       *time.Time
+	Labels      []string
+	Estimate    string
+	Description string
+	Parent      string
+	Subtasks    []string // IDs of tasks whose parent is this one
 }
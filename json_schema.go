@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/trstoyan/quickplan/internal/swarm"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema kinds accepted by `quickplan schema export`.
+const (
+	schemaKindV11    = "v1.1"
+	schemaKindV12    = "v1.2"
+	schemaKindV13    = "v1.3"
+	schemaKindLegacy = "legacy"
+)
+
+// estimatePattern mirrors parseEstimate for editors.
+const estimatePattern = `^((\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+|\d+(\.\d+)?[dw])$`
+
+// schemaGenerator derives a JSON Schema (draft-07) from the Go types the
+// loaders decode into, so the published schema cannot drift from them.
+// Struct types become definitions; overlays add the constraints that live
+// in validation code rather than in the types, keyed "Type.yaml_key".
+type schemaGenerator struct {
+	definitions map[string]interface{}
+	overlays    map[string]map[string]interface{}
+	required    map[string][]string
+	omit        map[string]bool
+}
+
+// projectJSONSchema builds the JSON Schema for one file kind: v1.1, v1.2 or
+// v1.3 project.yaml, or legacy tasks.yaml.
+func projectJSONSchema(kind string) (map[string]interface{}, error) {
+	g := &schemaGenerator{
+		definitions: make(map[string]interface{}),
+		overlays: map[string]map[string]interface{}{
+			"ProjectV11.layout":          {"enum": projectLayouts},
+			"TaskV11.status":             {"enum": taskStatuses},
+			"TaskV11.priority":           {"enum": taskPriorities},
+			"TaskV11.estimate":           {"pattern": estimatePattern},
+			"Task.status":                {"enum": taskStatuses},
+			"EnvironmentConfig.provider": {"enum": swarm.RunnerProviders},
+			"AgentBehavior.lifecycle":    {"enum": []string{"Atomic", "Infinite"}},
+			"RetryPolicy.backoff":        {"enum": []string{"fixed", "linear", "exponential"}},
+		},
+		required: map[string][]string{
+			"ProjectV11":  {"schema_version", "tasks"},
+			"TaskV11":     {"id", "status"},
+			"Recurrence":  {"schedule"},
+			"ProjectData": {"tasks"},
+			"Task":        {"id", "text"},
+		},
+		omit: make(map[string]bool),
+	}
+
+	var root reflect.Type
+	var title string
+	var newerKeys []string
+	switch kind {
+	case schemaKindV11, schemaKindV12, schemaKindV13:
+		root = reflect.TypeOf(ProjectV11{})
+		version := strings.TrimPrefix(kind, "v")
+		title = fmt.Sprintf("quickplan project.yaml (schema %s)", version)
+		g.overlays["ProjectV11.schema_version"] = map[string]interface{}{"enum": []string{version}}
+		newerKeys = taskFieldKeysAfter(version)
+		for _, key := range newerKeys {
+			g.omit["TaskV11."+key] = true
+		}
+	case schemaKindLegacy:
+		root = reflect.TypeOf(ProjectData{})
+		title = "quickplan tasks.yaml (legacy)"
+	default:
+		return nil, fmt.Errorf("unknown schema %q (expected %s, %s, %s or %s)", kind, schemaKindV11, schemaKindV12, schemaKindV13, schemaKindLegacy)
+	}
+
+	ref := g.schemaFor(root)
+	if len(newerKeys) > 0 {
+		// Reject keys from later schemas outright instead of merely not
+		// describing them.
+		task := g.definitions["TaskV11"].(map[string]interface{})
+		task["propertyNames"] = map[string]interface{}{"not": map[string]interface{}{"enum": newerKeys}}
+	}
+
+	return map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       title,
+		"allOf":       []interface{}{ref},
+		"definitions": g.definitions,
+	}, nil
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaFor(t.Elem())
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.definitions[name]; !ok {
+			g.definitions[name] = map[string]interface{}{} // placeholder for recursive types
+			g.definitions[name] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + name}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" || !field.IsExported() || g.omit[t.Name()+"."+key] {
+			continue
+		}
+		property := g.schemaFor(field.Type)
+		for k, v := range g.overlays[t.Name()+"."+key] {
+			property[k] = v
+		}
+		properties[key] = property
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if required := g.required[t.Name()]; len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// schemaDiagnostics validates YAML source against the JSON Schema for kind
+// and returns one positioned diagnostic per violation. Unknown kinds and
+// unparseable YAML yield nothing; other checks already report those.
+func schemaDiagnostics(file string, data []byte, kind string) []Diagnostic {
+	schema, err := projectJSONSchema(kind)
+	if err != nil {
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+
+	v := &schemaValidator{file: file, definitions: schema["definitions"].(map[string]interface{})}
+	v.validate(doc.Content[0], schema, "")
+	return v.diagnostics
+}
+
+// schemaValidator checks a yaml.Node tree against the subset of JSON Schema
+// that schemaGenerator emits.
+type schemaValidator struct {
+	file        string
+	definitions map[string]interface{}
+	diagnostics []Diagnostic
+}
+
+func (v *schemaValidator) report(node *yaml.Node, path, format string, args ...interface{}) {
+	if path == "" {
+		path = "(root)"
+	}
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		File:     v.file,
+		Line:     node.Line,
+		Column:   node.Column,
+		Severity: SeverityError,
+		Code:     "json-schema",
+		Message:  path + ": " + fmt.Sprintf(format, args...),
+	})
+}
+
+// matches reports whether node satisfies schema without recording anything.
+func (v *schemaValidator) matches(node *yaml.Node, schema map[string]interface{}) bool {
+	probe := &schemaValidator{file: v.file, definitions: v.definitions}
+	probe.validate(node, schema, "")
+	return len(probe.diagnostics) == 0
+}
+
+func (v *schemaValidator) validate(node *yaml.Node, schema map[string]interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		if def, ok := v.definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]interface{}); ok {
+			v.validate(node, def, path)
+		}
+		return
+	}
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if sub, ok := sub.(map[string]interface{}); ok {
+				v.validate(node, sub, path)
+			}
+		}
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// YAML null decodes to the zero value, which the loaders accept.
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	if typ, ok := schema["type"].(string); ok && !nodeHasJSONType(node, typ) {
+		v.report(node, path, "expected %s, found %s", typ, describeNode(node))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]string); ok && node.Kind == yaml.ScalarNode {
+		if !containsString(enum, node.Value) {
+			v.report(node, path, "%q is not one of %s", node.Value, strings.Join(enum, ", "))
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok && node.Kind == yaml.ScalarNode {
+		if !regexp.MustCompile(pattern).MatchString(node.Value) {
+			v.report(node, path, "%q does not match %s", node.Value, pattern)
+		}
+	}
+	if schema["format"] == "date-time" && node.Kind == yaml.ScalarNode && node.Tag != "!!timestamp" {
+		if _, err := time.Parse(time.RFC3339, node.Value); err != nil {
+			v.report(node, path, "%q is not an RFC3339 date-time", node.Value)
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok && v.matches(node, not) {
+		v.report(node, path, "%q is not allowed here", node.Value)
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range node.Content {
+				v.validate(item, items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case yaml.MappingNode:
+		v.validateMapping(node, schema, path)
+	}
+}
+
+func (v *schemaValidator) validateMapping(node *yaml.Node, schema map[string]interface{}, path string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, _ := schema["additionalProperties"].(map[string]interface{})
+	names, _ := schema["propertyNames"].(map[string]interface{})
+
+	present := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+		present[key] = true
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+
+		if names != nil {
+			v.validate(keyNode, names, childPath)
+		}
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			v.validate(valueNode, property, childPath)
+		} else if additional != nil {
+			v.validate(valueNode, additional, childPath)
+		}
+	}
+
+	if required, ok := schema["required"].([]string); ok {
+		var missing []string
+		for _, key := range required {
+			if !present[key] {
+				missing = append(missing, key)
+			}
+		}
+		sort.Strings(missing)
+		for _, key := range missing {
+			v.report(node, path, "missing required key %q", key)
+		}
+	}
+}
+
+// nodeHasJSONType reports whether a YAML node can decode as the JSON type.
+func nodeHasJSONType(node *yaml.Node, typ string) bool {
+	switch typ {
+	case "object":
+		return node.Kind == yaml.MappingNode
+	case "array":
+		return node.Kind == yaml.SequenceNode
+	}
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+	switch typ {
+	case "string":
+		// Unquoted scalars such as 1.1 or 2026-03-01 still decode into strings.
+		return true
+	case "integer":
+		return node.Tag == "!!int"
+	case "number":
+		return node.Tag == "!!int" || node.Tag == "!!float"
+	case "boolean":
+		return node.Tag == "!!bool"
+	}
+	return true
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDiagnostics appends extra diagnostics on lines base does not already
+// report, so one mistake is not flagged twice. yaml.v3 type errors carry no
+// column, hence the match on line alone.
+func mergeDiagnostics(base, extra []Diagnostic) []Diagnostic {
+	seen := make(map[int]bool, len(base))
+	for _, d := range base {
+		seen[d.Line] = true
+	}
+	for _, d := range extra {
+		if !seen[d.Line] {
+			base = append(base, d)
+		}
+	}
+	return base
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProjectJSONSchema_ExportsConstraints(t *testing.T) {
+	schema, err := projectJSONSchema(schemaKindV11)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	out, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("schema does not marshal: %v", err)
+	}
+
+	definitions := schema["definitions"].(map[string]interface{})
+	task := definitions["TaskV11"].(map[string]interface{})
+	properties := task["properties"].(map[string]interface{})
+	if _, ok := properties["priority"]; ok {
+		t.Fatal("v1.1 schema must not describe 1.2 task fields")
+	}
+	status := properties["status"].(map[string]interface{})
+	if enum := status["enum"].([]string); len(enum) != len(taskStatuses) {
+		t.Fatalf("expected status enum from taskStatuses, got %v", enum)
+	}
+	for _, want := range []string{`"daytona"`, `"RetryPolicy"`, `"date-time"`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("exported schema missing %s", want)
+		}
+	}
+
+	if _, err := projectJSONSchema("v9"); err == nil {
+		t.Fatal("expected error for unknown schema kind")
+	}
+}
+
+func TestSchemaDiagnostics(t *testing.T) {
+	source := `schema_version: "1.2"
+tasks:
+  - id: t-1
+    status: TODO
+    priority: high
+    attempts: many
+    behavior:
+      environment:
+        provider: aws
+  - name: no id
+    status: TODO
+`
+	diagnostics := schemaDiagnostics("project.yaml", []byte(source), schemaKindV12)
+
+	lines := make(map[int]string)
+	for _, d := range diagnostics {
+		lines[d.Line] = d.Message
+	}
+	if len(diagnostics) != 3 {
+		t.Fatalf("expected 3 schema diagnostics, got %v", diagnostics)
+	}
+	if !strings.Contains(lines[6], "expected integer") {
+		t.Errorf("expected integer error on line 6, got %q", lines[6])
+	}
+	if !strings.Contains(lines[9], "provider") {
+		t.Errorf("expected provider enum error on line 9, got %q", lines[9])
+	}
+	if !strings.Contains(lines[10], `missing required key "id"`) {
+		t.Errorf("expected missing id on line 10, got %q", lines[10])
+	}
+
+	v11 := strings.Replace(source, `"1.2"`, `"1.1"`, 1)
+	found := false
+	for _, d := range schemaDiagnostics("project.yaml", []byte(v11), schemaKindV11) {
+		if d.Line == 5 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected v1.1 schema to reject the priority key")
+	}
+
+	withParent := "schema_version: \"1.2\"\ntasks:\n  - id: t-1\n    status: TODO\n    parent: t-0\n"
+	if diagnostics := schemaDiagnostics("project.yaml", []byte(withParent), schemaKindV12); len(diagnostics) != 1 || diagnostics[0].Line != 5 {
+		t.Errorf("expected v1.2 schema to reject the parent key, got %v", diagnostics)
+	}
+	withParent = strings.Replace(withParent, `"1.2"`, `"1.3"`, 1)
+	if diagnostics := schemaDiagnostics("project.yaml", []byte(withParent), schemaKindV13); len(diagnostics) != 0 {
+		t.Errorf("expected v1.3 schema to accept the parent key, got %v", diagnostics)
+	}
+
+	legacy := "tasks:\n  - id: one\n    text: x\n"
+	if diagnostics := schemaDiagnostics("tasks.yaml", []byte(legacy), schemaKindLegacy); len(diagnostics) != 1 {
+		t.Fatalf("expected legacy id type error, got %v", diagnostics)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// leaseRenewals is how many times a worker renews its lease per lease.ttl,
+// so one slow renewal does not cost it the task.
+const leaseRenewals = 3
+
+// workerIDPattern matches the agent IDs swarm and daemon workers claim tasks
+// under, as opposed to the people or plugins a task can be assigned to.
+var workerIDPattern = regexp.MustCompile(`^(daemon-)?worker-[0-9]+$`)
+
+func isWorkerID(agentID string) bool {
+	return workerIDPattern.MatchString(agentID)
+}
+
+// ownershipFields returns the lease and assignee fields of taskID in either
+// schema. ok is false when there is no such task.
+func (tx *ProjectTx) ownershipFields(taskID string) (owner *string, expiresAt **time.Time, assignedTo *string, ok bool) {
+	if tx.v11 != nil {
+		for i := range tx.v11.Tasks {
+			if task := &tx.v11.Tasks[i]; task.ID == taskID {
+				return &task.LeaseOwner, &task.LeaseExpiresAt, &task.AssignedTo, true
+			}
+		}
+		return nil, nil, nil, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(taskID, "t-"))
+	if err != nil {
+		return nil, nil, nil, false
+	}
+	for i := range tx.legacy.Tasks {
+		if task := &tx.legacy.Tasks[i]; task.ID == id {
+			return &task.LeaseOwner, &task.LeaseExpiresAt, &task.AssignedTo, true
+		}
+	}
+	return nil, nil, nil, false
+}
+
+// setLease gives owner the lease on taskID until expiresAt.
+func (tx *ProjectTx) setLease(taskID, owner string, expiresAt time.Time) {
+	leaseOwner, leaseExpiresAt, _, ok := tx.ownershipFields(taskID)
+	if !ok {
+		return
+	}
+	*leaseOwner = owner
+	*leaseExpiresAt = &expiresAt
+	tx.modified = true
+}
+
+// setStatusKeepingAssignee is SetStatus for bookkeeping actors such as the
+// retry scheduler, which move a task without taking it over.
+func (tx *ProjectTx) setStatusKeepingAssignee(taskID, status, actor string) error {
+	_, _, assignedTo, ok := tx.ownershipFields(taskID)
+	if !ok {
+		return fmt.Errorf("task %s not found in project %s", taskID, tx.projectName)
+	}
+	assignee := *assignedTo
+	if _, err := tx.SetStatus(taskID, status, actor); err != nil {
+		return err
+	}
+	*assignedTo = assignee
+	return nil
+}
+
+// ClaimTask moves taskID to IN_PROGRESS for agentID with a lease of
+// lease.ttl, which the agent renews while it runs the task.
+func (pdm *ProjectDataManager) ClaimTask(projectName, taskID, agentID string) error {
+	ttl := pdm.SettingDuration(projectName, "lease.ttl")
+	return pdm.Update(projectName, func(tx *ProjectTx) error {
+		return tx.claim(taskID, agentID, ttl)
+	})
+}
+
+// claim moves taskID to IN_PROGRESS for agentID with a lease of ttl, or no
+// lease when ttl is zero.
+func (tx *ProjectTx) claim(taskID, agentID string, ttl time.Duration) error {
+	if _, err := tx.SetStatus(taskID, "IN_PROGRESS", agentID); err != nil {
+		return err
+	}
+	if ttl > 0 {
+		tx.setLease(taskID, agentID, time.Now().Add(ttl))
+	}
+	return nil
+}
+
+// RenewLease extends agentID's lease on taskID by lease.ttl. It returns
+// false when the agent no longer holds the task: the task left IN_PROGRESS,
+// was deleted, or was reaped and claimed by another worker.
+func (pdm *ProjectDataManager) RenewLease(projectName, taskID, agentID string) (bool, error) {
+	ttl := pdm.SettingDuration(projectName, "lease.ttl")
+	held := false
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		// Renewals are bookkeeping, not changes worth a history commit.
+		tx.quiet = true
+		for _, view := range tx.Views() {
+			if view.ID != taskID {
+				continue
+			}
+			if canonicalStatus(view.Status) != "IN_PROGRESS" || (view.LeaseOwner != "" && view.LeaseOwner != agentID) {
+				return nil
+			}
+			held = true
+			if ttl > 0 {
+				tx.setLease(taskID, agentID, time.Now().Add(ttl))
+			}
+			return nil
+		}
+		return nil
+	})
+	return held, err
+}
+
+// ReleaseTask hands taskID back to the queue when agentID stops working on
+// it without finishing, as when a shutdown ends an Infinite loop: the task
+// returns to PENDING without a lease, the worker's assignment is dropped and
+// a TASK_RELEASED event records why. It returns false when agentID no longer
+// holds the task.
+func (pdm *ProjectDataManager) ReleaseTask(projectName, taskID, agentID, reason string) (bool, error) {
+	var pulse *readinessPulse
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		for _, view := range tx.Views() {
+			if view.ID != taskID {
+				continue
+			}
+			if canonicalStatus(view.Status) != "IN_PROGRESS" || (view.LeaseOwner != "" && view.LeaseOwner != agentID) {
+				return nil
+			}
+			// IN_PROGRESS -> PENDING is not a transition people may make,
+			// so the status is written directly.
+			prevStatus, err := tx.applyStatus(taskID, "PENDING", "")
+			if err != nil {
+				return err
+			}
+			if _, _, assignedTo, ok := tx.ownershipFields(taskID); ok && *assignedTo == agentID {
+				*assignedTo = ""
+			}
+			message := fmt.Sprintf("Released by %s: %s", agentID, reason)
+			tx.AppendEvent(Event{
+				Type:       "TASK_RELEASED",
+				Actor:      agentID,
+				TaskID:     taskID,
+				PrevStatus: prevStatus,
+				NextStatus: "PENDING",
+				Message:    message,
+			})
+			pulse = &readinessPulse{taskID, "PENDING", prevStatus, "TASK_RELEASED", message}
+			return nil
+		}
+		return nil
+	})
+	if err != nil || pulse == nil {
+		return false, err
+	}
+
+	SendPulseWithMessage(projectName, agentID, pulse.taskID, pulse.status, pulse.prevStatus, pulse.eventType, pulse.message)
+	return true, nil
+}
+
+// ReapExpiredLeases fails every IN_PROGRESS task whose lease expired before
+// now, because its worker died or hung without renewing it, recording a
+// TASK_LEASE_EXPIRED event for each. The dead worker's assignment is dropped
+// so any worker can take the task again, and the retry policy is applied
+// with the reason "lease expired".
+func (pdm *ProjectDataManager) ReapExpiredLeases(projectName, actor string, now time.Time) (int, error) {
+	var pulses []readinessPulse
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		for _, view := range tx.Views() {
+			if canonicalStatus(view.Status) != "IN_PROGRESS" || view.LeaseExpiresAt == nil || !view.LeaseExpiresAt.Before(now) {
+				continue
+			}
+			message := fmt.Sprintf("Lease held by %s expired at %s", view.LeaseOwner, view.LeaseExpiresAt.Format(time.RFC3339))
+			tx.AppendEvent(Event{
+				Timestamp: now,
+				Type:      "TASK_LEASE_EXPIRED",
+				Actor:     actor,
+				TaskID:    view.ID,
+				Message:   message,
+			})
+			if _, err := tx.SetStatus(view.ID, "FAILED", actor); err != nil {
+				return fmt.Errorf("task %s: %w", view.ID, err)
+			}
+			if _, _, assignedTo, ok := tx.ownershipFields(view.ID); ok {
+				*assignedTo = view.AssignedTo
+				if view.AssignedTo == view.LeaseOwner {
+					*assignedTo = ""
+				}
+			}
+			pulses = append(pulses, readinessPulse{view.ID, "FAILED", "IN_PROGRESS", "TASK_LEASE_EXPIRED", message})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, p := range pulses {
+		SendPulseWithMessage(projectName, actor, p.taskID, p.status, p.prevStatus, p.eventType, p.message)
+		if _, err := pdm.ScheduleRetryIfAllowed(projectName, p.taskID, actor, "lease expired"); err != nil {
+			return len(pulses), err
+		}
+	}
+	return len(pulses), nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newLeaseTestProject saves a v1.1 project holding tasks.
+func newLeaseTestProject(t *testing.T, tasks ...TaskV11) (*ProjectDataManager, string, func()) {
+	t.Helper()
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	for i := range tasks {
+		tasks[i].UpdatedAt = time.Now()
+	}
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks:         tasks,
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		cleanup()
+		t.Fatalf("save failed: %v", err)
+	}
+	return pdm, projectName, cleanup
+}
+
+func loadLeaseTask(t *testing.T, pdm *ProjectDataManager, projectName, taskID string) TaskV11 {
+	t.Helper()
+	v11, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	for _, task := range v11.Tasks {
+		if task.ID == taskID {
+			return task
+		}
+	}
+	t.Fatalf("task %s not found", taskID)
+	return TaskV11{}
+}
+
+func TestClaimNextRunnableTask_TakesLease(t *testing.T) {
+	pdm, projectName, cleanup := newLeaseTestProject(t, TaskV11{ID: "t-1", Name: "work", Status: "TODO"})
+	defer cleanup()
+
+	before := time.Now()
+	claimed, err := pdm.ClaimNextRunnableTask(projectName, "worker-1")
+	if err != nil || claimed == nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	task := loadLeaseTask(t, pdm, projectName, "t-1")
+	if task.LeaseOwner != "worker-1" || task.LeaseExpiresAt == nil || task.LeaseExpiresAt.Before(before.Add(2*time.Minute)) {
+		t.Fatalf("expected a 2m lease for worker-1, got %q until %v", task.LeaseOwner, task.LeaseExpiresAt)
+	}
+
+	if err := pdm.UpdateTaskStatus(projectName, "t-1", "DONE", "worker-1"); err != nil {
+		t.Fatalf("DONE failed: %v", err)
+	}
+	if task := loadLeaseTask(t, pdm, projectName, "t-1"); task.LeaseOwner != "" || task.LeaseExpiresAt != nil {
+		t.Fatalf("expected leaving IN_PROGRESS to drop the lease, got %q until %v", task.LeaseOwner, task.LeaseExpiresAt)
+	}
+}
+
+func TestReapExpiredLeases_FailsAndRetries(t *testing.T) {
+	pdm, projectName, cleanup := newLeaseTestProject(t,
+		TaskV11{ID: "t-1", Name: "orphaned", Status: "TODO", RetryPolicy: &RetryPolicy{MaxAttempts: 3, Backoff: "fixed"}},
+		TaskV11{ID: "t-2", Name: "alive", Status: "TODO"},
+	)
+	defer cleanup()
+	for _, id := range []string{"t-1", "t-2"} {
+		if err := pdm.ClaimTask(projectName, id, "worker-"+id); err != nil {
+			t.Fatalf("claim %s failed: %v", id, err)
+		}
+	}
+
+	if reaped, err := pdm.ReapExpiredLeases(projectName, "daemon", time.Now()); err != nil || reaped != 0 {
+		t.Fatalf("expected live leases to be kept, reaped %d (%v)", reaped, err)
+	}
+
+	// Only t-1's worker stops renewing.
+	if held, err := pdm.RenewLease(projectName, "t-2", "worker-t-2"); err != nil || !held {
+		t.Fatalf("renew failed: %v", err)
+	}
+	expired := time.Now().Add(3 * time.Minute)
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		tx.setLease("t-2", "worker-t-2", expired.Add(time.Minute))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("extend failed: %v", err)
+	}
+
+	reaped, err := pdm.ReapExpiredLeases(projectName, "daemon", expired)
+	if err != nil || reaped != 1 {
+		t.Fatalf("expected one expired lease to be reaped, got %d (%v)", reaped, err)
+	}
+
+	task := loadLeaseTask(t, pdm, projectName, "t-1")
+	if task.Status != "RETRYING" && task.Status != "PENDING" {
+		t.Fatalf("expected the reaped task to be retried, got %s", task.Status)
+	}
+	if task.LastError != "lease expired" || task.Attempts != 1 {
+		t.Fatalf("expected attempt 1 failed with \"lease expired\", got %d %q", task.Attempts, task.LastError)
+	}
+	if task.AssignedTo != "" || task.LeaseOwner != "" {
+		t.Fatalf("expected the dead worker to be released, got assignee %q lease %q", task.AssignedTo, task.LeaseOwner)
+	}
+	if got := loadLeaseTask(t, pdm, projectName, "t-2").Status; got != "IN_PROGRESS" {
+		t.Fatalf("expected the renewed task to keep running, got %s", got)
+	}
+
+	stored, err := pdm.eventStore(projectName).ReadAll()
+	if err != nil {
+		t.Fatalf("read events failed: %v", err)
+	}
+	var types []string
+	for _, event := range stored {
+		if event.TaskID == "t-1" {
+			types = append(types, event.Type)
+		}
+	}
+	if got := strings.Join(types, " "); !strings.Contains(got, "TASK_LEASE_EXPIRED TASK_STATUS_CHANGED") || !strings.Contains(got, "TASK_RETRY_SCHEDULED") {
+		t.Fatalf("expected a lease expiry, failure and retry, got %q", got)
+	}
+
+	if held, err := pdm.RenewLease(projectName, "t-1", "worker-t-1"); err != nil || held {
+		t.Fatalf("expected the reaped worker to have lost its lease, got %v (%v)", held, err)
+	}
+}
+
+func TestRunTask_StopsWhenLeaseIsLost(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+	defer func(poll time.Duration) { taskStatusPoll = poll }(taskStatusPoll)
+	taskStatusPoll = 10 * time.Millisecond
+
+	pdm, projectName, cleanup := newLeaseTestProject(t, TaskV11{
+		ID: "t-1", Name: "slow", Status: "TODO",
+		Behavior: AgentBehavior{Command: "sleep 30"},
+	})
+	defer cleanup()
+	if err := pdm.SetProjectSetting(projectName, "lease.ttl", "150ms"); err != nil {
+		t.Fatalf("set lease ttl failed: %v", err)
+	}
+	task, err := pdm.ClaimNextRunnableTask(projectName, "worker-1")
+	if err != nil || task == nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- (&BackgroundRunner{ProjectManager: pdm}).RunTask(projectName, "worker-1", task) }()
+
+	// The running worker keeps renewing well past the original ttl.
+	time.Sleep(400 * time.Millisecond)
+	if reaped, err := pdm.ReapExpiredLeases(projectName, "swarm", time.Now()); err != nil || reaped != 0 {
+		t.Fatalf("expected a renewed lease, reaped %d (%v)", reaped, err)
+	}
+
+	// Another worker takes over, as after the task was reaped and retried.
+	err = pdm.Update(projectName, func(tx *ProjectTx) error {
+		tx.setLease("t-1", "worker-2", time.Now().Add(time.Hour))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("take over failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a quiet stop, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("run did not stop after losing its lease")
+	}
+	if task := loadLeaseTask(t, pdm, projectName, "t-1"); task.Status != "IN_PROGRESS" || task.LeaseOwner != "worker-2" {
+		t.Fatalf("expected worker-2 to keep the task, got %s held by %q", task.Status, task.LeaseOwner)
+	}
+}
+
+func TestRunSwarmToCompletion_RecoversTaskOfDeadWorker(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+
+	pdm, projectName, cleanup := newLeaseTestProject(t, TaskV11{
+		ID: "t-1", Name: "orphaned", Status: "TODO",
+		Behavior:    AgentBehavior{Command: "echo done"},
+		RetryPolicy: &RetryPolicy{MaxAttempts: 2, Backoff: "fixed"},
+	})
+	defer cleanup()
+
+	// A worker of an earlier swarm claimed the task and died.
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if _, err := tx.SetStatus("t-1", "IN_PROGRESS", "worker-9"); err != nil {
+			return err
+		}
+		tx.setLease("t-1", "worker-9", time.Now().Add(-time.Second))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	runner := &BackgroundRunner{ProjectManager: pdm}
+	if err := runSwarmToCompletion(projectName, 1, 25*time.Millisecond, 5*time.Second, runner, pdm, nil); err != nil {
+		t.Fatalf("unexpected swarm error: %v", err)
+	}
+	if task := loadLeaseTask(t, pdm, projectName, "t-1"); task.Status != "DONE" || task.AssignedTo != "worker-1" {
+		t.Fatalf("expected worker-1 to finish the recovered task, got %s by %q", task.Status, task.AssignedTo)
+	}
+}
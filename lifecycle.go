@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/trstoyan/quickplan/internal/swarm"
+)
+
+const lifecycleInfinite = "Infinite"
+
+// defaultLoopInterval is the pause between iterations of an Infinite task
+// without a loop_interval.
+const defaultLoopInterval = time.Minute
+
+// loopInterval parses behavior.loop_interval.
+func loopInterval(behavior AgentBehavior) (time.Duration, error) {
+	if behavior.LoopInterval == "" {
+		return defaultLoopInterval, nil
+	}
+	interval, err := time.ParseDuration(behavior.LoopInterval)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid loop_interval %q (expected a positive duration such as 30s)", behavior.LoopInterval)
+	}
+	return interval, nil
+}
+
+// exitCode returns the exit code of a finished run: 0 on success, the
+// command's code when it exited non-zero, and -1 when it did not run.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exited interface{ ExitCode() int }
+	if errors.As(err, &exited) {
+		return exited.ExitCode()
+	}
+	return -1
+}
+
+// behaviorFindings checks the loop and timeout settings of a task's behavior.
+func behaviorFindings(taskIndex int, task TaskV11) []finding {
+	behavior := task.Behavior
+	var findings []finding
+	if behavior.LifeCycle == lifecycleInfinite {
+		if _, err := loopInterval(behavior); err != nil {
+			findings = append(findings, errorAt(taskIndex, "behavior", -1, "task-loop-interval", "task %s: %v", task.ID, err))
+		}
+	} else if behavior.MaxIterations != 0 || behavior.UntilExitCode != nil {
+		findings = append(findings, warningAt(taskIndex, "behavior", -1, "task-loop-ignored",
+			"task %s sets max_iterations or until_exit_code, which only apply to the Infinite lifecycle", task.ID))
+	}
+	if _, err := taskTimeout(behavior); err != nil {
+		findings = append(findings, errorAt(taskIndex, "behavior", -1, "task-timeout-invalid", "task %s: %v", task.ID, err))
+	}
+	if behavior.MaxIterations < 0 {
+		findings = append(findings, errorAt(taskIndex, "behavior", -1, "task-loop-max-iterations",
+			"task %s: max_iterations cannot be negative", task.ID))
+	}
+	return findings
+}
+
+// runLoop runs an Infinite task every loop_interval, recording a
+// TASK_ITERATION event per run. The task stays IN_PROGRESS throughout. The
+// loop ends when ctx ends because the task left IN_PROGRESS (e.g. was
+// cancelled), which leaves the status alone, when Stop is closed, which
+// hands the task back as PENDING for the next worker, or when
+// max_iterations runs are done or a run exits with until_exit_code, which
+// finalize it like an Atomic run. Each run gets its own timeout.
+func (br *BackgroundRunner) runLoop(ctx context.Context, project, agentID string, task *TaskView, plan executionPlan, runner swarm.Runner) error {
+	interval, err := loopInterval(task.Behavior)
+	if err != nil {
+		br.logExecutionError(agentID, "Task has an invalid loop", err, "")
+		_ = br.finalizeTask(project, agentID, task, "FAILED", err.Error())
+		return err
+	}
+
+	for iteration := 1; ; iteration++ {
+		output, runErr := br.execute(ctx, project, agentID, task, plan, runner)
+		if errors.Is(runErr, errTaskReleased) {
+			return br.finish(project, agentID, task, output, runErr)
+		}
+		code := exitCode(runErr)
+		br.recordIteration(project, agentID, task.ID, iteration, code, runErr)
+
+		if until := task.Behavior.UntilExitCode; until != nil && code == *until {
+			return br.finish(project, agentID, task, output, nil)
+		}
+		if max := task.Behavior.MaxIterations; max > 0 && iteration >= max {
+			return br.finish(project, agentID, task, output, runErr)
+		}
+		if runErr != nil {
+			br.logExecutionError(agentID, fmt.Sprintf("Iteration %d failed", iteration), runErr, output)
+		}
+
+		if !br.waitForIteration(ctx, interval) {
+			return br.releaseStoppedLoop(ctx, project, agentID, task)
+		}
+	}
+}
+
+// releaseStoppedLoop hands back a task whose loop Stop ended, so a restarted
+// worker resumes it instead of the reaper failing it once the lease runs
+// out. A task that left IN_PROGRESS on its own is left alone.
+func (br *BackgroundRunner) releaseStoppedLoop(ctx context.Context, project, agentID string, task *TaskView) error {
+	if ctx.Err() != nil || br.ProjectManager == nil || task.ID == "" || task.ID == "default" {
+		return nil
+	}
+	released, err := br.ProjectManager.ReleaseTask(project, task.ID, agentID, "worker stopped")
+	if err != nil {
+		br.logExecutionError(agentID, "Failed to release task on stop", err, "")
+		return err
+	}
+	if released && br.Logger != nil {
+		br.Logger.Log("INFO", "Swarm", "Worker stopped; task released", map[string]interface{}{
+			"agent": agentID,
+			"task":  task.ID,
+		})
+	}
+	return nil
+}
+
+// waitForIteration sleeps until the next iteration is due. It returns false
+// as soon as Stop is closed or ctx ends.
+func (br *BackgroundRunner) waitForIteration(ctx context.Context, interval time.Duration) bool {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-br.Stop:
+		return false
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// recordIteration appends a TASK_ITERATION event for one run of a loop.
+func (br *BackgroundRunner) recordIteration(project, agentID, taskID string, iteration, code int, runErr error) {
+	message := fmt.Sprintf("Iteration %d exited %d", iteration, code)
+	if runErr != nil {
+		message = fmt.Sprintf("Iteration %d failed (exit %d): %v", iteration, code, runErr)
+	}
+	br.recordEvent(project, agentID, Event{Type: "TASK_ITERATION", Actor: agentID, TaskID: taskID, Message: message})
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startLoopTask saves one IN_PROGRESS task with behavior and returns its view.
+func startLoopTask(t *testing.T, pdm *ProjectDataManager, projectName string, behavior AgentBehavior) TaskView {
+	t.Helper()
+	projectData, err := pdm.LoadProjectData(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	projectData.Tasks = []Task{{ID: 1, Text: "loop", Status: "TODO", Created: time.Now(), Behavior: behavior}}
+	if err := pdm.SaveProjectData(projectName, projectData); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if err := pdm.UpdateTaskStatus(projectName, "t-1", "IN_PROGRESS", "worker-1"); err != nil {
+		t.Fatalf("failed to set IN_PROGRESS: %v", err)
+	}
+	views, _, err := pdm.GetTaskViews(projectName)
+	if err != nil {
+		t.Fatalf("failed to load task views: %v", err)
+	}
+	return views[0]
+}
+
+func iterationEvents(t *testing.T, pdm *ProjectDataManager, projectName string) []string {
+	t.Helper()
+	eventLog, err := pdm.loadCanonicalEventLog(projectName)
+	if err != nil {
+		t.Fatalf("read events failed: %v", err)
+	}
+	var messages []string
+	for _, event := range eventLog.Events {
+		if event.Type == "TASK_ITERATION" {
+			messages = append(messages, event.Message)
+		}
+	}
+	return messages
+}
+
+func taskStatus(t *testing.T, pdm *ProjectDataManager, projectName, taskID string) string {
+	t.Helper()
+	views, _, err := pdm.GetTaskViews(projectName)
+	if err != nil {
+		t.Fatalf("failed to load task views: %v", err)
+	}
+	for _, view := range views {
+		if view.ID == taskID {
+			return view.Status
+		}
+	}
+	t.Fatalf("task %s not found", taskID)
+	return ""
+}
+
+func TestRunTask_InfiniteStopsAfterMaxIterations(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	task := startLoopTask(t, pdm, projectName, AgentBehavior{
+		LifeCycle:     lifecycleInfinite,
+		LoopInterval:  "10ms",
+		MaxIterations: 3,
+		Command:       "true",
+	})
+	runner := &BackgroundRunner{ProjectManager: pdm}
+	if err := runner.RunTask(projectName, "worker-1", &task); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if got := iterationEvents(t, pdm, projectName); len(got) != 3 || got[2] != "Iteration 3 exited 0" {
+		t.Fatalf("expected 3 iterations, got %q", got)
+	}
+	if status := taskStatus(t, pdm, projectName, "t-1"); status != "DONE" {
+		t.Fatalf("expected DONE after the last iteration, got %s", status)
+	}
+}
+
+func TestRunTask_InfiniteStopsOnExitCode(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	// Exits 0, 0, then 3.
+	counter := filepath.Join(t.TempDir(), "runs")
+	command := fmt.Sprintf(`n=$(( $(cat %[1]s 2>/dev/null || echo 0) + 1 )); echo $n > %[1]s; [ $n -lt 3 ] || exit 3`, counter)
+	until := 3
+	task := startLoopTask(t, pdm, projectName, AgentBehavior{
+		LifeCycle:     lifecycleInfinite,
+		LoopInterval:  "10ms",
+		UntilExitCode: &until,
+		Command:       command,
+	})
+	runner := &BackgroundRunner{ProjectManager: pdm}
+	if err := runner.RunTask(projectName, "worker-1", &task); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	got := iterationEvents(t, pdm, projectName)
+	if len(got) != 3 || got[2] != "Iteration 3 failed (exit 3): exit status 3" {
+		t.Fatalf("expected the loop to end on the third iteration, got %q", got)
+	}
+	if status := taskStatus(t, pdm, projectName, "t-1"); status != "DONE" {
+		t.Fatalf("expected until_exit_code to finish the task, got %s", status)
+	}
+}
+
+func TestRunTask_InfiniteEndsWhenCancelledOrStopped(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+	defer func(poll time.Duration) { taskStatusPoll = poll }(taskStatusPoll)
+	taskStatusPoll = 5 * time.Millisecond
+
+	behavior := AgentBehavior{LifeCycle: lifecycleInfinite, LoopInterval: "20ms", Command: "true"}
+
+	t.Run("cancelled", func(t *testing.T) {
+		pdm, projectName, cleanup := newTransitionTestManager(t)
+		defer cleanup()
+		task := startLoopTask(t, pdm, projectName, behavior)
+
+		done := make(chan error, 1)
+		go func() { done <- (&BackgroundRunner{ProjectManager: pdm}).RunTask(projectName, "worker-1", &task) }()
+
+		time.Sleep(100 * time.Millisecond)
+		if err := pdm.UpdateTaskStatus(projectName, "t-1", "CANCELLED", "human"); err != nil {
+			t.Fatalf("cancel failed: %v", err)
+		}
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("loop did not stop after cancellation")
+		}
+		if status := taskStatus(t, pdm, projectName, "t-1"); status != "CANCELLED" {
+			t.Fatalf("expected the task to stay CANCELLED, got %s", status)
+		}
+		if got := iterationEvents(t, pdm, projectName); len(got) < 2 {
+			t.Fatalf("expected repeated iterations before cancelling, got %q", got)
+		}
+	})
+
+	t.Run("stopped and restarted", func(t *testing.T) {
+		pdm, projectName, cleanup := newTransitionTestManager(t)
+		defer cleanup()
+		task := startLoopTask(t, pdm, projectName, behavior)
+		if held, err := pdm.RenewLease(projectName, "t-1", "worker-1"); err != nil || !held {
+			t.Fatalf("failed to take the lease: %v", err)
+		}
+
+		stop := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- (&BackgroundRunner{ProjectManager: pdm, Stop: stop}).RunTask(projectName, "worker-1", &task)
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		close(stop)
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("loop did not stop on shutdown")
+		}
+		views, _, err := pdm.GetTaskViews(projectName)
+		if err != nil {
+			t.Fatalf("failed to load task views: %v", err)
+		}
+		if view := views[0]; view.Status != "PENDING" || view.LeaseOwner != "" || view.LeaseExpiresAt != nil || view.AssignedTo != "" {
+			t.Fatalf("expected shutdown to hand the task back as PENDING without a lease, got %+v", view)
+		}
+
+		// A restarted worker resumes the loop, and the reaper has nothing to fail.
+		if n, err := pdm.ReapExpiredLeases(projectName, "daemon", time.Now().Add(time.Hour)); err != nil || n != 0 {
+			t.Fatalf("expected nothing to reap after a clean stop, got %d (%v)", n, err)
+		}
+		claimed, err := pdm.ClaimNextRunnableTask(projectName, "worker-2")
+		if err != nil || claimed == nil || claimed.ID != "t-1" {
+			t.Fatalf("expected a restarted worker to claim t-1, got %+v (%v)", claimed, err)
+		}
+	})
+}
+
+func TestBehaviorFindings(t *testing.T) {
+	cases := []struct {
+		behavior AgentBehavior
+		want     string
+	}{
+		{AgentBehavior{LifeCycle: lifecycleInfinite, LoopInterval: "30s", MaxIterations: 5}, ""},
+		{AgentBehavior{LifeCycle: lifecycleInfinite, LoopInterval: "soon"}, "task-loop-interval"},
+		{AgentBehavior{LifeCycle: lifecycleInfinite, LoopInterval: "-1s"}, "task-loop-interval"},
+		{AgentBehavior{LifeCycle: "Atomic", MaxIterations: 2}, "task-loop-ignored"},
+		{AgentBehavior{LifeCycle: lifecycleInfinite, MaxIterations: -1}, "task-loop-max-iterations"},
+	}
+	for _, tc := range cases {
+		findings := behaviorFindings(0, TaskV11{ID: "t-1", Behavior: tc.behavior})
+		got := ""
+		if len(findings) > 0 {
+			got = findings[0].Code
+		}
+		if got != tc.want || len(findings) > 1 {
+			t.Errorf("%+v: expected %q, got %+v", tc.behavior, tc.want, findings)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultLockTimeout bounds how long AcquireLock waits for a busy lock.
+	defaultLockTimeout  = 30 * time.Second
+	lockPollInterval    = 10 * time.Millisecond
+	maxLockPollInterval = 250 * time.Millisecond
+)
+
+// heldLock is a project lock owned by this process. The open file keeps the
+// kernel lock alive; the heartbeat keeps the metadata fresh.
+type heldLock struct {
+	file *os.File
+	meta Lock
+	stop chan struct{}
+	done chan struct{}
+}
+
+// LockState describes a project lock as reported by `quickplan lock status`.
+type LockState struct {
+	Lock    *Lock
+	Held    bool
+	Stale   bool
+	Waiters []Lock
+}
+
+// LastSeen returns when the holder last proved it was alive.
+func (l Lock) LastSeen() time.Time {
+	if l.RenewedAt.After(l.CreatedAt) {
+		return l.RenewedAt
+	}
+	return l.CreatedAt
+}
+
+// Expired reports whether the holder missed its renewal window.
+func (l Lock) Expired(now time.Time) bool {
+	return now.After(l.LastSeen().Add(time.Duration(l.TTL) * time.Second))
+}
+
+// getLockWaitersDir returns the directory where blocked acquirers register.
+func (pdm *ProjectDataManager) getLockWaitersDir(projectName string) string {
+	return pdm.getLockPath(projectName) + ".waiters"
+}
+
+// AcquireLock takes the project lock, waiting up to the manager's lock
+// timeout while another process holds it. The lock is a flock(2) on the lock
+// file, so the kernel releases it if the holder dies. The YAML metadata in
+// the file is renewed every ttl/3 seconds until ReleaseLock.
+func (pdm *ProjectDataManager) AcquireLock(projectName string, ttl int) error {
+	deadline := time.Now().Add(pdm.lockTimeout)
+	interval := lockPollInterval
+	waiterPath := ""
+	defer func() {
+		if waiterPath != "" {
+			os.Remove(waiterPath)
+			// Only succeeds once the last waiter is gone.
+			os.Remove(filepath.Dir(waiterPath))
+		}
+	}()
+
+	for {
+		acquired, holder, err := pdm.tryAcquireLock(projectName, ttl)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Project directory might not exist yet (e.g. during creation)
+				return nil
+			}
+			return err
+		}
+		if acquired {
+			return nil
+		}
+
+		if !time.Now().Before(deadline) {
+			if holder == nil {
+				return fmt.Errorf("project is locked (timed out after %s)", pdm.lockTimeout)
+			}
+			return fmt.Errorf("project is locked by pid %d on host %s (held for %s, timed out after %s)",
+				holder.PID, holder.Host, time.Since(holder.CreatedAt).Round(time.Second), pdm.lockTimeout)
+		}
+		if waiterPath == "" {
+			waiterPath = pdm.registerLockWaiter(projectName)
+		}
+		time.Sleep(interval)
+		interval = min(interval*2, maxLockPollInterval)
+	}
+}
+
+// tryAcquireLock makes one non-blocking attempt. When the lock is busy it
+// returns the current holder's metadata, if readable.
+func (pdm *ProjectDataManager) tryAcquireLock(projectName string, ttl int) (bool, *Lock, error) {
+	lockPath := pdm.getLockPath(projectName)
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil, err
+		}
+		return false, nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	locked, err := tryLockFile(f)
+	if err != nil {
+		f.Close()
+		return false, nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+	existing := readLockMeta(f)
+	if !locked {
+		f.Close()
+		return false, existing, nil
+	}
+
+	// The previous holder unlinks the file on release; if that happened
+	// between our open and lock we hold a lock on an orphaned inode.
+	if !lockFileIsCurrent(f, lockPath) {
+		unlockFile(f)
+		f.Close()
+		return false, nil, nil
+	}
+
+	// Metadata the kernel cannot vouch for (another host on a shared
+	// filesystem, or no flock support) is honored until it expires.
+	host, _ := os.Hostname()
+	now := time.Now()
+	if existing != nil && existing.PID != 0 && (!kernelLocking || existing.Host != host) {
+		if !existing.Expired(now) {
+			unlockFile(f)
+			f.Close()
+			return false, existing, nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: expired lock from pid %d on host %s, overriding...\n", existing.PID, existing.Host)
+	}
+
+	held := &heldLock{
+		file: f,
+		meta: Lock{
+			PID:       os.Getpid(),
+			Host:      host,
+			CreatedAt: now,
+			RenewedAt: now,
+			TTL:       ttl,
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := writeLockMeta(f, held.meta); err != nil {
+		unlockFile(f)
+		f.Close()
+		return false, nil, err
+	}
+
+	pdm.locksMu.Lock()
+	if pdm.locks == nil {
+		pdm.locks = make(map[string]*heldLock)
+	}
+	pdm.locks[projectName] = held
+	pdm.locksMu.Unlock()
+
+	go held.heartbeat()
+	return true, nil, nil
+}
+
+// heartbeat renews the lock metadata so long operations are not mistaken for
+// dead holders by hosts that can only see the TTL.
+func (h *heldLock) heartbeat() {
+	defer close(h.done)
+
+	interval := time.Duration(h.meta.TTL) * time.Second / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case now := <-ticker.C:
+			h.meta.RenewedAt = now
+			if err := writeLockMeta(h.file, h.meta); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to renew lock: %v\n", err)
+			}
+		}
+	}
+}
+
+// ReleaseLock stops the heartbeat, removes the lock file and drops the
+// kernel lock. Called for a lock this process does not hold (e.g.
+// `quickplan unlock --force`) it only removes the file.
+func (pdm *ProjectDataManager) ReleaseLock(projectName string) error {
+	pdm.locksMu.Lock()
+	held := pdm.locks[projectName]
+	delete(pdm.locks, projectName)
+	pdm.locksMu.Unlock()
+
+	if held != nil {
+		close(held.stop)
+		<-held.done
+	}
+
+	// Unlink before unlocking so waiters re-open a fresh file instead of
+	// locking the one being retired.
+	err := os.Remove(pdm.getLockPath(projectName))
+	if held != nil {
+		unlockFile(held.file)
+		held.file.Close()
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// IsLockStale checks if a lock is stale
+func (pdm *ProjectDataManager) IsLockStale(projectName string) (bool, *Lock, error) {
+	state, err := pdm.LockStatus(projectName)
+	if err != nil {
+		return false, nil, err
+	}
+	return state.Stale, state.Lock, nil
+}
+
+// LockStatus inspects the project lock without taking it. A lock file no
+// process holds a kernel lock on is stale; metadata from another host is
+// judged by its renewal TTL.
+func (pdm *ProjectDataManager) LockStatus(projectName string) (*LockState, error) {
+	lockPath := pdm.getLockPath(projectName)
+	f, err := os.OpenFile(lockPath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	state := &LockState{Lock: readLockMeta(f)}
+	host, _ := os.Hostname()
+	if state.Lock != nil && (!kernelLocking || state.Lock.Host != host) {
+		state.Held = !state.Lock.Expired(time.Now())
+	} else {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to probe lock: %w", err)
+		}
+		if locked {
+			unlockFile(f)
+		}
+		state.Held = !locked
+	}
+	state.Stale = !state.Held
+	if state.Held && state.Lock == nil {
+		// Holder is mid-write of its metadata.
+		state.Lock = &Lock{}
+	}
+
+	state.Waiters = pdm.lockWaiters(projectName)
+	return state, nil
+}
+
+// registerLockWaiter records this process as blocked on the project lock so
+// `lock status` can report contention. It returns the file to remove once
+// the wait is over, or "" if registration failed.
+func (pdm *ProjectDataManager) registerLockWaiter(projectName string) string {
+	dir := pdm.getLockWaitersDir(projectName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ""
+	}
+
+	host, _ := os.Hostname()
+	f, err := os.CreateTemp(dir, fmt.Sprintf("%d-*.yaml", os.Getpid()))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	data, _ := yaml.Marshal(Lock{
+		PID:       os.Getpid(),
+		Host:      host,
+		CreatedAt: time.Now(),
+		TTL:       int(pdm.lockTimeout/time.Second) + 1,
+	})
+	f.Write(data)
+	return f.Name()
+}
+
+// lockWaiters lists live waiters, oldest first. Registrations left behind by
+// crashed or timed-out processes are cleaned up.
+func (pdm *ProjectDataManager) lockWaiters(projectName string) []Lock {
+	dir := pdm.getLockWaitersDir(projectName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	host, _ := os.Hostname()
+	now := time.Now()
+	var waiters []Lock
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var w Lock
+		if err := yaml.Unmarshal(data, &w); err != nil {
+			continue
+		}
+		if w.Expired(now) || (w.Host == host && !processAlive(w.PID)) {
+			os.Remove(path)
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+
+	sort.Slice(waiters, func(i, j int) bool {
+		return waiters[i].CreatedAt.Before(waiters[j].CreatedAt)
+	})
+	return waiters
+}
+
+// readLockMeta parses the metadata in an open lock file, or returns nil if
+// it is empty or unreadable.
+func readLockMeta(f *os.File) *Lock {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 1<<20))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var lock Lock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+	return &lock
+}
+
+// writeLockMeta rewrites the metadata in place. The lock lives on the file's
+// inode, so it must not be replaced via rename like other state files.
+func writeLockMeta(f *os.File, lock Lock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock data: %w", err)
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write lock data: %w", err)
+	}
+	if err := f.Truncate(int64(len(data))); err != nil {
+		return fmt.Errorf("failed to write lock data: %w", err)
+	}
+	return nil
+}
+
+// lockFileIsCurrent reports whether f is still the file at path.
+func lockFileIsCurrent(f *os.File, path string) bool {
+	held, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(held, current)
+}
+
+// processAlive is a best-effort liveness check for a pid on this host.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// On Unix, FindProcess always succeeds. Use signal 0 to check existence.
+	if runtime.GOOS != "windows" {
+		return process.Signal(syscall.Signal(0)) == nil
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// kernelLocking reports whether lock ownership is enforced by flock(2).
+// Without it, lock metadata and its renewal TTL are authoritative.
+const kernelLocking = false
+
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
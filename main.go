@@ -20,7 +20,7 @@ var (
 tasks into named projects with vim-inspired selection menus.`,
 		Version: version,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			// Pre-execution logic if needed
+			useProjectDataDir(cmd)
 		},
 	}
 )
@@ -77,6 +77,14 @@ func init() {
 	rootCmd.AddCommand(importCmd)
 	rootCmd.AddCommand(aclCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(revertCmd)
+	rootCmd.AddCommand(setCmd)
+	rootCmd.AddCommand(agendaCmd)
+	rootCmd.AddCommand(cancelCmd)
+	rootCmd.AddCommand(logsCmd)
 }
 
 // Get the data directory for storing projects and tasks
@@ -89,6 +97,31 @@ func getDataDir() (string, error) {
 		return envDir, nil
 	}
 
+	// 2. The data directory --project was found in (see useProjectDataDir)
+	if projectDataDir != "" {
+		return projectDataDir, nil
+	}
+
+	// 3. Repository-local .quickplan/ in the working directory or a parent
+	if cwd, err := os.Getwd(); err == nil {
+		if repoDir := findRepoDataDir(cwd); repoDir != "" {
+			return repoDir, nil
+		}
+	}
+
+	return globalDataDir()
+}
+
+// globalDataDir returns the data directory used outside repositories.
+func globalDataDir() (string, error) {
+	// 1. data_dir from the global config file
+	if configured := globalSetting("data_dir"); configured.Value != "" {
+		if err := os.MkdirAll(configured.Value, 0755); err != nil {
+			return "", fmt.Errorf("failed to create configured data directory: %w", err)
+		}
+		return configured.Value, nil
+	}
+
 	// 2. Try standard location (~/.local/share/quickplan)
 	usr, err := user.Current()
 	if err == nil {
@@ -117,7 +150,8 @@ func ensureIgnoreFile(dataDir string) {
 	}
 }
 
-// Get the current project context
+// Get the current project context. Inside a repository with a .quickplan/
+// directory this is that directory's project; getDataDir resolves to it.
 func getCurrentProject() (string, error) {
 	dataDir, err := getDataDir()
 	if err != nil {
@@ -127,6 +161,11 @@ func getCurrentProject() (string, error) {
 	contextFile := filepath.Join(dataDir, ".current_project")
 	project, err := os.ReadFile(contextFile)
 	if err != nil {
+		// .current_project is not committed, so a fresh clone of a
+		// repository with a single project starts in that project.
+		if project := soleRepoProject(dataDir); project != "" {
+			return project, nil
+		}
 		return "default", nil // Return default if no context file exists
 	}
 
@@ -141,5 +180,5 @@ func setCurrentProject(project string) error {
 	}
 
 	contextFile := filepath.Join(dataDir, ".current_project")
-	return os.WriteFile(contextFile, []byte(project), 0644)
+	return writeFileAtomic(contextFile, []byte(project), 0644)
 }
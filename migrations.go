@@ -0,0 +1,526 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// migrationJournalFile records every applied step so it can be rolled back.
+const migrationJournalFile = ".migrations.yaml"
+
+// Migration is one registered, reversible step for a project file. Up and
+// Down edit the YAML node tree in place, so comments and keys this build
+// does not know about survive; the engine rewrites the version key itself.
+type Migration struct {
+	File        string // "tasks.yaml" or "project.yaml"
+	From        string
+	To          string
+	Description string
+	// Auto steps run whenever the file is loaded. The rest produce files
+	// older builds reject, so they only run on request (migrate up).
+	Auto bool
+	Up   func(root *yaml.Node) error
+	Down func(root *yaml.Node) error
+}
+
+// migrationRegistry lists every step in the order it applies. tasks.yaml is
+// versioned by the CLI release that wrote it, project.yaml by schema_version.
+var migrationRegistry = []Migration{
+	{
+		File:        "tasks.yaml",
+		From:        "",
+		To:          "0.1.0",
+		Description: "Tag unversioned tasks.yaml",
+		Auto:        true,
+		Up:          noopMigration,
+		Down:        noopMigration,
+	},
+	{
+		File:        "project.yaml",
+		From:        schemaV11,
+		To:          schemaV12,
+		Description: allowTaskFieldsDescription(schemaV12),
+		Up:          noopMigration,
+		Down:        refuseTaskFieldsOf(schemaV12, schemaV11),
+	},
+	{
+		File:        "project.yaml",
+		From:        schemaV12,
+		To:          schemaV13,
+		Description: allowTaskFieldsDescription(schemaV13),
+		Up:          noopMigration,
+		Down:        refuseTaskFieldsOf(schemaV13, schemaV12),
+	},
+}
+
+// MigrationRecord is one applied step in a project's migration journal.
+type MigrationRecord struct {
+	File        string    `yaml:"file" json:"file"`
+	From        string    `yaml:"from" json:"from"`
+	To          string    `yaml:"to" json:"to"`
+	Description string    `yaml:"description" json:"description"`
+	Backup      string    `yaml:"backup" json:"backup"`
+	AppliedAt   time.Time `yaml:"applied_at" json:"applied_at"`
+}
+
+type migrationJournal struct {
+	Applied []MigrationRecord `yaml:"applied"`
+}
+
+// MigrationFileStatus describes where one project file stands.
+type MigrationFileStatus struct {
+	File    string      `json:"file"`
+	Version string      `json:"version"`
+	Latest  string      `json:"latest"`
+	Pending []Migration `json:"-"`
+}
+
+func noopMigration(root *yaml.Node) error { return nil }
+
+// allowTaskFieldsDescription describes the step to a schema version by the
+// task keys it introduces.
+func allowTaskFieldsDescription(version string) string {
+	keys := taskFieldKeysAddedIn(version)
+	if len(keys) > 1 {
+		return fmt.Sprintf("Allow %s and %s on tasks", strings.Join(keys[:len(keys)-1], ", "), keys[len(keys)-1])
+	}
+	return fmt.Sprintf("Allow %s on tasks", strings.Join(keys, ""))
+}
+
+// refuseTaskFieldsOf returns the Down step from schema version to the
+// previous one: it refuses to drop task keys version introduced, which
+// previous cannot hold.
+func refuseTaskFieldsOf(version, previous string) func(root *yaml.Node) error {
+	keys := taskFieldKeysAddedIn(version)
+	return func(root *yaml.Node) error {
+		tasks := mappingValue(root, "tasks")
+		if tasks == nil {
+			return nil
+		}
+		for _, task := range tasks.Content {
+			for _, key := range keys {
+				if mappingValue(task, key) != nil {
+					id := "?"
+					if idNode := mappingValue(task, "id"); idNode != nil {
+						id = idNode.Value
+					}
+					return fmt.Errorf("task %s uses %s, which schema %s cannot hold; clear it before rolling back", id, key, previous)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// migrationVersionKey is the key holding a file's version.
+func migrationVersionKey(file string) string {
+	if file == "project.yaml" {
+		return "schema_version"
+	}
+	return "quickplan-cli-version"
+}
+
+// latestVersion is the newest version a file can be migrated to.
+func (vm *VersionManager) latestVersion(file string) string {
+	if file == "tasks.yaml" {
+		return vm.currentVersion
+	}
+	latest := schemaV11
+	for _, m := range migrationRegistry {
+		if m.File == file {
+			latest = m.To
+		}
+	}
+	return latest
+}
+
+// planMigrations returns the steps that take file from version current to
+// target. With autoOnly it stops at the first opt-in step. tasks.yaml gets
+// a final step stamping the running CLI version, as it always has.
+func (vm *VersionManager) planMigrations(file, current, target string, autoOnly bool) []Migration {
+	var plan []Migration
+	for _, m := range migrationRegistry {
+		if m.File != file || !versionLess(current, m.To) || versionLess(target, m.To) {
+			continue
+		}
+		if autoOnly && !m.Auto {
+			break
+		}
+		step := m
+		step.From = current
+		plan = append(plan, step)
+		current = m.To
+	}
+	if file == "tasks.yaml" && current != target && versionLess(current, target) {
+		plan = append(plan, Migration{
+			File:        file,
+			From:        current,
+			To:          target,
+			Description: "Stamp quickplan-cli-version",
+			Auto:        true,
+			Up:          noopMigration,
+			Down:        noopMigration,
+		})
+	}
+	return plan
+}
+
+// hasPendingAutoMigrations reports whether loading a file at version must
+// migrate it first. It only inspects the registry, so loaders can call it
+// on every read.
+func (vm *VersionManager) hasPendingAutoMigrations(file, version string) bool {
+	return len(vm.planMigrations(file, version, vm.latestVersion(file), true)) > 0
+}
+
+// Migrate applies the registered steps that take file in projectPath to
+// target (its latest version when empty). A copy of the file as it was is
+// written next to it before anything changes, and every step is journaled
+// for rollback. With dryRun the migrated document is returned unwritten.
+func (vm *VersionManager) Migrate(projectPath, file, target string, autoOnly, dryRun bool) ([]byte, []MigrationRecord, error) {
+	if target == "" {
+		target = vm.latestVersion(file)
+	}
+	path := filepath.Join(projectPath, file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("%s is not a mapping", file)
+	}
+	root := doc.Content[0]
+
+	key := migrationVersionKey(file)
+	current := ""
+	if versionNode := mappingValue(root, key); versionNode != nil {
+		current = versionNode.Value
+	}
+
+	plan := vm.planMigrations(file, current, target, autoOnly)
+	if len(plan) == 0 {
+		if current != target && !autoOnly {
+			return nil, nil, fmt.Errorf("no migration path for %s from %s to %s", file, displayVersion(current), target)
+		}
+		return nil, nil, nil
+	}
+
+	backup := fmt.Sprintf("%s.%s.bak", path, displayVersion(current))
+	now := time.Now()
+	records := make([]MigrationRecord, 0, len(plan))
+	for _, step := range plan {
+		if err := step.Up(root); err != nil {
+			return nil, nil, fmt.Errorf("migration %s %s -> %s failed: %w", file, displayVersion(step.From), step.To, err)
+		}
+		setMigrationVersion(root, file, step.To)
+		records = append(records, MigrationRecord{
+			File:        file,
+			From:        step.From,
+			To:          step.To,
+			Description: step.Description,
+			Backup:      filepath.Base(backup),
+			AppliedAt:   now,
+		})
+	}
+
+	out, err := vm.encodeMigrated(path, file, &doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dryRun {
+		return out, records, nil
+	}
+
+	if err := writeFileAtomic(backup, data, 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to back up %s: %w", file, err)
+	}
+	if err := writeFileAtomicWithBackup(path, out, 0644); err != nil {
+		return nil, nil, err
+	}
+
+	journal, err := loadMigrationJournal(projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	journal.Applied = append(journal.Applied, records...)
+	if err := saveMigrationJournal(projectPath, journal); err != nil {
+		return nil, nil, err
+	}
+	return out, records, nil
+}
+
+// Rollback reverts the last steps applied in projectPath, newest first,
+// using each step's Down function. A file that no longer carries the
+// version a step produced has been changed since and is left alone.
+func (vm *VersionManager) Rollback(projectPath string, steps int) ([]MigrationRecord, error) {
+	journal, err := loadMigrationJournal(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if steps > len(journal.Applied) {
+		steps = len(journal.Applied)
+	}
+
+	var reverted []MigrationRecord
+	for i := 0; i < steps; i++ {
+		record := journal.Applied[len(journal.Applied)-1]
+		if err := vm.revertMigration(projectPath, record); err != nil {
+			if len(reverted) > 0 {
+				saveMigrationJournal(projectPath, journal)
+			}
+			return reverted, err
+		}
+		journal.Applied = journal.Applied[:len(journal.Applied)-1]
+		reverted = append(reverted, record)
+	}
+
+	if len(reverted) > 0 {
+		if err := saveMigrationJournal(projectPath, journal); err != nil {
+			return reverted, err
+		}
+	}
+	return reverted, nil
+}
+
+func (vm *VersionManager) revertMigration(projectPath string, record MigrationRecord) error {
+	path := filepath.Join(projectPath, record.File)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", record.File, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping", record.File)
+	}
+	root := doc.Content[0]
+
+	current := ""
+	if versionNode := mappingValue(root, migrationVersionKey(record.File)); versionNode != nil {
+		current = versionNode.Value
+	}
+	if current != record.To {
+		return fmt.Errorf("%s is at %s, not %s; it changed after the migration and cannot be rolled back", record.File, displayVersion(current), record.To)
+	}
+
+	down := noopMigration
+	for _, m := range migrationRegistry {
+		if m.File == record.File && m.To == record.To {
+			down = m.Down
+		}
+	}
+	if err := down(root); err != nil {
+		return fmt.Errorf("rollback %s %s -> %s failed: %w", record.File, record.To, displayVersion(record.From), err)
+	}
+	if record.File == "project.yaml" {
+		// Down steps only inspect tasks, so a task-files project can be
+		// checked against its task files gathered into one mapping.
+		tasks, err := taskFilesNode(projectPath, root)
+		if err != nil {
+			return err
+		}
+		if tasks != nil {
+			if err := down(tasks); err != nil {
+				return fmt.Errorf("rollback %s %s -> %s failed: %w", record.File, record.To, displayVersion(record.From), err)
+			}
+		}
+	}
+	setMigrationVersion(root, record.File, record.From)
+
+	out, err := vm.encodeMigrated(path, record.File, &doc)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomicWithBackup(path, out, 0644)
+}
+
+// encodeMigrated bumps the revision, so compare-and-swap writers holding
+// the old document notice, and checks the result still loads.
+func (vm *VersionManager) encodeMigrated(path, file string, doc *yaml.Node) ([]byte, error) {
+	root := doc.Content[0]
+	stored, err := storedRevision(path)
+	if err != nil {
+		return nil, err
+	}
+	revision := strconv.FormatInt(nextRevision(stored, 0), 10)
+	if revisionNode := mappingValue(root, "revision"); revisionNode != nil {
+		revisionNode.Value = revision
+	} else {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "revision"},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: revision})
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", file, err)
+	}
+
+	if file == "project.yaml" {
+		var migrated ProjectV11
+		if err := yaml.Unmarshal(out, &migrated); err != nil {
+			return nil, fmt.Errorf("migrated %s does not parse: %w", file, err)
+		}
+		if err := ValidateProjectV11(&migrated); err != nil {
+			return nil, fmt.Errorf("migrated %s is invalid: %w", file, err)
+		}
+	} else {
+		var migrated ProjectData
+		if err := yaml.Unmarshal(out, &migrated); err != nil {
+			return nil, fmt.Errorf("migrated %s does not parse: %w", file, err)
+		}
+	}
+	return out, nil
+}
+
+// MigrationStatus reports the version of each project file present in
+// projectPath, the steps still pending for it and the journal so far.
+func (vm *VersionManager) MigrationStatus(projectPath string) ([]MigrationFileStatus, []MigrationRecord, error) {
+	var files []MigrationFileStatus
+	for _, file := range []string{"project.yaml", "tasks.yaml"} {
+		data, err := os.ReadFile(filepath.Join(projectPath, file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		current := ""
+		if len(doc.Content) > 0 {
+			if versionNode := mappingValue(doc.Content[0], migrationVersionKey(file)); versionNode != nil {
+				current = versionNode.Value
+			}
+		}
+
+		latest := vm.latestVersion(file)
+		files = append(files, MigrationFileStatus{
+			File:    file,
+			Version: current,
+			Latest:  latest,
+			Pending: vm.planMigrations(file, current, latest, false),
+		})
+	}
+
+	journal, err := loadMigrationJournal(projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, journal.Applied, nil
+}
+
+// setMigrationVersion writes a file's version key, dropping it for the
+// empty (unversioned) version.
+func setMigrationVersion(root *yaml.Node, file, version string) {
+	key := migrationVersionKey(file)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != key {
+			continue
+		}
+		if version == "" {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			return
+		}
+		root.Content[i+1].Value = version
+		root.Content[i+1].Tag = "!!str"
+		if file == "project.yaml" {
+			root.Content[i+1].Style = yaml.DoubleQuotedStyle
+		}
+		return
+	}
+	if version == "" {
+		return
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: version}
+	if file == "project.yaml" {
+		value.Style = yaml.DoubleQuotedStyle
+	}
+	root.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Value: key}, value}, root.Content...)
+}
+
+// displayVersion names the empty version in messages and backup files.
+func displayVersion(version string) string {
+	if version == "" {
+		return "unversioned"
+	}
+	return version
+}
+
+func loadMigrationJournal(projectPath string) (*migrationJournal, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, migrationJournalFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &migrationJournal{}, nil
+		}
+		return nil, fmt.Errorf("failed to read migration journal: %w", err)
+	}
+	var journal migrationJournal
+	if err := yaml.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("failed to parse migration journal: %w", err)
+	}
+	return &journal, nil
+}
+
+func saveMigrationJournal(projectPath string, journal *migrationJournal) error {
+	data, err := yaml.Marshal(journal)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(projectPath, migrationJournalFile), data, 0644)
+}
+
+// MigrateProject applies pending migrations to the project's active file
+// (project.yaml when present, otherwise tasks.yaml) under the project lock.
+func (pdm *ProjectDataManager) MigrateProject(projectName, target string, dryRun bool) ([]byte, []MigrationRecord, error) {
+	if !dryRun {
+		if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+			return nil, nil, err
+		}
+		defer pdm.ReleaseLock(projectName)
+	}
+
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	file := "tasks.yaml"
+	if _, err := os.Stat(filepath.Join(projectPath, "project.yaml")); err == nil {
+		file = "project.yaml"
+	}
+	out, records, err := pdm.versionManager.Migrate(projectPath, file, target, false, dryRun)
+	if err == nil && !dryRun && len(records) > 0 {
+		last := records[len(records)-1]
+		pdm.recordHistoryLine(projectName, fmt.Sprintf("Migrate %s %s -> %s", file, displayVersion(records[0].From), last.To))
+	}
+	return out, records, err
+}
+
+// RollbackMigrations reverts the project's last applied migration steps
+// under the project lock.
+func (pdm *ProjectDataManager) RollbackMigrations(projectName string, steps int) ([]MigrationRecord, error) {
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return nil, err
+	}
+	defer pdm.ReleaseLock(projectName)
+
+	reverted, err := pdm.versionManager.Rollback(filepath.Join(pdm.dataDir, projectName), steps)
+	if len(reverted) > 0 {
+		last := reverted[len(reverted)-1]
+		pdm.recordHistoryLine(projectName, fmt.Sprintf("Roll back %s %s -> %s", last.File, reverted[0].To, displayVersion(last.From)))
+	}
+	return reverted, err
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrate_LegacyTasksAutoMigrateAndRollback(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "legacy")
+	if err := os.MkdirAll(projectPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tasksFile := filepath.Join(projectPath, "tasks.yaml")
+	original := "# hand-written\ntasks: []\narchived: false\n"
+	if err := os.WriteFile(tasksFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pdm := NewProjectDataManager(tmpDir, NewVersionManager("0.3.0-alpha.rc1"))
+	data, err := pdm.LoadProjectData("legacy")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if data.Version != "0.3.0-alpha.rc1" {
+		t.Fatalf("expected version stamped on load, got %q", data.Version)
+	}
+
+	backup, err := os.ReadFile(tasksFile + ".unversioned.bak")
+	if err != nil || string(backup) != original {
+		t.Fatalf("expected pre-migration backup, got %q (%v)", backup, err)
+	}
+
+	_, applied, err := pdm.versionManager.MigrationStatus(projectPath)
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if len(applied) != 2 || applied[0].To != "0.1.0" || applied[1].To != "0.3.0-alpha.rc1" {
+		t.Fatalf("unexpected journal: %+v", applied)
+	}
+
+	reverted, err := pdm.RollbackMigrations("legacy", 2)
+	if err != nil || len(reverted) != 2 {
+		t.Fatalf("rollback failed: %v (%d reverted)", err, len(reverted))
+	}
+	rolledBack, _ := os.ReadFile(tasksFile)
+	if strings.Contains(string(rolledBack), "quickplan-cli-version") {
+		t.Fatalf("expected version key removed after rollback:\n%s", rolledBack)
+	}
+	if !strings.Contains(string(rolledBack), "# hand-written") {
+		t.Fatalf("rollback lost comments:\n%s", rolledBack)
+	}
+}
+
+func TestMigrate_ProjectSchemaUpAndRollback(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	if _, _, err := pdm.MigrateProject(projectName, "", false); err != nil {
+		t.Fatalf("migrate up failed: %v", err)
+	}
+	v13, err := pdm.LoadProjectV11(projectName)
+	if err != nil || v13.SchemaVersion != schemaV13 {
+		t.Fatalf("expected schema 1.3 after migrate up, got %v (%v)", v13, err)
+	}
+
+	start := time.Now().Add(time.Hour)
+	v13.Tasks[0].Start = &start
+	if err := pdm.SaveProjectV11(projectName, v13); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if _, err := pdm.RollbackMigrations(projectName, 1); err == nil || !strings.Contains(err.Error(), "start") {
+		t.Fatalf("expected rollback to refuse dropping start, got %v", err)
+	}
+
+	v13, _ = pdm.LoadProjectV11(projectName)
+	v13.Tasks[0].Start = nil
+	v13.Tasks[0].Priority = "high"
+	if err := pdm.SaveProjectV11(projectName, v13); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if _, err := pdm.RollbackMigrations(projectName, 1); err != nil {
+		t.Fatalf("rollback to 1.2 failed: %v", err)
+	}
+	v12, err := pdm.LoadProjectV11(projectName)
+	if err != nil || v12.SchemaVersion != schemaV12 {
+		t.Fatalf("expected schema 1.2 after one rollback, got %v (%v)", v12, err)
+	}
+	if _, err := pdm.RollbackMigrations(projectName, 1); err == nil || !strings.Contains(err.Error(), "priority") {
+		t.Fatalf("expected rollback to refuse dropping priority, got %v", err)
+	}
+
+	v12.Tasks[0].Priority = ""
+	if err := pdm.SaveProjectV11(projectName, v12); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if _, err := pdm.RollbackMigrations(projectName, 1); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	v11, err := pdm.LoadProjectV11(projectName)
+	if err != nil || v11.SchemaVersion != schemaV11 {
+		t.Fatalf("expected schema 1.1 after rollback, got %v (%v)", v11, err)
+	}
+}
+
+func TestPlanMigrations_StopsAtOptInSteps(t *testing.T) {
+	vm := NewVersionManager("0.3.0")
+	if vm.hasPendingAutoMigrations("project.yaml", schemaV11) {
+		t.Fatal("1.1 -> 1.2 is opt-in and must not run on load")
+	}
+	if plan := vm.planMigrations("project.yaml", schemaV11, schemaV12, false); len(plan) != 1 {
+		t.Fatalf("expected one explicit step, got %+v", plan)
+	}
+	if plan := vm.planMigrations("project.yaml", schemaV11, vm.latestVersion("project.yaml"), false); len(plan) != 2 || plan[1].To != schemaV13 {
+		t.Fatalf("expected 1.1 -> 1.2 -> 1.3, got %+v", plan)
+	}
+	if !vm.hasPendingAutoMigrations("tasks.yaml", "0.2.0") {
+		t.Fatal("expected older tasks.yaml to be re-stamped on load")
+	}
+}
@@ -5,6 +5,7 @@ import "time"
 // ProjectData represents the YAML structure for a project's tasks
 type ProjectData struct {
 	Version  string    `yaml:"quickplan-cli-version"`
+	Revision int64     `yaml:"revision,omitempty"`
 	Tasks    []Task    `yaml:"tasks"`
 	Created  time.Time `yaml:"created"`
 	Modified time.Time `yaml:"modified"`
@@ -17,8 +18,10 @@ type ProjectConfig struct {
 	Name        string     `yaml:"name"`
 	Description string     `yaml:"description,omitempty"`
 	SyncSource  SyncSource `yaml:"sync_source,omitempty"`
-	Created     time.Time  `yaml:"created"`
-	Modified    time.Time  `yaml:"modified"`
+	// Settings overrides global config keys for this project (see config.go)
+	Settings map[string]string `yaml:"settings,omitempty"`
+	Created  time.Time         `yaml:"created"`
+	Modified time.Time         `yaml:"modified"`
 }
 
 // SyncSource defines where the project syncs from
@@ -50,6 +53,10 @@ type Task struct {
 	Behavior     AgentBehavior `yaml:"behavior,omitempty"`
 	ContextFiles []string      `yaml:"context_files,omitempty"`
 	WatchPath    string        `yaml:"watch_path,omitempty"`
+
+	// Lease held by the worker running the task, renewed while it lives
+	LeaseOwner     string     `yaml:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time `yaml:"lease_expires_at,omitempty"`
 }
 
 // Lock represents the lock file metadata
@@ -57,6 +64,7 @@ type Lock struct {
 	PID       int       `yaml:"pid"`
 	Host      string    `yaml:"host"`
 	CreatedAt time.Time `yaml:"created_at"`
+	RenewedAt time.Time `yaml:"renewed_at,omitempty"`
 	TTL       int       `yaml:"ttl_seconds"`
 }
 
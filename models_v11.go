@@ -2,14 +2,17 @@ package main
 
 import "time"
 
-// ProjectV11 represents the Schema v1.1 project structure
+// ProjectV11 represents the Schema v1.1 project structure. Schemas 1.2 and
+// 1.3 share it and only add optional task keys (see taskSchemaFields).
 type ProjectV11 struct {
 	SchemaVersion string          `yaml:"schema_version"`
+	Revision      int64           `yaml:"revision,omitempty"`
+	Layout        string          `yaml:"layout,omitempty"` // "" or single-file, or task-files (see task_files.go)
 	Project       ProjectMeta     `yaml:"project"`
 	Lock          LockConfig      `yaml:"lock"`
 	Agents        []AgentMeta     `yaml:"agents,omitempty"`
 	Tasks         []TaskV11       `yaml:"tasks"`
-	Events        []Event         `yaml:"events"`
+	Events        []Event         `yaml:"events,omitempty"` // pre-event-store history, see MigrateEmbeddedEvents
 	Registry      *RegistryConfig `yaml:"registry,omitempty"`
 }
 
@@ -45,6 +48,32 @@ type TaskV11 struct {
 	Attempts    int           `yaml:"attempts"`
 	LastError   string        `yaml:"last_error,omitempty"`
 	UpdatedAt   time.Time     `yaml:"updated_at"`
+
+	// Lease held by the worker running the task, renewed while it lives
+	LeaseOwner     string     `yaml:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time `yaml:"lease_expires_at,omitempty"`
+
+	// Schema 1.2 task metadata
+	Priority    string     `yaml:"priority,omitempty"` // low, medium, high, urgent
+	Due         *time.Time `yaml:"due,omitempty"`
+	Labels      []string   `yaml:"labels,omitempty"`
+	Estimate    string     `yaml:"estimate,omitempty"`    // e.g. 90m, 4h, 3d, 2w
+	Description string     `yaml:"description,omitempty"` // markdown
+
+	// Schema 1.3 scheduling and structure
+	Start      *time.Time  `yaml:"start,omitempty"`       // workers do not claim the task before this
+	Parent     string      `yaml:"parent,omitempty"`      // ID of the task this one is a subtask of
+	Recurrence *Recurrence `yaml:"recurrence,omitempty"`  // repeat the task on a schedule
+	RecursFrom string      `yaml:"recurs_from,omitempty"` // instance this one was cloned from
+
+	OverdueNotified *time.Time `yaml:"overdue_notified,omitempty"` // due date last reported as passed
+}
+
+// Recurrence repeats a task on a cron schedule: once an instance is DONE,
+// the daemon adds the next one, starting at the next occurrence.
+type Recurrence struct {
+	Schedule string `yaml:"schedule"`           // cron expression, e.g. "0 9 * * MON"
+	Timezone string `yaml:"timezone,omitempty"` // IANA name; local time if empty
 }
 
 type WatchConfig struct {
@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"github.com/trstoyan/quickplan/internal/atomicfile"
 )
 
 const (
@@ -33,10 +35,10 @@ func InitProjectKey(projectDir string, userPub *ecdh.PublicKey, userPriv *ecdh.P
 		return nil, err
 	}
 
-	if err := os.WriteFile(filepath.Join(cryptoPath, ProjectKeyFile), []byte(base64.StdEncoding.EncodeToString(wrapped)), 0600); err != nil {
+	if err := atomicfile.WriteFile(filepath.Join(cryptoPath, ProjectKeyFile), []byte(base64.StdEncoding.EncodeToString(wrapped)), 0600); err != nil {
 		return nil, err
 	}
-	if err := os.WriteFile(filepath.Join(cryptoPath, ProjectKeyNonceFile), []byte(base64.StdEncoding.EncodeToString(nonce)), 0600); err != nil {
+	if err := atomicfile.WriteFile(filepath.Join(cryptoPath, ProjectKeyNonceFile), []byte(base64.StdEncoding.EncodeToString(nonce)), 0600); err != nil {
 		return nil, err
 	}
 
@@ -23,6 +23,18 @@ func TestKeystore(t *testing.T) {
 		t.Fatalf("InitProjectKey failed: %v", err)
 	}
 
+	entries, _ := os.ReadDir(filepath.Join(tmpDir, CryptoDir))
+	if len(entries) != 2 {
+		t.Fatalf("expected only the key and nonce files, got %d entries", len(entries))
+	}
+	info, err := os.Stat(filepath.Join(tmpDir, CryptoDir, ProjectKeyFile))
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the wrapped key to be private, got %v", info.Mode().Perm())
+	}
+
 	// 2. Get
 	key2, err := GetProjectKey(tmpDir, userPriv, userPub)
 	if err != nil {
@@ -4,10 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
-	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -18,6 +16,10 @@ import (
 type ProjectDataManager struct {
 	dataDir        string
 	versionManager *VersionManager
+	lockTimeout    time.Duration
+
+	locksMu sync.Mutex
+	locks   map[string]*heldLock
 }
 
 // NewProjectDataManager creates a new project data manager
@@ -25,6 +27,8 @@ func NewProjectDataManager(dataDir string, versionManager *VersionManager) *Proj
 	return &ProjectDataManager{
 		dataDir:        dataDir,
 		versionManager: versionManager,
+		lockTimeout:    defaultLockTimeout,
+		locks:          make(map[string]*heldLock),
 	}
 }
 
@@ -59,50 +63,26 @@ func (pdm *ProjectDataManager) AppendEvent(projectName string, event Event) erro
 	projectPath := filepath.Join(pdm.dataDir, projectName)
 	v11File := filepath.Join(projectPath, "project.yaml")
 
-	// Try v1.1 embedding first
+	// v1.1 projects append to the event store without touching project.yaml
 	if _, err := os.Stat(v11File); err == nil {
-		v11, err := pdm.LoadProjectV11(projectName)
+		store := pdm.eventStore(projectName)
+		lastSeq, err := store.LastSeq()
 		if err != nil {
-			return fmt.Errorf("failed to load v1.1 for event append: %w", err)
+			return err
 		}
-		v11.Events = append(v11.Events, event)
-		return pdm.SaveProjectV11(projectName, v11)
-	}
-
-	// Fallback to events.yaml sidecar
-	eventsPath := pdm.getEventsPath(projectName)
-
-	// Ensure project directory exists
-	if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-		return fmt.Errorf("project '%s' does not exist", projectName)
-	}
-
-	// Load existing events or create new log
-	var eventLog EventLog
-	data, err := os.ReadFile(eventsPath)
-	if err == nil {
-		if err := yaml.Unmarshal(data, &eventLog); err != nil {
-			// If corrupt, we'll just start fresh or return error?
-			// Protocol says append-only, so let's try to preserve if possible.
-			return fmt.Errorf("failed to parse events file: %w", err)
+		if lastSeq == 0 {
+			// First event in the store: move embedded history over first so
+			// sequence numbers continue from it.
+			if _, err := pdm.MigrateEmbeddedEvents(projectName); err != nil {
+				return fmt.Errorf("failed to migrate embedded events: %w", err)
+			}
 		}
-	} else if !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read events file: %w", err)
-	}
-
-	if eventLog.SchemaVersion == "" {
-		eventLog.SchemaVersion = "events-0.1"
-	}
-
-	eventLog.Events = append(eventLog.Events, event)
-
-	// Marshal and write
-	out, err := yaml.Marshal(eventLog)
-	if err != nil {
-		return fmt.Errorf("failed to marshal events: %w", err)
+		_, err = store.Append(event)
+		return err
 	}
 
-	return os.WriteFile(eventsPath, out, 0644)
+	// Fallback to events.yaml sidecar
+	return pdm.appendLegacyEvents(projectName, event)
 }
 
 // LoadEvents loads the events from the events.yaml sidecar
@@ -124,231 +104,148 @@ func (pdm *ProjectDataManager) LoadEvents(projectName string) (*EventLog, error)
 	return &eventLog, nil
 }
 
-// AcquireLock attempts to acquire a lock for the project
-func (pdm *ProjectDataManager) AcquireLock(projectName string, ttl int) error {
-	lockPath := pdm.getLockPath(projectName)
-
-	// Try to create the lock file with O_EXCL
-	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Project directory might not exist yet (e.g. during creation)
-			return nil
-		}
-		if os.IsExist(err) {
-			// Lock already exists, check if it's stale
-			stale, existingLock, err := pdm.IsLockStale(projectName)
-			if err != nil {
-				return fmt.Errorf("failed to check if lock is stale: %w", err)
-			}
-			if stale {
-				fmt.Fprintf(os.Stderr, "Warning: stale lock detected from pid %d on host %s, overriding...\n", existingLock.PID, existingLock.Host)
-				// Remove stale lock and try again once
-				os.Remove(lockPath)
-				return pdm.AcquireLock(projectName, ttl)
-			}
-			return fmt.Errorf("project is locked by pid %d on host %s (created at %s)", existingLock.PID, existingLock.Host, existingLock.CreatedAt.Format(time.RFC3339))
-		}
-		return fmt.Errorf("failed to create lock file: %w", err)
-	}
-	defer f.Close()
-
-	host, _ := os.Hostname()
-	lock := Lock{
-		PID:       os.Getpid(),
-		Host:      host,
-		CreatedAt: time.Now(),
-		TTL:       ttl,
-	}
+// LoadProjectV11 loads a schema v1.1 project file
+func (pdm *ProjectDataManager) LoadProjectV11(projectName string) (*ProjectV11, error) {
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	v11File := filepath.Join(projectPath, "project.yaml")
 
-	data, err := yaml.Marshal(lock)
+	data, err := os.ReadFile(v11File)
 	if err != nil {
-		return fmt.Errorf("failed to marshal lock data: %w", err)
-	}
-
-	if _, err := f.Write(data); err != nil {
-		return fmt.Errorf("failed to write lock data: %w", err)
-	}
-
-	return nil
-}
-
-// ReleaseLock removes the lock file
-func (pdm *ProjectDataManager) ReleaseLock(projectName string) error {
-	lockPath := pdm.getLockPath(projectName)
-	err := os.Remove(lockPath)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to release lock: %w", err)
+		return nil, err
 	}
-	return nil
-}
 
-// IsLockStale checks if a lock is stale
-func (pdm *ProjectDataManager) IsLockStale(projectName string) (bool, *Lock, error) {
-	lockPath := pdm.getLockPath(projectName)
-	data, err := os.ReadFile(lockPath)
-	if err != nil {
-		return false, nil, err
+	var project ProjectV11
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		// Fall back to the last-good copy kept by the durable write path.
+		backup, backupErr := loadYAMLBackup(v11File, &project)
+		if backupErr != nil {
+			return nil, fmt.Errorf("failed to parse project.yaml: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: project.yaml is unreadable (%v), using last-good copy %s\n", err, backup)
 	}
 
-	var lock Lock
-	if err := yaml.Unmarshal(data, &lock); err != nil {
-		return true, nil, nil // Corrupt lock is stale
+	if !isSupportedSchemaVersion(project.SchemaVersion) {
+		return nil, fmt.Errorf("unsupported schema version: %s", project.SchemaVersion)
 	}
 
-	// 1. Check TTL
-	if time.Now().After(lock.CreatedAt.Add(time.Duration(lock.TTL) * time.Second)) {
-		return true, &lock, nil
+	if pdm.versionManager.hasPendingAutoMigrations("project.yaml", project.SchemaVersion) {
+		if _, _, err := pdm.versionManager.Migrate(projectPath, "project.yaml", "", true, false); err != nil {
+			return nil, fmt.Errorf("failed to migrate project.yaml: %w", err)
+		}
+		return pdm.LoadProjectV11(projectName)
 	}
 
-	// 2. Best-effort PID check if on same host
-	host, _ := os.Hostname()
-	if lock.Host == host {
-		process, err := os.FindProcess(lock.PID)
-		if err != nil {
-			return true, &lock, nil
-		}
-		// On Unix, FindProcess always succeeds. Use signal 0 to check existence.
-		if runtime.GOOS != "windows" {
-			err = process.Signal(syscall.Signal(0))
-			if err != nil {
-				return true, &lock, nil
-			}
+	if project.Layout == layoutTaskFiles {
+		if err := loadTaskFiles(projectPath, &project); err != nil {
+			return nil, err
 		}
 	}
 
-	return false, &lock, nil
+	return &project, nil
 }
 
-// LoadProjectV11 loads a schema v1.1 project file
-func (pdm *ProjectDataManager) LoadProjectV11(projectName string) (*ProjectV11, error) {
-	projectPath := filepath.Join(pdm.dataDir, projectName)
-	v11File := filepath.Join(projectPath, "project.yaml")
-
-	data, err := os.ReadFile(v11File)
+// loadYAMLBackup decodes the last-good copy of path into out and returns the
+// backup path it read.
+func loadYAMLBackup(path string, out interface{}) (string, error) {
+	backupPath := path + backupSuffix
+	data, err := os.ReadFile(backupPath)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	var project ProjectV11
-	if err := yaml.Unmarshal(data, &project); err != nil {
-		return nil, fmt.Errorf("failed to parse project.yaml: %w", err)
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return "", err
 	}
+	return backupPath, nil
+}
 
-	if project.SchemaVersion != "1.1" {
-		return nil, fmt.Errorf("unsupported schema version: %s", project.SchemaVersion)
-	}
+// SaveProjectV11 saves a schema v1.1 project file unconditionally,
+// bumping its revision.
+func (pdm *ProjectDataManager) SaveProjectV11(projectName string, project *ProjectV11) error {
+	return pdm.saveProjectV11(projectName, project, false)
+}
 
-	return &project, nil
+// CompareAndSwapProjectV11 saves a schema v1.1 project file only if the stored
+// revision still equals project.Revision, i.e. nobody saved since it was
+// loaded. Otherwise it returns a *RevisionConflictError.
+func (pdm *ProjectDataManager) CompareAndSwapProjectV11(projectName string, project *ProjectV11) error {
+	return pdm.saveProjectV11(projectName, project, true)
 }
 
-// SaveProjectV11 saves a schema v1.1 project file
-func (pdm *ProjectDataManager) SaveProjectV11(projectName string, project *ProjectV11) error {
+func (pdm *ProjectDataManager) saveProjectV11(projectName string, project *ProjectV11, checkRevision bool) error {
+	rolledUp := rollUpParents(project)
 	if err := ValidateProjectV11(project); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	if err := pdm.AcquireLock(projectName, 300); err != nil {
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
 		return err
 	}
 	defer pdm.ReleaseLock(projectName)
 
-	projectPath := filepath.Join(pdm.dataDir, projectName)
-	v11File := filepath.Join(projectPath, "project.yaml")
-
-	project.Project.UpdatedAt = time.Now()
-
-	data, err := yaml.Marshal(project)
-	if err != nil {
-		return fmt.Errorf("failed to marshal project v1.1: %w", err)
+	before, history := pdm.historySnapshot(projectName)
+	if err := pdm.writeProjectV11Locked(projectName, project, checkRevision); err != nil {
+		return err
 	}
-
-	return os.WriteFile(v11File, data, 0644)
+	if len(rolledUp) > 0 {
+		if _, err := pdm.eventStore(projectName).Append(rolledUp...); err != nil {
+			return err
+		}
+	}
+	if history {
+		pdm.recordHistory(projectName, before, rolledUp)
+	}
+	return nil
 }
 
-// ValidateProjectV11 enforces schema v1.1 rules and invariants.
-func ValidateProjectV11(project *ProjectV11) error {
-	if project.SchemaVersion != "1.1" {
-		return fmt.Errorf("unsupported schema version: %s (expected 1.1)", project.SchemaVersion)
+// writeProjectV11Locked writes project.yaml, or the task files of a
+// task-files project; the caller must hold the project lock.
+func (pdm *ProjectDataManager) writeProjectV11Locked(projectName string, project *ProjectV11, checkRevision bool) error {
+	if project.Layout == layoutTaskFiles {
+		return pdm.writeTaskFilesLocked(projectName, project, checkRevision)
 	}
 
-	taskIDs := make(map[string]bool)
-	for _, task := range project.Tasks {
-		// 1. Unique task ids
-		if task.ID == "" {
-			return fmt.Errorf("task ID cannot be empty")
-		}
-		if taskIDs[task.ID] {
-			return fmt.Errorf("duplicate task ID: %s", task.ID)
-		}
-		taskIDs[task.ID] = true
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	v11File := filepath.Join(projectPath, "project.yaml")
 
-		// 2. Valid status enum
-		if !isValidStatus(task.Status) {
-			return fmt.Errorf("invalid status for task %s: %s", task.ID, task.Status)
-		}
+	stored, err := storedRevision(v11File)
+	if err != nil {
+		return err
 	}
-
-	// 3. depends_on references exist and no cycles
-	for _, task := range project.Tasks {
-		for _, depID := range task.DependsOn {
-			if !taskIDs[depID] {
-				return fmt.Errorf("task %s depends on non-existent task %s", task.ID, depID)
-			}
-		}
+	if checkRevision && stored != project.Revision {
+		return &RevisionConflictError{Project: projectName, Expected: project.Revision, Actual: stored}
 	}
 
-	if hasDependencyCycles(project.Tasks) {
-		return fmt.Errorf("dependency cycle detected")
-	}
+	prevRevision, prevUpdatedAt := project.Revision, project.Project.UpdatedAt
+	project.Revision = nextRevision(stored, project.Revision)
+	project.Project.UpdatedAt = time.Now()
 
+	data, err := yaml.Marshal(project)
+	if err == nil {
+		err = writeFileAtomicWithBackup(v11File, data, 0644)
+	}
+	if err != nil {
+		project.Revision, project.Project.UpdatedAt = prevRevision, prevUpdatedAt
+		return fmt.Errorf("failed to save project v1.1: %w", err)
+	}
 	return nil
 }
 
-func isValidStatus(status string) bool {
-	switch status {
-	case "TODO", "PENDING", "BLOCKED", "IN_PROGRESS", "DONE", "FAILED", "RETRYING", "CANCELLED":
-		return true
-	}
-	return false
+// ValidateProjectV11 enforces schema v1.1 and later rules and invariants,
+// returning the first error. DiagnoseProjectV11 reports all of them with
+// source positions.
+func ValidateProjectV11(project *ProjectV11) error {
+	return firstError(checkProjectV11(project))
 }
 
-func hasDependencyCycles(tasks []TaskV11) bool {
-	visited := make(map[string]bool)
-	recStack := make(map[string]bool)
-	taskMap := make(map[string]TaskV11)
-
-	for _, task := range tasks {
-		taskMap[task.ID] = task
-	}
-
-	var check func(id string) bool
-	check = func(id string) bool {
-		visited[id] = true
-		recStack[id] = true
-
-		for _, depID := range taskMap[id].DependsOn {
-			if !visited[depID] {
-				if check(depID) {
-					return true
-				}
-			} else if recStack[depID] {
-				return true
-			}
-		}
+// taskStatuses lists every status a task may have.
+var taskStatuses = []string{"TODO", "PENDING", "BLOCKED", "IN_PROGRESS", "DONE", "FAILED", "RETRYING", "CANCELLED"}
 
-		recStack[id] = false
-		return false
-	}
-
-	for _, task := range tasks {
-		if !visited[task.ID] {
-			if check(task.ID) {
-				return true
-			}
+func isValidStatus(status string) bool {
+	for _, s := range taskStatuses {
+		if s == status {
+			return true
 		}
 	}
-
 	return false
 }
 
@@ -357,27 +254,7 @@ func (pdm *ProjectDataManager) GetTaskViews(projectName string) ([]TaskView, boo
 	// Try v1.1 first
 	v11, err := pdm.LoadProjectV11(projectName)
 	if err == nil {
-		views := make([]TaskView, len(v11.Tasks))
-		for i, t := range v11.Tasks {
-			watchPath := ""
-			if len(t.Watch.Paths) > 0 {
-				watchPath = t.Watch.Paths[0]
-			}
-
-			views[i] = TaskView{
-				ID:            t.ID,
-				Text:          t.Name,
-				Status:        t.Status,
-				AssignedTo:    t.AssignedTo,
-				DependsOn:     t.DependsOn,
-				WatchPath:     watchPath,
-				WatchPaths:    append([]string{}, t.Watch.Paths...),
-				RequiresFiles: append([]string{}, t.Watch.RequiresFiles...),
-				Behavior:      t.Behavior,
-				IsV11:         true,
-			}
-		}
-		return views, true, nil
+		return taskViewsFromV11(v11), true, nil
 	}
 
 	// Fallback to legacy
@@ -385,7 +262,52 @@ func (pdm *ProjectDataManager) GetTaskViews(projectName string) ([]TaskView, boo
 	if err != nil {
 		return nil, false, err
 	}
+	return taskViewsFromLegacy(legacy), false, nil
+}
 
+// taskViewsFromV11 projects v1.1 tasks into the unified TaskView shape.
+func taskViewsFromV11(v11 *ProjectV11) []TaskView {
+	views := make([]TaskView, len(v11.Tasks))
+	for i, t := range v11.Tasks {
+		watchPath := ""
+		if len(t.Watch.Paths) > 0 {
+			watchPath = t.Watch.Paths[0]
+		}
+
+		views[i] = TaskView{
+			ID:             t.ID,
+			Text:           t.Name,
+			Status:         t.Status,
+			AssignedTo:     t.AssignedTo,
+			DependsOn:      t.DependsOn,
+			WatchPath:      watchPath,
+			WatchPaths:     append([]string{}, t.Watch.Paths...),
+			RequiresFiles:  append([]string{}, t.Watch.RequiresFiles...),
+			Behavior:       t.Behavior,
+			IsV11:          true,
+			UpdatedAt:      t.UpdatedAt,
+			LeaseOwner:     t.LeaseOwner,
+			LeaseExpiresAt: t.LeaseExpiresAt,
+			Priority:       t.Priority,
+			Due:            t.Due,
+			Start:          t.Start,
+			Labels:         t.Labels,
+			Estimate:       t.Estimate,
+			Description:    t.Description,
+			Parent:         t.Parent,
+		}
+	}
+	children := subtaskIndex(v11.Tasks)
+	for i := range views {
+		for _, k := range children[views[i].ID] {
+			views[i].Subtasks = append(views[i].Subtasks, v11.Tasks[k].ID)
+		}
+	}
+	return views
+}
+
+// taskViewsFromLegacy projects legacy tasks into the unified TaskView shape.
+func taskViewsFromLegacy(legacy *ProjectData) []TaskView {
 	views := make([]TaskView, len(legacy.Tasks))
 	for i, t := range legacy.Tasks {
 		// Map int deps to strings
@@ -395,19 +317,22 @@ func (pdm *ProjectDataManager) GetTaskViews(projectName string) ([]TaskView, boo
 		}
 
 		views[i] = TaskView{
-			ID:            fmt.Sprintf("t-%d", t.ID),
-			Text:          t.Text,
-			Status:        GetTaskStatus(t),
-			AssignedTo:    t.AssignedTo,
-			DependsOn:     deps,
-			WatchPath:     t.WatchPath,
-			WatchPaths:    []string{t.WatchPath},
-			RequiresFiles: nil,
-			Behavior:      t.Behavior,
-			IsV11:         false,
+			ID:             fmt.Sprintf("t-%d", t.ID),
+			Text:           t.Text,
+			Status:         GetTaskStatus(t),
+			AssignedTo:     t.AssignedTo,
+			DependsOn:      deps,
+			WatchPath:      t.WatchPath,
+			WatchPaths:     []string{t.WatchPath},
+			RequiresFiles:  nil,
+			Behavior:       t.Behavior,
+			IsV11:          false,
+			UpdatedAt:      t.Created,
+			LeaseOwner:     t.LeaseOwner,
+			LeaseExpiresAt: t.LeaseExpiresAt,
 		}
 	}
-	return views, false, nil
+	return views
 }
 
 // LoadProjectData loads project data from disk with version migration
@@ -435,7 +360,11 @@ func (pdm *ProjectDataManager) LoadProjectData(projectName string) (*ProjectData
 
 	// Parse YAML
 	if err := yaml.Unmarshal(data, &projectData); err != nil {
-		return nil, fmt.Errorf("failed to parse tasks file: %w", err)
+		backup, backupErr := loadYAMLBackup(tasksFile, &projectData)
+		if backupErr != nil {
+			return nil, fmt.Errorf("failed to parse tasks file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: tasks.yaml is unreadable (%v), using last-good copy %s\n", err, backup)
 	}
 
 	// Validate version compatibility
@@ -464,32 +393,94 @@ func (pdm *ProjectDataManager) LoadProjectData(projectName string) (*ProjectData
 	return &projectData, nil
 }
 
-// SaveProjectData saves project data to disk with version tracking
+// SaveProjectData saves project data to disk with version tracking,
+// bumping its revision unconditionally.
 func (pdm *ProjectDataManager) SaveProjectData(projectName string, projectData *ProjectData) error {
-	if err := pdm.AcquireLock(projectName, 300); err != nil {
+	return pdm.saveProjectData(projectName, projectData, false)
+}
+
+// CompareAndSwapProjectData saves legacy project data only if the stored
+// revision still equals projectData.Revision. Otherwise it returns a
+// *RevisionConflictError.
+func (pdm *ProjectDataManager) CompareAndSwapProjectData(projectName string, projectData *ProjectData) error {
+	return pdm.saveProjectData(projectName, projectData, true)
+}
+
+func (pdm *ProjectDataManager) saveProjectData(projectName string, projectData *ProjectData, checkRevision bool) error {
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return err
+	}
+	defer pdm.ReleaseLock(projectName)
+
+	before, history := pdm.historySnapshot(projectName)
+	if err := pdm.writeProjectDataLocked(projectName, projectData, checkRevision); err != nil {
 		return err
 	}
+	if history {
+		pdm.recordHistory(projectName, before, nil)
+	}
+	return nil
+}
+
+// ToggleArchived archives the project, or unarchives it if it already is,
+// and returns the new state. The flag lives in tasks.yaml whatever schema the
+// tasks use.
+func (pdm *ProjectDataManager) ToggleArchived(projectName string) (bool, error) {
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return false, err
+	}
 	defer pdm.ReleaseLock(projectName)
 
+	projectData, err := pdm.LoadProjectData(projectName)
+	if err != nil {
+		return false, fmt.Errorf("failed to load project data: %w", err)
+	}
+	projectData.Archived = !projectData.Archived
+	if err := pdm.writeProjectDataLocked(projectName, projectData, false); err != nil {
+		return false, fmt.Errorf("failed to save project data: %w", err)
+	}
+	if projectData.Archived {
+		pdm.recordHistoryLine(projectName, "Archive the project")
+	} else {
+		pdm.recordHistoryLine(projectName, "Unarchive the project")
+	}
+	return projectData.Archived, nil
+}
+
+// writeProjectDataLocked writes tasks.yaml; the caller must hold the project lock.
+func (pdm *ProjectDataManager) writeProjectDataLocked(projectName string, projectData *ProjectData, checkRevision bool) error {
 	projectPath := filepath.Join(pdm.dataDir, projectName)
 	tasksFile := filepath.Join(projectPath, "tasks.yaml")
 
+	stored, err := storedRevision(tasksFile)
+	if err != nil {
+		return err
+	}
+	if checkRevision && stored != projectData.Revision {
+		return &RevisionConflictError{Project: projectName, Expected: projectData.Revision, Actual: stored}
+	}
+
 	// Ensure version is set
 	if projectData.Version == "" {
 		projectData.Version = pdm.versionManager.currentVersion
 	}
 
+	prevRevision, prevModified := projectData.Revision, projectData.Modified
+	projectData.Revision = nextRevision(stored, projectData.Revision)
+
 	// Update modified timestamp
 	projectData.Modified = time.Now()
 
 	// Marshal to YAML
 	data, err := yaml.Marshal(projectData)
 	if err != nil {
+		projectData.Revision, projectData.Modified = prevRevision, prevModified
 		return fmt.Errorf("failed to marshal tasks: %w", err)
 	}
 
 	// Write to file
-	if err := os.WriteFile(tasksFile, data, 0644); err != nil {
+	if err := writeFileAtomicWithBackup(tasksFile, data, 0644); err != nil {
+		projectData.Revision, projectData.Modified = prevRevision, prevModified
 		return fmt.Errorf("failed to write tasks file: %w", err)
 	}
 
@@ -559,7 +550,13 @@ func (pdm *ProjectDataManager) LoadProjectConfig(projectName string) (*ProjectCo
 
 // SaveProjectConfig saves project configuration to project.yml
 func (pdm *ProjectDataManager) SaveProjectConfig(projectName string, config *ProjectConfig) error {
-	if err := pdm.AcquireLock(projectName, 300); err != nil {
+	return pdm.saveProjectConfig(projectName, config, "Update project settings")
+}
+
+// saveProjectConfig saves project.yml and, for a project with git history,
+// commits it as historyLine. An empty historyLine skips the commit.
+func (pdm *ProjectDataManager) saveProjectConfig(projectName string, config *ProjectConfig, historyLine string) error {
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
 		return err
 	}
 	defer pdm.ReleaseLock(projectName)
@@ -575,116 +572,33 @@ func (pdm *ProjectDataManager) SaveProjectConfig(projectName string, config *Pro
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configFile, data, 0644); err != nil {
+	if err := writeFileAtomic(configFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	if historyLine != "" {
+		pdm.recordHistoryLine(projectName, historyLine)
+	}
 	return nil
 }
 
 // UpdateTaskStatus updates the status and assigned agent of a specific task.
+// The transition is validated and saved in a single transaction.
 func (pdm *ProjectDataManager) UpdateTaskStatus(projectName, taskID, status, agentID string) error {
-	views, isV11, err := pdm.GetTaskViews(projectName)
-	if err != nil {
+	return pdm.Update(projectName, func(tx *ProjectTx) error {
+		_, err := tx.SetStatus(taskID, status, agentID)
 		return err
-	}
-	statusByID := buildStatusIndex(views)
+	})
+}
 
-	var targetTask *TaskView
+// validateTaskStatusUpdate checks that taskID exists in views and may move to status.
+func validateTaskStatusUpdate(views []TaskView, projectName, taskID, status string) error {
 	for i := range views {
 		if views[i].ID == taskID {
-			targetTask = &views[i]
-			break
+			return validateTaskStatusTransition(views[i], status, buildStatusIndex(views))
 		}
 	}
-	if targetTask == nil {
-		return fmt.Errorf("task %s not found in project %s", taskID, projectName)
-	}
-
-	if err := validateTaskStatusTransition(*targetTask, status, statusByID); err != nil {
-		return err
-	}
-	actor := strings.TrimSpace(agentID)
-	if actor == "" {
-		actor = "system"
-	}
-
-	// Try v1.1 first
-	if isV11 {
-		v11, err := pdm.LoadProjectV11(projectName)
-		if err != nil {
-			return err
-		}
-		for i := range v11.Tasks {
-			if v11.Tasks[i].ID == taskID {
-				prevStatus := v11.Tasks[i].Status
-				v11.Tasks[i].Status = status
-				if agentID != "" {
-					v11.Tasks[i].AssignedTo = agentID
-				}
-				v11.Tasks[i].UpdatedAt = time.Now()
-
-				v11.Events = append(v11.Events, Event{
-					Timestamp:  time.Now(),
-					Type:       "TASK_STATUS_CHANGED",
-					Actor:      actor,
-					TaskID:     taskID,
-					PrevStatus: prevStatus,
-					NextStatus: status,
-					Message:    fmt.Sprintf("Status updated to %s", status),
-				})
-				return pdm.SaveProjectV11(projectName, v11)
-			}
-		}
-		return fmt.Errorf("task %s not found in project %s", taskID, projectName)
-	}
-
-	// Fallback to legacy
-	projectData, err := pdm.LoadProjectData(projectName)
-	if err != nil {
-		return err
-	}
-
-	id, err := strconv.Atoi(strings.TrimPrefix(taskID, "t-"))
-	if err != nil {
-		return fmt.Errorf("invalid legacy task ID: %s", taskID)
-	}
-
-	found := false
-	for i := range projectData.Tasks {
-		if projectData.Tasks[i].ID == id {
-			prevStatus := GetTaskStatus(projectData.Tasks[i])
-			projectData.Tasks[i].Status = status
-			projectData.Tasks[i].Done = (status == "DONE")
-			if agentID != "" {
-				projectData.Tasks[i].AssignedTo = agentID
-			}
-			if projectData.Tasks[i].Done {
-				now := time.Now()
-				projectData.Tasks[i].Completed = &now
-			} else {
-				projectData.Tasks[i].Completed = nil
-			}
-
-			pdm.AppendEvent(projectName, Event{
-				Timestamp:  time.Now(),
-				Type:       "TASK_STATUS_CHANGED",
-				Actor:      actor,
-				TaskID:     taskID,
-				PrevStatus: prevStatus,
-				NextStatus: status,
-				Message:    fmt.Sprintf("Status updated to %s", status),
-			})
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		return fmt.Errorf("task %s not found in project %s", taskID, projectName)
-	}
-
-	return pdm.SaveProjectData(projectName, projectData)
+	return fmt.Errorf("task %s not found in project %s", taskID, projectName)
 }
 
 // ListProjects returns a list of project names, optionally including archived ones
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectTx is the mutable view of a project handed to Update callbacks.
+// It wraps either a v1.1 project or legacy project data, never both.
+type ProjectTx struct {
+	projectName string
+	v11         *ProjectV11
+	legacy      *ProjectData
+	events      []Event
+	modified    bool
+	quiet       bool // not recorded in git history
+}
+
+// IsV11 reports whether the transaction operates on a schema v1.1 project.
+func (tx *ProjectTx) IsV11() bool {
+	return tx.v11 != nil
+}
+
+// V11 returns the v1.1 project being edited, or nil for legacy projects.
+// Direct edits must be followed by MarkModified.
+func (tx *ProjectTx) V11() *ProjectV11 {
+	return tx.v11
+}
+
+// Legacy returns the legacy project data being edited, or nil for v1.1
+// projects. Direct edits must be followed by MarkModified.
+func (tx *ProjectTx) Legacy() *ProjectData {
+	return tx.legacy
+}
+
+// MarkModified flags the project to be saved on commit.
+func (tx *ProjectTx) MarkModified() {
+	tx.modified = true
+}
+
+// Views returns task views reflecting every edit made so far in the transaction.
+func (tx *ProjectTx) Views() []TaskView {
+	if tx.v11 != nil {
+		return taskViewsFromV11(tx.v11)
+	}
+	return taskViewsFromLegacy(tx.legacy)
+}
+
+// AppendEvent records an event to be persisted with the transaction.
+func (tx *ProjectTx) AppendEvent(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	tx.events = append(tx.events, event)
+}
+
+// SetStatus moves a task to status after validating the transition against
+// the in-transaction state, and records a TASK_STATUS_CHANGED event. It
+// returns the previous status.
+func (tx *ProjectTx) SetStatus(taskID, status, agentID string) (string, error) {
+	views := tx.Views()
+	for _, view := range views {
+		if view.ID == taskID && len(view.Subtasks) > 0 {
+			return "", fmt.Errorf("task %s has subtasks; its status follows theirs", taskID)
+		}
+	}
+	if err := validateTaskStatusUpdate(views, tx.projectName, taskID, status); err != nil {
+		return "", err
+	}
+
+	actor := strings.TrimSpace(agentID)
+	if actor == "" {
+		actor = "system"
+	}
+
+	prevStatus, err := tx.applyStatus(taskID, status, agentID)
+	if err != nil {
+		return "", err
+	}
+
+	tx.AppendEvent(Event{
+		Type:       "TASK_STATUS_CHANGED",
+		Actor:      actor,
+		TaskID:     taskID,
+		PrevStatus: prevStatus,
+		NextStatus: status,
+		Message:    fmt.Sprintf("Status updated to %s", status),
+	})
+	return prevStatus, nil
+}
+
+// applyStatus writes status onto a task without validating the transition or
+// recording an event, and returns the previous status.
+func (tx *ProjectTx) applyStatus(taskID, status, agentID string) (string, error) {
+	prevStatus := ""
+	now := time.Now()
+	if tx.v11 != nil {
+		for i := range tx.v11.Tasks {
+			if tx.v11.Tasks[i].ID != taskID {
+				continue
+			}
+			prevStatus = tx.v11.Tasks[i].Status
+			tx.v11.Tasks[i].Status = status
+			if agentID != "" {
+				tx.v11.Tasks[i].AssignedTo = agentID
+			}
+			tx.v11.Tasks[i].UpdatedAt = now
+			if status != "IN_PROGRESS" {
+				tx.v11.Tasks[i].LeaseOwner = ""
+				tx.v11.Tasks[i].LeaseExpiresAt = nil
+			}
+			break
+		}
+	} else {
+		id, err := strconv.Atoi(strings.TrimPrefix(taskID, "t-"))
+		if err != nil {
+			return "", fmt.Errorf("invalid legacy task ID: %s", taskID)
+		}
+		for i := range tx.legacy.Tasks {
+			if tx.legacy.Tasks[i].ID != id {
+				continue
+			}
+			prevStatus = GetTaskStatus(tx.legacy.Tasks[i])
+			tx.legacy.Tasks[i].Status = status
+			tx.legacy.Tasks[i].Done = (status == "DONE")
+			if agentID != "" {
+				tx.legacy.Tasks[i].AssignedTo = agentID
+			}
+			if tx.legacy.Tasks[i].Done {
+				tx.legacy.Tasks[i].Completed = &now
+			} else {
+				tx.legacy.Tasks[i].Completed = nil
+			}
+			if status != "IN_PROGRESS" {
+				tx.legacy.Tasks[i].LeaseOwner = ""
+				tx.legacy.Tasks[i].LeaseExpiresAt = nil
+			}
+			break
+		}
+	}
+
+	tx.modified = true
+	return prevStatus, nil
+}
+
+// Update runs fn as a single transaction: the project lock is taken once,
+// the project is loaded once, and all task edits, status transitions and
+// events made through tx are saved once when fn returns nil. If fn returns an
+// error nothing is written. Nothing is written either when fn made no changes.
+func (pdm *ProjectDataManager) Update(projectName string, fn func(tx *ProjectTx) error) error {
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return err
+	}
+	defer pdm.ReleaseLock(projectName)
+
+	tx, err := pdm.loadTx(projectName)
+	if err != nil {
+		return err
+	}
+
+	history := pdm.historyEnabled(projectName)
+	var before []TaskView
+	if history {
+		before = tx.Views()
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return pdm.commitAndRecordTx(tx, before, history)
+}
+
+// UpdateAtRevision is the optimistic form of Update for hot paths such as
+// claiming: the project is loaded and fn runs without the lock, and the
+// lock is only taken to save the result, which happens only if nobody saved
+// the project in between. Otherwise nothing is written and a
+// *RevisionConflictError is returned; wrap the call in retryOnConflict to
+// replay fn against fresh state.
+func (pdm *ProjectDataManager) UpdateAtRevision(projectName string, fn func(tx *ProjectTx) error) error {
+	// Task files are read before the state file carrying their revision, so
+	// take the revision first: a save racing the load then shows up as a
+	// conflict instead of pairing stale tasks with the new revision.
+	stateRevision, err := storedRevision(filepath.Join(pdm.dataDir, projectName, projectStateFile))
+	if err != nil {
+		return err
+	}
+	tx, err := pdm.loadTx(projectName)
+	if err != nil {
+		return err
+	}
+	if tx.v11 != nil && tx.v11.Layout == layoutTaskFiles {
+		tx.v11.Revision = stateRevision
+	}
+
+	history := pdm.historyEnabled(projectName)
+	var before []TaskView
+	if history {
+		before = tx.Views()
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.modified && len(tx.events) == 0 && (tx.v11 == nil || len(tx.v11.Events) == 0) {
+		return nil
+	}
+
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return err
+	}
+	defer pdm.ReleaseLock(projectName)
+
+	if err := pdm.checkTxRevision(tx); err != nil {
+		return err
+	}
+	return pdm.commitAndRecordTx(tx, before, history)
+}
+
+// loadTx loads projectName into a fresh transaction.
+func (pdm *ProjectDataManager) loadTx(projectName string) (*ProjectTx, error) {
+	tx := &ProjectTx{projectName: projectName}
+	if v11, err := pdm.LoadProjectV11(projectName); err == nil {
+		tx.v11 = v11
+		return tx, nil
+	}
+	legacy, err := pdm.LoadProjectData(projectName)
+	if err != nil {
+		return nil, err
+	}
+	tx.legacy = legacy
+	return tx, nil
+}
+
+// checkTxRevision returns a *RevisionConflictError when the project was
+// saved since tx was loaded. The caller must hold the project lock, and
+// checks before anything is written, so a conflict leaves no trace.
+func (pdm *ProjectDataManager) checkTxRevision(tx *ProjectTx) error {
+	projectPath := filepath.Join(pdm.dataDir, tx.projectName)
+	path := filepath.Join(projectPath, "tasks.yaml")
+	var held int64
+	if tx.v11 != nil {
+		path, held = filepath.Join(projectPath, "project.yaml"), tx.v11.Revision
+		if tx.v11.Layout == layoutTaskFiles {
+			path = filepath.Join(projectPath, projectStateFile)
+		}
+	} else {
+		held = tx.legacy.Revision
+	}
+
+	stored, err := storedRevision(path)
+	if err != nil {
+		return err
+	}
+	if stored != held {
+		return &RevisionConflictError{Project: tx.projectName, Expected: held, Actual: stored}
+	}
+	return nil
+}
+
+// commitAndRecordTx saves tx and records it in git history; the caller must
+// hold the project lock.
+func (pdm *ProjectDataManager) commitAndRecordTx(tx *ProjectTx, before []TaskView, history bool) error {
+	if err := pdm.commitTx(tx); err != nil {
+		return err
+	}
+	if history && !tx.quiet && (tx.modified || len(tx.events) > 0) {
+		pdm.recordHistory(tx.projectName, before, tx.events)
+	}
+	return nil
+}
+
+func (pdm *ProjectDataManager) commitTx(tx *ProjectTx) error {
+	if tx.v11 != nil {
+		// Events live in the event store; anything still embedded in
+		// project.yaml moves there before new events are appended.
+		if len(tx.v11.Events) > 0 {
+			if _, err := pdm.moveEmbeddedEvents(tx.projectName, tx.v11); err != nil {
+				return err
+			}
+			tx.modified = true
+		}
+		if tx.modified {
+			for _, event := range rollUpParents(tx.v11) {
+				tx.AppendEvent(event)
+			}
+			if err := ValidateProjectV11(tx.v11); err != nil {
+				return fmt.Errorf("validation failed: %w", err)
+			}
+			if err := pdm.writeProjectV11Locked(tx.projectName, tx.v11, false); err != nil {
+				return err
+			}
+		}
+		if len(tx.events) > 0 {
+			if _, err := pdm.eventStore(tx.projectName).Append(tx.events...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if tx.modified {
+		if err := pdm.writeProjectDataLocked(tx.projectName, tx.legacy, false); err != nil {
+			return err
+		}
+	}
+	if len(tx.events) > 0 {
+		return pdm.appendLegacyEvents(tx.projectName, tx.events...)
+	}
+	return nil
+}
+
+// appendLegacyEvents appends events to the events.yaml sidecar.
+func (pdm *ProjectDataManager) appendLegacyEvents(projectName string, events ...Event) error {
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	eventsPath := pdm.getEventsPath(projectName)
+
+	// Ensure project directory exists
+	if _, err := os.Stat(projectPath); os.IsNotExist(err) {
+		return fmt.Errorf("project '%s' does not exist", projectName)
+	}
+
+	// Load existing events or create new log
+	var eventLog EventLog
+	data, err := os.ReadFile(eventsPath)
+	if err == nil {
+		if err := yaml.Unmarshal(data, &eventLog); err != nil {
+			// Protocol says append-only, so refuse to overwrite a log we cannot read.
+			return fmt.Errorf("failed to parse events file: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read events file: %w", err)
+	}
+
+	if eventLog.SchemaVersion == "" {
+		eventLog.SchemaVersion = "events-0.1"
+	}
+
+	eventLog.Events = append(eventLog.Events, events...)
+
+	out, err := yaml.Marshal(eventLog)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	return writeFileAtomic(eventsPath, out, 0644)
+}
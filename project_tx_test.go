@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpdate_SavesAllEditsOnce(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if _, err := tx.SetStatus("t-1", "IN_PROGRESS", "agent-1"); err != nil {
+			return err
+		}
+		if _, err := tx.SetStatus("t-2", "IN_PROGRESS", "agent-2"); err != nil {
+			return err
+		}
+		tx.AppendEvent(Event{Type: "NOTE", Actor: "agent-1", Message: "both claimed"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	stored, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if stored.Revision != 2 {
+		t.Fatalf("expected a single save (revision 2), got revision %d", stored.Revision)
+	}
+	if stored.Tasks[0].Status != "IN_PROGRESS" || stored.Tasks[1].Status != "IN_PROGRESS" {
+		t.Fatalf("expected both tasks in progress, got %+v", stored.Tasks)
+	}
+	if lastSeq, _ := pdm.eventStore(projectName).LastSeq(); lastSeq != 3 {
+		t.Fatalf("expected 3 events, got %d", lastSeq)
+	}
+}
+
+func TestUpdate_RollsBackOnError(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	boom := errors.New("boom")
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if _, err := tx.SetStatus("t-1", "IN_PROGRESS", "agent-1"); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+
+	stored, _ := pdm.LoadProjectV11(projectName)
+	lastSeq, _ := pdm.eventStore(projectName).LastSeq()
+	if stored.Revision != 1 || stored.Tasks[0].Status != "TODO" || lastSeq != 0 {
+		t.Fatalf("failed transaction must not be persisted: rev=%d tasks=%+v events=%d", stored.Revision, stored.Tasks, lastSeq)
+	}
+}
+
+func TestUpdate_NoChangesSkipsWrite(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	if err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		_ = tx.Views()
+		return nil
+	}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	stored, _ := pdm.LoadProjectV11(projectName)
+	if stored.Revision != 1 {
+		t.Fatalf("no-op transaction must not write, got revision %d", stored.Revision)
+	}
+}
+
+func TestProjectTxSetStatus_ValidatesAgainstPendingEdits(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if _, err := tx.SetStatus("t-1", "DONE", ""); err == nil {
+			t.Fatal("expected TODO -> DONE to be rejected")
+		}
+		if _, err := tx.SetStatus("t-1", "IN_PROGRESS", ""); err != nil {
+			return err
+		}
+		prev, err := tx.SetStatus("t-1", "DONE", "")
+		if err != nil {
+			return err
+		}
+		if prev != "IN_PROGRESS" {
+			t.Fatalf("expected previous status IN_PROGRESS, got %s", prev)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+}
+
+func TestUpdate_LegacyWritesTasksAndEvents(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if tx.IsV11() {
+			t.Fatal("expected legacy transaction")
+		}
+		tx.Legacy().Tasks = append(tx.Legacy().Tasks, Task{ID: 1, Text: "task", Status: "TODO"})
+		tx.MarkModified()
+		_, err := tx.SetStatus("t-1", "IN_PROGRESS", "agent-1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	data, err := pdm.LoadProjectData(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(data.Tasks) != 1 || data.Tasks[0].Status != "IN_PROGRESS" {
+		t.Fatalf("unexpected tasks: %+v", data.Tasks)
+	}
+	events, err := pdm.LoadEvents(projectName)
+	if err != nil {
+		t.Fatalf("load events failed: %v", err)
+	}
+	if len(events.Events) != 1 || events.Events[0].Type != "TASK_STATUS_CHANGED" {
+		t.Fatalf("unexpected events: %+v", events.Events)
+	}
+}
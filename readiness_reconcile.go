@@ -5,72 +5,88 @@ import (
 	"time"
 )
 
+type readinessPulse struct {
+	taskID, status, prevStatus, eventType, message string
+}
+
 // ReconcileTaskReadiness aligns task status with dependency and guard readiness.
 // - TODO/PENDING tasks with unmet prerequisites are moved to BLOCKED.
 // - BLOCKED tasks with all prerequisites satisfied are moved to PENDING.
+// Parents of subtasks are skipped; their status is rolled up on commit.
+// All changes are applied in a single project transaction, which is replayed
+// if another writer saved the project while readiness was being checked.
 func (pdm *ProjectDataManager) ReconcileTaskReadiness(projectName, actorID string) (int, error) {
-	views, _, err := pdm.GetTaskViews(projectName)
-	if err != nil {
-		return 0, err
-	}
-
 	actor := strings.TrimSpace(actorID)
 	if actor == "" {
 		actor = "system:guard"
 	}
 
-	statusByID := buildStatusIndex(views)
-	changed := 0
+	var pulses []readinessPulse
+	err := retryOnConflict(func() error {
+		pulses = nil
+		return pdm.UpdateAtRevision(projectName, func(tx *ProjectTx) error {
+			views := tx.Views()
+			statusByID := buildStatusIndex(views)
 
-	for _, task := range views {
-		current := canonicalStatus(task.Status)
-		if current != "PENDING" && current != "BLOCKED" {
-			continue
-		}
+			for _, task := range views {
+				current := canonicalStatus(task.Status)
+				if current != "PENDING" && current != "BLOCKED" {
+					continue
+				}
+				if len(task.Subtasks) > 0 {
+					continue // status follows the subtasks
+				}
 
-		issue := taskPrerequisiteIssue(task, statusByID)
+				issue := taskPrerequisiteIssue(task, statusByID)
 
-		if issue != "" && current != "BLOCKED" {
-			if err := pdm.UpdateTaskStatus(projectName, task.ID, "BLOCKED", ""); err != nil {
-				return changed, err
-			}
+				if issue != "" && current != "BLOCKED" {
+					if _, err := tx.SetStatus(task.ID, "BLOCKED", ""); err != nil {
+						return err
+					}
 
-			_ = pdm.AppendEvent(projectName, Event{
-				Timestamp:  time.Now(),
-				Type:       "TASK_BLOCKED",
-				Actor:      actor,
-				TaskID:     task.ID,
-				PrevStatus: task.Status,
-				NextStatus: "BLOCKED",
-				Message:    issue,
-			})
+					tx.AppendEvent(Event{
+						Timestamp:  time.Now(),
+						Type:       "TASK_BLOCKED",
+						Actor:      actor,
+						TaskID:     task.ID,
+						PrevStatus: task.Status,
+						NextStatus: "BLOCKED",
+						Message:    issue,
+					})
 
-			statusByID[task.ID] = "BLOCKED"
-			SendPulseWithMessage(projectName, actor, task.ID, "BLOCKED", task.Status, "TASK_BLOCKED", issue)
-			changed++
-			continue
-		}
+					statusByID[task.ID] = "BLOCKED"
+					pulses = append(pulses, readinessPulse{task.ID, "BLOCKED", task.Status, "TASK_BLOCKED", issue})
+					continue
+				}
 
-		if issue == "" && current == "BLOCKED" {
-			if err := pdm.UpdateTaskStatus(projectName, task.ID, "PENDING", ""); err != nil {
-				return changed, err
-			}
+				if issue == "" && current == "BLOCKED" {
+					if _, err := tx.SetStatus(task.ID, "PENDING", ""); err != nil {
+						return err
+					}
 
-			_ = pdm.AppendEvent(projectName, Event{
-				Timestamp:  time.Now(),
-				Type:       "TASK_UNBLOCKED",
-				Actor:      actor,
-				TaskID:     task.ID,
-				PrevStatus: task.Status,
-				NextStatus: "PENDING",
-				Message:    "All dependencies and guard checks are satisfied",
-			})
+					tx.AppendEvent(Event{
+						Timestamp:  time.Now(),
+						Type:       "TASK_UNBLOCKED",
+						Actor:      actor,
+						TaskID:     task.ID,
+						PrevStatus: task.Status,
+						NextStatus: "PENDING",
+						Message:    "All dependencies and guard checks are satisfied",
+					})
 
-			statusByID[task.ID] = "PENDING"
-			SendPulseWithMessage(projectName, actor, task.ID, "PENDING", task.Status, "TASK_UNBLOCKED", "All dependencies and guard checks are satisfied")
-			changed++
-		}
+					statusByID[task.ID] = "PENDING"
+					pulses = append(pulses, readinessPulse{task.ID, "PENDING", task.Status, "TASK_UNBLOCKED", "All dependencies and guard checks are satisfied"})
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return changed, nil
+	for _, p := range pulses {
+		SendPulseWithMessage(projectName, actor, p.taskID, p.status, p.prevStatus, p.eventType, p.message)
+	}
+	return len(pulses), nil
 }
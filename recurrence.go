@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// recurrenceLocation returns the time zone a recurrence is evaluated in:
+// its timezone, or local time when none is set.
+func recurrenceLocation(r *Recurrence) (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", r.Timezone)
+	}
+	return loc, nil
+}
+
+// nextOccurrence returns the first time after now that r's schedule fires.
+func nextOccurrence(r *Recurrence, now time.Time) (time.Time, error) {
+	schedule, err := parseCron(r.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := recurrenceLocation(r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := schedule.Next(now.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("schedule %q never fires", r.Schedule)
+	}
+	return next, nil
+}
+
+// recurrenceFindings reports unparsable schedules and time zones, and
+// recurrences on parents, which would repeat without their subtasks.
+func recurrenceFindings(tasks []TaskV11) []finding {
+	children := subtaskIndex(tasks)
+	var findings []finding
+	for i, task := range tasks {
+		if task.Recurrence == nil {
+			continue
+		}
+		if _, err := parseCron(task.Recurrence.Schedule); err != nil {
+			findings = append(findings, errorAt(i, "recurrence", -1, "task-recurrence-invalid", "task %s: %v", task.ID, err))
+		}
+		if _, err := recurrenceLocation(task.Recurrence); err != nil {
+			findings = append(findings, errorAt(i, "recurrence", -1, "task-recurrence-timezone", "task %s: %v", task.ID, err))
+		}
+		if len(children[task.ID]) > 0 {
+			findings = append(findings, warningAt(i, "recurrence", -1, "task-recurrence-parent",
+				"task %s has subtasks, which do not recur with it", task.ID))
+		}
+	}
+	return findings
+}
+
+// recurredInstance builds the next instance of a finished recurring task:
+// a PENDING copy with a fresh ID that starts at next. It keeps a human
+// assignee, so the daemon never picks up a chore meant for a person, but
+// drops the worker that ran the last instance so any worker can run the
+// next. A due date keeps its distance from the start date; without a start
+// date it is dropped.
+func recurredInstance(done TaskV11, id string, next, now time.Time) TaskV11 {
+	instance := done
+	instance.ID = id
+	instance.Status = "PENDING"
+	instance.Attempts = 0
+	instance.LastError = ""
+	instance.LeaseOwner = ""
+	instance.LeaseExpiresAt = nil
+	if isWorkerID(done.AssignedTo) || (done.LeaseOwner != "" && done.AssignedTo == done.LeaseOwner) {
+		instance.AssignedTo = ""
+	}
+	instance.RecursFrom = done.ID
+	instance.UpdatedAt = now
+	instance.DependsOn = append([]string{}, done.DependsOn...)
+	instance.Labels = append([]string{}, done.Labels...)
+	instance.Watch.Paths = append([]string{}, done.Watch.Paths...)
+	instance.Watch.RequiresFiles = append([]string{}, done.Watch.RequiresFiles...)
+	if done.RetryPolicy != nil {
+		policy := *done.RetryPolicy
+		instance.RetryPolicy = &policy
+	}
+
+	start := next
+	instance.Start = &start
+	instance.Due = nil
+	instance.OverdueNotified = nil
+	if done.Due != nil && done.Start != nil {
+		due := next.Add(done.Due.Sub(*done.Start))
+		instance.Due = &due
+	}
+	return instance
+}
+
+// RecurDoneTasks creates the next instance of every DONE task with a
+// recurrence and records a TASK_RECURRED event for it. The recurrence moves
+// to the new instance, so each finished instance recurs once. A task whose
+// schedule cannot be evaluated is skipped with a warning, leaving the others
+// to recur.
+func (pdm *ProjectDataManager) RecurDoneTasks(projectName, actor string, now time.Time) (int, error) {
+	var pulses []readinessPulse
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		if !tx.IsV11() {
+			return nil
+		}
+		v11 := tx.V11()
+
+		count := len(v11.Tasks)
+		for i := 0; i < count; i++ {
+			done := v11.Tasks[i]
+			if done.Recurrence == nil || done.Status != "DONE" {
+				continue
+			}
+			next, err := nextOccurrence(done.Recurrence, now)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: task %s in project '%s' does not recur: %v\n", done.ID, projectName, err)
+				continue
+			}
+
+			id := fmt.Sprintf("t-%d", nextV11TaskNumericID(v11.Tasks))
+			instance := recurredInstance(done, id, next, now)
+			v11.Tasks[i].Recurrence = nil
+			v11.Tasks = append(v11.Tasks, instance)
+			tx.MarkModified()
+
+			message := fmt.Sprintf("Recurs from %s; next run %s", done.ID, next.Format(time.RFC3339))
+			tx.AppendEvent(Event{
+				Timestamp:  now,
+				Type:       "TASK_RECURRED",
+				Actor:      actor,
+				TaskID:     id,
+				NextStatus: "PENDING",
+				Message:    message,
+			})
+			pulses = append(pulses, readinessPulse{id, "PENDING", "", "TASK_RECURRED", message})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, p := range pulses {
+		SendPulseWithMessage(projectName, actor, p.taskID, p.status, p.prevStatus, p.eventType, p.message)
+	}
+	return len(pulses), nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecurDoneTasks_ClonesNextInstance(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	// Wednesday; the weekly audit ran Monday with a day to finish.
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	due := start.Add(24 * time.Hour)
+	v11 := &ProjectV11{
+		SchemaVersion: schemaV13,
+		Project:       ProjectMeta{Name: projectName, CreatedAt: now},
+		Tasks: []TaskV11{
+			{
+				ID: "t-1", Name: "dependency audit", Status: "DONE", Attempts: 2, LastError: "flaky",
+				Behavior:   AgentBehavior{LifeCycle: "Atomic", Command: "make audit"},
+				Start:      &start,
+				Due:        &due,
+				Recurrence: &Recurrence{Schedule: "0 9 * * MON", Timezone: "UTC"},
+			},
+			{ID: "t-2", Name: "one-off", Status: "DONE"},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	for i, want := range []int{1, 0} {
+		n, err := pdm.RecurDoneTasks(projectName, "daemon", now)
+		if err != nil {
+			t.Fatalf("recur %d failed: %v", i, err)
+		}
+		if n != want {
+			t.Fatalf("recur %d: expected %d new instances, got %d", i, want, n)
+		}
+	}
+
+	loaded, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(loaded.Tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(loaded.Tasks))
+	}
+	if loaded.Tasks[0].Recurrence != nil {
+		t.Fatalf("expected the recurrence to move off the finished instance")
+	}
+
+	next := loaded.Tasks[2]
+	wantStart := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	if next.ID != "t-3" || next.Status != "PENDING" || next.RecursFrom != "t-1" ||
+		next.Attempts != 0 || next.LastError != "" || next.Behavior.Command != "make audit" {
+		t.Fatalf("unexpected next instance: %+v", next)
+	}
+	if next.Recurrence == nil || next.Recurrence.Schedule != "0 9 * * MON" {
+		t.Fatalf("expected the next instance to carry the recurrence, got %+v", next.Recurrence)
+	}
+	if next.Start == nil || !next.Start.Equal(wantStart) {
+		t.Fatalf("expected the next instance to start at %s, got %v", wantStart, next.Start)
+	}
+	if next.Due == nil || !next.Due.Equal(wantStart.Add(24*time.Hour)) {
+		t.Fatalf("expected the due date to keep its offset, got %v", next.Due)
+	}
+
+	stored, err := pdm.eventStore(projectName).ReadAll()
+	if err != nil {
+		t.Fatalf("read events failed: %v", err)
+	}
+	recurred := 0
+	for _, event := range stored {
+		if event.Type == "TASK_RECURRED" {
+			recurred++
+			if event.TaskID != "t-3" || event.NextStatus != "PENDING" || !strings.Contains(event.Message, "t-1") {
+				t.Fatalf("unexpected recurred event: %+v", event)
+			}
+		}
+	}
+	if recurred != 1 {
+		t.Fatalf("expected 1 TASK_RECURRED event, got %d", recurred)
+	}
+}
+
+func TestRecurrenceFindings(t *testing.T) {
+	tasks := []TaskV11{
+		{ID: "t-1", Recurrence: &Recurrence{Schedule: "0 9 * * MON"}},
+		{ID: "t-2", Recurrence: &Recurrence{Schedule: "every monday"}},
+		{ID: "t-3", Recurrence: &Recurrence{Schedule: "@daily", Timezone: "Mars/Olympus"}},
+		{ID: "t-4", Recurrence: &Recurrence{Schedule: "@daily"}},
+		{ID: "t-5", Parent: "t-4"},
+	}
+	var codes []string
+	for _, f := range recurrenceFindings(tasks) {
+		codes = append(codes, f.Code)
+	}
+	want := "task-recurrence-invalid task-recurrence-timezone task-recurrence-parent"
+	if got := strings.Join(codes, " "); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRecurDoneTasks_ReleasesWorkerForDaemon(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	v11 := &ProjectV11{
+		SchemaVersion: schemaV13,
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks: []TaskV11{
+			{
+				ID: "t-1", Name: "nightly backup", Status: "TODO", UpdatedAt: time.Now(),
+				Behavior:   AgentBehavior{LifeCycle: "Atomic", Command: "make backup"},
+				Recurrence: &Recurrence{Schedule: "@daily", Timezone: "UTC"},
+			},
+			{
+				ID: "t-2", Name: "water plants", Status: "TODO", AssignedTo: "alice", UpdatedAt: time.Now(),
+				Recurrence: &Recurrence{Schedule: "@daily", Timezone: "UTC"},
+			},
+			{
+				ID: "t-3", Name: "broken", Status: "TODO", UpdatedAt: time.Now(),
+				Recurrence: &Recurrence{Schedule: "0 0 30 2 *", Timezone: "UTC"},
+			},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	if err := pdm.ClaimTask(projectName, "t-1", "daemon-worker-42"); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	for _, id := range []string{"t-1", "t-2", "t-3"} {
+		if id != "t-1" {
+			if err := pdm.UpdateTaskStatus(projectName, id, "IN_PROGRESS", ""); err != nil {
+				t.Fatalf("start %s failed: %v", id, err)
+			}
+		}
+		if err := pdm.UpdateTaskStatus(projectName, id, "DONE", ""); err != nil {
+			t.Fatalf("finish %s failed: %v", id, err)
+		}
+	}
+
+	// Two days ago, so the next daily occurrence is already due.
+	n, err := pdm.RecurDoneTasks(projectName, "daemon", time.Now().Add(-48*time.Hour))
+	if err != nil {
+		t.Fatalf("recur failed: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected the schedule that never fires to be skipped and 2 instances, got %d", n)
+	}
+
+	loaded, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	assignees := map[string]string{}
+	for _, task := range loaded.Tasks {
+		if task.RecursFrom != "" {
+			assignees[task.RecursFrom] = task.AssignedTo
+		}
+	}
+	if assignees["t-1"] != "" || assignees["t-2"] != "alice" {
+		t.Fatalf("expected the worker dropped and the human kept, got %v", assignees)
+	}
+
+	views, _, err := pdm.GetTaskViews(projectName)
+	if err != nil {
+		t.Fatalf("views failed: %v", err)
+	}
+
+	candidates := pdm.runnableCandidates(projectName, views, func(v TaskView) bool { return v.AssignedTo == "" })
+	if len(candidates) != 1 || candidates[0].Text != "nightly backup" {
+		t.Fatalf("expected the daemon to pick up the next backup, got %+v", candidates)
+	}
+}
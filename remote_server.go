@@ -0,0 +1,492 @@
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	remoteAPIVersion        = "v1"
+	pulseSubscriberBuffer   = 64
+	pulseKeepaliveInterval  = 15 * time.Second
+	maxRemoteRequestBodyLen = 8 << 20
+)
+
+// blueprintIDPattern restricts registry IDs and versions to safe path segments.
+var blueprintIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// RemotePulse is the wire format accepted on /api/v1/pulse and re-broadcast
+// on /api/v1/pulse/stream. TaskID is normalized to a string on ingest so
+// stream consumers (e.g. `quickplan monitor`) can decode it uniformly.
+type RemotePulse struct {
+	Project    string `json:"project"`
+	AgentID    string `json:"agent_id"`
+	TaskID     string `json:"task_id"`
+	Status     string `json:"status"`
+	PrevStatus string `json:"prev_status,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Timestamp  string `json:"timestamp"`
+}
+
+// PulseHub fans incoming pulses out to SSE subscribers.
+type PulseHub struct {
+	mu          sync.Mutex
+	subscribers map[chan []byte]struct{}
+}
+
+// NewPulseHub creates an empty pulse hub
+func NewPulseHub() *PulseHub {
+	return &PulseHub{subscribers: make(map[chan []byte]struct{})}
+}
+
+// Subscribe registers a new stream listener. The returned cancel func must be
+// called when the listener goes away.
+func (h *PulseHub) Subscribe() (<-chan []byte, func()) {
+	ch := make(chan []byte, pulseSubscriberBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// Publish delivers a payload to every subscriber. Slow subscribers drop
+// messages instead of stalling publishers.
+func (h *PulseHub) Publish(payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+}
+
+// SubscriberCount reports the number of connected stream listeners.
+func (h *PulseHub) SubscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// Blueprint is a registry entry as exchanged by `sync push` and `sync pull`.
+type Blueprint struct {
+	ID            string    `json:"id"`
+	Author        string    `json:"author,omitempty"`
+	Description   string    `json:"description,omitempty"`
+	YAMLContent   string    `json:"yaml_content"`
+	Format        string    `json:"format,omitempty"`
+	SchemaVersion string    `json:"schema_version,omitempty"`
+	Version       string    `json:"version,omitempty"`
+	PushedAt      time.Time `json:"pushed_at"`
+}
+
+// ErrBlueprintExists is returned when an id/version pair is already published.
+var ErrBlueprintExists = fmt.Errorf("blueprint version already exists")
+
+// ErrBlueprintNotFound is returned when no matching blueprint is stored.
+var ErrBlueprintNotFound = fmt.Errorf("blueprint not found")
+
+// BlueprintRegistry stores immutable blueprint versions on disk as
+// <root>/<id>/<version>.json.
+type BlueprintRegistry struct {
+	mu   sync.Mutex
+	root string
+}
+
+// NewBlueprintRegistry creates a registry rooted at dir
+func NewBlueprintRegistry(dir string) (*BlueprintRegistry, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	return &BlueprintRegistry{root: dir}, nil
+}
+
+// Push stores a new blueprint version. Versions are immutable.
+func (r *BlueprintRegistry) Push(bp Blueprint) error {
+	if !blueprintIDPattern.MatchString(bp.ID) {
+		return fmt.Errorf("invalid blueprint id: %q", bp.ID)
+	}
+	if strings.TrimSpace(bp.Version) == "" {
+		bp.Version = "1"
+	}
+	if !blueprintIDPattern.MatchString(bp.Version) {
+		return fmt.Errorf("invalid blueprint version: %q", bp.Version)
+	}
+	if strings.TrimSpace(bp.YAMLContent) == "" {
+		return fmt.Errorf("blueprint yaml_content is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir := filepath.Join(r.root, bp.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, bp.Version+".json")
+	if _, err := os.Stat(path); err == nil {
+		return ErrBlueprintExists
+	}
+
+	bp.PushedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(bp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data, 0644)
+}
+
+// Pull returns a specific blueprint version, or the most recently pushed
+// version when version is empty.
+func (r *BlueprintRegistry) Pull(id, version string) (*Blueprint, error) {
+	if !blueprintIDPattern.MatchString(id) {
+		return nil, ErrBlueprintNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if version != "" {
+		if !blueprintIDPattern.MatchString(version) {
+			return nil, ErrBlueprintNotFound
+		}
+		return r.readBlueprint(filepath.Join(r.root, id, version+".json"))
+	}
+
+	versions, err := r.loadVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrBlueprintNotFound
+	}
+	latest := versions[len(versions)-1]
+	return &latest, nil
+}
+
+func (r *BlueprintRegistry) loadVersions(id string) ([]Blueprint, error) {
+	entries, err := os.ReadDir(filepath.Join(r.root, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrBlueprintNotFound
+		}
+		return nil, err
+	}
+
+	var out []Blueprint
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		bp, err := r.readBlueprint(filepath.Join(r.root, id, entry.Name()))
+		if err != nil {
+			continue
+		}
+		out = append(out, *bp)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].PushedAt.Before(out[j].PushedAt)
+	})
+	return out, nil
+}
+
+func (r *BlueprintRegistry) readBlueprint(path string) (*Blueprint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrBlueprintNotFound
+		}
+		return nil, err
+	}
+	var bp Blueprint
+	if err := json.Unmarshal(data, &bp); err != nil {
+		return nil, fmt.Errorf("corrupt blueprint %s: %w", path, err)
+	}
+	return &bp, nil
+}
+
+// RemoteServerAuth holds the credentials accepted by the remote server.
+// An empty config disables authentication.
+type RemoteServerAuth struct {
+	APIKey string
+	Token  string
+}
+
+func (a RemoteServerAuth) enabled() bool {
+	return a.APIKey != "" || a.Token != ""
+}
+
+// authorize accepts the same headers applyWebAuth sends: X-API-Key and/or
+// "Authorization: Bearer <token>". Either matching credential is sufficient.
+func (a RemoteServerAuth) authorize(req *http.Request) bool {
+	if !a.enabled() {
+		return true
+	}
+	if a.APIKey != "" {
+		if key := strings.TrimSpace(req.Header.Get("X-API-Key")); key != "" && secureEqual(key, a.APIKey) {
+			return true
+		}
+	}
+	if a.Token != "" {
+		header := strings.TrimSpace(req.Header.Get("Authorization"))
+		if strings.HasPrefix(header, "Bearer ") {
+			if secureEqual(strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")), a.Token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func secureEqual(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// RemoteServer implements the remote API the CLI talks to: pulses, the pulse
+// stream, the blueprint registry and the info endpoint.
+type RemoteServer struct {
+	Hub       *PulseHub
+	Registry  *BlueprintRegistry
+	Auth      RemoteServerAuth
+	StartedAt time.Time
+}
+
+// NewRemoteServer creates a server with a file-backed registry under registryDir
+func NewRemoteServer(registryDir string, auth RemoteServerAuth) (*RemoteServer, error) {
+	registry, err := NewBlueprintRegistry(registryDir)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteServer{
+		Hub:       NewPulseHub(),
+		Registry:  registry,
+		Auth:      auth,
+		StartedAt: time.Now(),
+	}, nil
+}
+
+// Handler returns the HTTP routes served by the remote server.
+func (s *RemoteServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/info", s.handleInfo)
+	mux.HandleFunc("/api/v1/pulse", s.requireAuth(s.handlePulse))
+	mux.HandleFunc("/api/v1/pulse/stream", s.requireAuth(s.handlePulseStream))
+	mux.HandleFunc("/api/v1/registry/push", s.requireAuth(s.handleRegistryPush))
+	mux.HandleFunc("/api/v1/registry/pull", s.requireAuth(s.handleRegistryPull))
+	return mux
+}
+
+func (s *RemoteServer) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !s.Auth.authorize(req) {
+			writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next(w, req)
+	}
+}
+
+func (s *RemoteServer) handleInfo(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        "ok",
+		"service":       "quickplan",
+		"version":       version,
+		"api_version":   remoteAPIVersion,
+		"auth_required": s.Auth.enabled(),
+		"uptime":        time.Since(s.StartedAt).Round(time.Second).String(),
+		"subscribers":   s.Hub.SubscriberCount(),
+	})
+}
+
+func (s *RemoteServer) handlePulse(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var raw struct {
+		Project    string      `json:"project"`
+		AgentID    string      `json:"agent_id"`
+		TaskID     interface{} `json:"task_id"`
+		Status     string      `json:"status"`
+		PrevStatus string      `json:"prev_status"`
+		Type       string      `json:"type"`
+		Message    string      `json:"message"`
+		Timestamp  string      `json:"timestamp"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRemoteRequestBodyLen)).Decode(&raw); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid pulse: %v", err))
+		return
+	}
+
+	pulse := RemotePulse{
+		Project:    raw.Project,
+		AgentID:    raw.AgentID,
+		TaskID:     normalizePulseTaskID(raw.TaskID),
+		Status:     raw.Status,
+		PrevStatus: raw.PrevStatus,
+		Type:       raw.Type,
+		Message:    raw.Message,
+		Timestamp:  raw.Timestamp,
+	}
+	if pulse.Timestamp == "" {
+		pulse.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	payload, err := json.Marshal(pulse)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.Hub.Publish(payload)
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+func normalizePulseTaskID(v interface{}) string {
+	switch id := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	case float64:
+		if id == float64(int64(id)) {
+			return fmt.Sprintf("%d", int64(id))
+		}
+		return fmt.Sprintf("%v", id)
+	default:
+		return fmt.Sprintf("%v", id)
+	}
+}
+
+func (s *RemoteServer) handlePulseStream(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ch, cancel := s.Hub.Subscribe()
+	defer cancel()
+
+	keepalive := time.NewTicker(pulseKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case payload, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *RemoteServer) handleRegistryPush(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var bp Blueprint
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRemoteRequestBodyLen)).Decode(&bp); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid blueprint: %v", err))
+		return
+	}
+
+	if err := s.Registry.Push(bp); err != nil {
+		if err == ErrBlueprintExists {
+			writeJSONError(w, http.StatusConflict, fmt.Sprintf("blueprint %s version %s already exists", bp.ID, bp.Version))
+			return
+		}
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]string{"status": "created", "id": bp.ID})
+}
+
+func (s *RemoteServer) handleRegistryPull(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	id := strings.TrimSpace(req.URL.Query().Get("id"))
+	if id == "" {
+		writeJSONError(w, http.StatusBadRequest, "id query parameter is required")
+		return
+	}
+
+	bp, err := s.Registry.Pull(id, strings.TrimSpace(req.URL.Query().Get("version")))
+	if err != nil {
+		if err == ErrBlueprintNotFound {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("blueprint not found: %s", id))
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
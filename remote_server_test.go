@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRemoteServer(t *testing.T, auth RemoteServerAuth) (*RemoteServer, *httptest.Server) {
+	t.Helper()
+
+	server, err := NewRemoteServer(t.TempDir(), auth)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return server, ts
+}
+
+func TestRemoteServer_InfoReportsOK(t *testing.T) {
+	_, ts := newTestRemoteServer(t, RemoteServerAuth{})
+
+	resp, err := http.Get(ts.URL + "/api/v1/info")
+	if err != nil {
+		t.Fatalf("info request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if info.Status != "ok" {
+		t.Fatalf("expected status ok, got %q", info.Status)
+	}
+}
+
+func TestRemoteServer_PulseIsBroadcastToStream(t *testing.T) {
+	server, ts := newTestRemoteServer(t, RemoteServerAuth{})
+
+	resp, err := http.Get(ts.URL + "/api/v1/pulse/stream")
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.Hub.SubscriberCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream subscriber never registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	body := []byte(`{"project":"p","agent_id":"a","task_id":7,"status":"DONE"}`)
+	postResp, err := http.Post(ts.URL+"/api/v1/pulse", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("pulse post failed: %v", err)
+	}
+	postResp.Body.Close()
+	if postResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", postResp.StatusCode)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream read failed: %v", err)
+		}
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		var pulse SwarmPulse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &pulse); err != nil {
+			t.Fatalf("pulse decode failed: %v", err)
+		}
+		if pulse.TaskID != "7" || pulse.Status != "DONE" || pulse.Project != "p" {
+			t.Fatalf("unexpected pulse: %+v", pulse)
+		}
+		return
+	}
+}
+
+func TestRemoteServer_RegistryPushPullAndImmutability(t *testing.T) {
+	_, ts := newTestRemoteServer(t, RemoteServerAuth{})
+
+	push := func(version, content string) int {
+		payload, _ := json.Marshal(Blueprint{ID: "demo", Version: version, YAMLContent: content, Format: "v1.1"})
+		resp, err := http.Post(ts.URL+"/api/v1/registry/push", "application/json", bytes.NewReader(payload))
+		if err != nil {
+			t.Fatalf("push failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := push("1", "tasks: []"); code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if code := push("1", "tasks: [changed]"); code != http.StatusConflict {
+		t.Fatalf("expected 409 on duplicate version, got %d", code)
+	}
+	if code := push("2", "tasks: [v2]"); code != http.StatusCreated {
+		t.Fatalf("expected 201 for new version, got %d", code)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/registry/pull?id=demo")
+	if err != nil {
+		t.Fatalf("pull failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var bp Blueprint
+	if err := json.NewDecoder(resp.Body).Decode(&bp); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if bp.Version != "2" || bp.YAMLContent != "tasks: [v2]" {
+		t.Fatalf("expected latest version 2, got %+v", bp)
+	}
+
+	missing, err := http.Get(ts.URL + "/api/v1/registry/pull?id=nope")
+	if err != nil {
+		t.Fatalf("pull failed: %v", err)
+	}
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", missing.StatusCode)
+	}
+}
+
+func TestRemoteServer_RegistryRejectsPathTraversal(t *testing.T) {
+	_, ts := newTestRemoteServer(t, RemoteServerAuth{})
+
+	payload, _ := json.Marshal(Blueprint{ID: "../escape", Version: "1", YAMLContent: "x"})
+	resp, err := http.Post(ts.URL+"/api/v1/registry/push", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestRemoteServer_AcceptsApplyWebAuthHeaders(t *testing.T) {
+	_, ts := newTestRemoteServer(t, RemoteServerAuth{APIKey: "k", Token: "tok"})
+
+	doPull := func(configure func(*http.Request)) int {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/registry/pull?id=none", nil)
+		configure(req)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := doPull(func(*http.Request) {}); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", code)
+	}
+	if code := doPull(func(r *http.Request) { r.Header.Set("X-API-Key", "wrong") }); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with wrong key, got %d", code)
+	}
+
+	t.Setenv("QUICKPLAN_API_KEY", "k")
+	t.Setenv("QUICKPLAN_REMOTE_TOKEN", "")
+	t.Setenv("QUICKPLAN_WEB_TOKEN", "")
+	if code := doPull(applyWebAuth); code != http.StatusNotFound {
+		t.Fatalf("expected API key to authorize, got %d", code)
+	}
+
+	t.Setenv("QUICKPLAN_API_KEY", "")
+	t.Setenv("QUICKPLAN_REMOTE_TOKEN", "tok")
+	if code := doPull(applyWebAuth); code != http.StatusNotFound {
+		t.Fatalf("expected bearer token to authorize, got %d", code)
+	}
+}
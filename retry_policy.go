@@ -8,85 +8,95 @@ import (
 
 // ScheduleRetryIfAllowed applies retry-policy orchestration for v1.1 tasks.
 // It records failure metadata and, if policy allows, transitions:
-// FAILED -> RETRYING -> PENDING (after backoff).
+// FAILED -> RETRYING -> PENDING (after backoff), leaving the assignee as is.
 func (pdm *ProjectDataManager) ScheduleRetryIfAllowed(projectName, taskID, actorID, failureReason string) (bool, error) {
-	v11, err := pdm.LoadProjectV11(projectName)
-	if err != nil {
-		// Legacy projects do not support retry_policy metadata.
-		return false, nil
-	}
-
 	actor := strings.TrimSpace(actorID)
 	if actor == "" {
 		actor = "system:retry"
 	}
 
-	var task *TaskV11
-	for i := range v11.Tasks {
-		if v11.Tasks[i].ID == taskID {
-			task = &v11.Tasks[i]
-			break
-		}
-	}
-	if task == nil {
-		return false, fmt.Errorf("task %s not found in project %s", taskID, projectName)
-	}
+	scheduled := false
+	var backoff time.Duration
+	err := retryOnConflict(func() error {
+		scheduled, backoff = false, 0
+		return pdm.UpdateAtRevision(projectName, func(tx *ProjectTx) error {
+			if !tx.IsV11() {
+				// Legacy projects do not support retry_policy metadata.
+				return nil
+			}
 
-	if canonicalStatus(task.Status) != "FAILED" {
-		return false, nil
-	}
+			v11 := tx.V11()
+			var task *TaskV11
+			for i := range v11.Tasks {
+				if v11.Tasks[i].ID == taskID {
+					task = &v11.Tasks[i]
+					break
+				}
+			}
+			if task == nil {
+				return fmt.Errorf("task %s not found in project %s", taskID, projectName)
+			}
 
-	policy := task.RetryPolicy
-	if policy == nil || policy.MaxAttempts <= 0 {
-		task.LastError = failureReason
-		task.UpdatedAt = time.Now()
-		if saveErr := pdm.SaveProjectV11(projectName, v11); saveErr != nil {
-			return false, saveErr
-		}
-		return false, nil
-	}
+			if canonicalStatus(task.Status) != "FAILED" {
+				return nil
+			}
 
-	attemptNum := task.Attempts + 1
-	task.Attempts = attemptNum
-	task.LastError = failureReason
-	task.UpdatedAt = time.Now()
-	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
-		return false, err
-	}
+			tx.MarkModified()
+			task.LastError = failureReason
+			task.UpdatedAt = time.Now()
 
-	if attemptNum >= policy.MaxAttempts {
-		_ = pdm.AppendEvent(projectName, Event{
-			Timestamp:  time.Now(),
-			Type:       "TASK_RETRY_EXHAUSTED",
-			Actor:      actor,
-			TaskID:     taskID,
-			PrevStatus: "FAILED",
-			NextStatus: "FAILED",
-			Message:    fmt.Sprintf("Retry budget exhausted (%d/%d)", attemptNum, policy.MaxAttempts),
-		})
-		return false, nil
-	}
+			policy := task.RetryPolicy
+			if policy == nil || policy.MaxAttempts <= 0 {
+				return nil
+			}
 
-	if err := pdm.UpdateTaskStatus(projectName, taskID, "RETRYING", actor); err != nil {
-		return false, err
-	}
+			attemptNum := task.Attempts + 1
+			task.Attempts = attemptNum
+
+			if attemptNum >= policy.MaxAttempts {
+				tx.AppendEvent(Event{
+					Timestamp:  time.Now(),
+					Type:       "TASK_RETRY_EXHAUSTED",
+					Actor:      actor,
+					TaskID:     taskID,
+					PrevStatus: "FAILED",
+					NextStatus: "FAILED",
+					Message:    fmt.Sprintf("Retry budget exhausted (%d/%d)", attemptNum, policy.MaxAttempts),
+				})
+				return nil
+			}
 
-	backoff := retryBackoffDuration(policy, attemptNum)
-	_ = pdm.AppendEvent(projectName, Event{
-		Timestamp:  time.Now(),
-		Type:       "TASK_RETRY_SCHEDULED",
-		Actor:      actor,
-		TaskID:     taskID,
-		PrevStatus: "FAILED",
-		NextStatus: "RETRYING",
-		Message:    fmt.Sprintf("Retry %d/%d scheduled after %s", attemptNum, policy.MaxAttempts, backoff),
+			if err := tx.setStatusKeepingAssignee(taskID, "RETRYING", actor); err != nil {
+				return err
+			}
+
+			backoff = retryBackoffDuration(policy, attemptNum)
+			tx.AppendEvent(Event{
+				Timestamp:  time.Now(),
+				Type:       "TASK_RETRY_SCHEDULED",
+				Actor:      actor,
+				TaskID:     taskID,
+				PrevStatus: "FAILED",
+				NextStatus: "RETRYING",
+				Message:    fmt.Sprintf("Retry %d/%d scheduled after %s", attemptNum, policy.MaxAttempts, backoff),
+			})
+			scheduled = true
+			return nil
+		})
 	})
+	if err != nil || !scheduled {
+		return false, err
+	}
 
 	go func(delay time.Duration) {
 		if delay > 0 {
 			time.Sleep(delay)
 		}
-		_ = pdm.UpdateTaskStatus(projectName, taskID, "PENDING", actor)
+		_ = retryOnConflict(func() error {
+			return pdm.UpdateAtRevision(projectName, func(tx *ProjectTx) error {
+				return tx.setStatusKeepingAssignee(taskID, "PENDING", actor)
+			})
+		})
 	}(backoff)
 
 	return true, nil
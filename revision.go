@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultConflictRetries bounds how often a load-modify-save cycle is
+	// replayed after losing a compare-and-swap race.
+	defaultConflictRetries = 8
+	conflictBackoffBase    = 5 * time.Millisecond
+)
+
+// RevisionConflictError reports that a compare-and-swap save lost a race:
+// the stored revision moved since the caller loaded the project.
+type RevisionConflictError struct {
+	Project  string
+	Expected int64
+	Actual   int64
+}
+
+func (e *RevisionConflictError) Error() string {
+	return fmt.Sprintf("revision conflict on project %s: expected revision %d, found %d", e.Project, e.Expected, e.Actual)
+}
+
+// isRevisionConflict reports whether err is (or wraps) a RevisionConflictError.
+func isRevisionConflict(err error) bool {
+	var conflict *RevisionConflictError
+	return errors.As(err, &conflict)
+}
+
+// retryOnConflict runs fn until it succeeds, fails with a non-conflict error
+// or the retry budget is spent. fn must reload state on every call.
+func retryOnConflict(fn func() error) error {
+	var err error
+	for attempt := 0; attempt < defaultConflictRetries; attempt++ {
+		err = fn()
+		if !isRevisionConflict(err) {
+			return err
+		}
+		backoff := conflictBackoffBase * time.Duration(attempt+1)
+		time.Sleep(backoff + time.Duration(rand.Int63n(int64(conflictBackoffBase))))
+	}
+	return err
+}
+
+// storedRevision reads the revision currently persisted in a state file,
+// honoring the same last-good fallback the loaders use. Missing files are
+// revision 0.
+func storedRevision(path string) (int64, error) {
+	var probe struct {
+		Revision int64 `yaml:"revision"`
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		if _, backupErr := loadYAMLBackup(path, &probe); backupErr != nil {
+			return 0, fmt.Errorf("failed to read revision from %s: %w", path, err)
+		}
+	}
+	return probe.Revision, nil
+}
+
+// nextRevision returns the revision to write after stored, never moving
+// backwards relative to what the caller holds.
+func nextRevision(stored, held int64) int64 {
+	if held > stored {
+		return held + 1
+	}
+	return stored + 1
+}
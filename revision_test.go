@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func seedRevisionProject(t *testing.T, pdm *ProjectDataManager, projectName string) {
+	t.Helper()
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks: []TaskV11{
+			{ID: "t-1", Name: "one", Status: "TODO"},
+			{ID: "t-2", Name: "two", Status: "TODO"},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("seed save failed: %v", err)
+	}
+}
+
+func TestSaveProjectV11_BumpsRevision(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	first, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if first.Revision != 1 {
+		t.Fatalf("expected revision 1 after first save, got %d", first.Revision)
+	}
+
+	if err := pdm.CompareAndSwapProjectV11(projectName, first); err != nil {
+		t.Fatalf("CAS save failed: %v", err)
+	}
+	if first.Revision != 2 {
+		t.Fatalf("expected in-memory revision 2 after CAS, got %d", first.Revision)
+	}
+
+	reloaded, _ := pdm.LoadProjectV11(projectName)
+	if reloaded.Revision != 2 {
+		t.Fatalf("expected stored revision 2, got %d", reloaded.Revision)
+	}
+}
+
+func TestCompareAndSwapProjectV11_DetectsLostUpdate(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	a, _ := pdm.LoadProjectV11(projectName)
+	b, _ := pdm.LoadProjectV11(projectName)
+
+	a.Tasks[0].Name = "changed by a"
+	if err := pdm.CompareAndSwapProjectV11(projectName, a); err != nil {
+		t.Fatalf("first writer should win: %v", err)
+	}
+
+	b.Tasks[1].Name = "changed by b"
+	err := pdm.CompareAndSwapProjectV11(projectName, b)
+	var conflict *RevisionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected RevisionConflictError, got %v", err)
+	}
+	if conflict.Expected != 1 || conflict.Actual != 2 {
+		t.Fatalf("unexpected conflict details: %+v", conflict)
+	}
+
+	stored, _ := pdm.LoadProjectV11(projectName)
+	if stored.Tasks[0].Name != "changed by a" || stored.Tasks[1].Name != "two" {
+		t.Fatalf("losing write must not be persisted: %+v", stored.Tasks)
+	}
+}
+
+func TestCompareAndSwapProjectData_DetectsLostUpdate(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	a, _ := pdm.LoadProjectData(projectName)
+	b, _ := pdm.LoadProjectData(projectName)
+
+	a.Tasks = append(a.Tasks, Task{ID: 1, Text: "a", Created: time.Now()})
+	if err := pdm.CompareAndSwapProjectData(projectName, a); err != nil {
+		t.Fatalf("first writer should win: %v", err)
+	}
+
+	b.Tasks = append(b.Tasks, Task{ID: 1, Text: "b", Created: time.Now()})
+	if err := pdm.CompareAndSwapProjectData(projectName, b); !isRevisionConflict(err) {
+		t.Fatalf("expected revision conflict, got %v", err)
+	}
+}
+
+func TestRetryOnConflict_ReplaysUntilSuccess(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedRevisionProject(t, pdm, projectName)
+
+	calls := 0
+	err := retryOnConflict(func() error {
+		calls++
+		v11, err := pdm.LoadProjectV11(projectName)
+		if err != nil {
+			return err
+		}
+		if calls == 1 {
+			// A competing writer sneaks in between our load and save.
+			competitor, _ := pdm.LoadProjectV11(projectName)
+			competitor.Tasks[0].Name = "competitor"
+			if err := pdm.SaveProjectV11(projectName, competitor); err != nil {
+				return err
+			}
+		}
+		v11.Tasks[1].Name = "ours"
+		return pdm.CompareAndSwapProjectV11(projectName, v11)
+	})
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected exactly one replay, got %d calls", calls)
+	}
+
+	stored, _ := pdm.LoadProjectV11(projectName)
+	if stored.Tasks[0].Name != "competitor" || stored.Tasks[1].Name != "ours" {
+		t.Fatalf("expected both writes to survive, got %+v", stored.Tasks)
+	}
+}
+
+func TestUpdateAtRevision_ConflictWritesNothing(t *testing.T) {
+	for _, layout := range []string{"", layoutTaskFiles} {
+		t.Run("layout="+layout, func(t *testing.T) {
+			pdm, projectName, cleanup := newTransitionTestManager(t)
+			defer cleanup()
+			seedRevisionProject(t, pdm, projectName)
+			if layout != "" {
+				v11, _ := pdm.LoadProjectV11(projectName)
+				v11.Layout = layout
+				if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+					t.Fatalf("switch layout failed: %v", err)
+				}
+			}
+
+			err := pdm.UpdateAtRevision(projectName, func(tx *ProjectTx) error {
+				// A competing writer saves between our load and commit.
+				if err := pdm.Update(projectName, func(other *ProjectTx) error {
+					other.V11().Tasks[0].Name = "competitor"
+					other.MarkModified()
+					return nil
+				}); err != nil {
+					return err
+				}
+				if _, err := tx.SetStatus("t-2", "IN_PROGRESS", "worker-1"); err != nil {
+					return err
+				}
+				return nil
+			})
+			if !isRevisionConflict(err) {
+				t.Fatalf("expected revision conflict, got %v", err)
+			}
+
+			stored, _ := pdm.LoadProjectV11(projectName)
+			if stored.Tasks[0].Name != "competitor" || stored.Tasks[1].Status != "TODO" {
+				t.Fatalf("losing transaction must not be persisted: %+v", stored.Tasks)
+			}
+			events, err := pdm.eventStore(projectName).ReadAll()
+			if err != nil {
+				t.Fatalf("read events failed: %v", err)
+			}
+			for _, event := range events {
+				if event.TaskID == "t-2" {
+					t.Fatalf("losing transaction must not append events, got %+v", event)
+				}
+			}
+		})
+	}
+}
+
+func TestClaimNextRunnableTask_ConcurrentWorkersClaimDistinctTasks(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	tasks := make([]TaskV11, 6)
+	for i := range tasks {
+		tasks[i] = TaskV11{ID: fmt.Sprintf("t-%d", i+1), Name: "work", Status: "TODO"}
+	}
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks:         tasks,
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("seed save failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	claimed := make(chan string, len(tasks))
+	for w := 1; w <= len(tasks); w++ {
+		wg.Add(1)
+		go func(agentID string) {
+			defer wg.Done()
+			task, err := pdm.ClaimNextRunnableTask(projectName, agentID)
+			if err != nil {
+				t.Errorf("%s: claim failed: %v", agentID, err)
+				return
+			}
+			if task != nil {
+				claimed <- task.ID
+			}
+		}(fmt.Sprintf("worker-%d", w))
+	}
+	wg.Wait()
+	close(claimed)
+
+	seen := map[string]bool{}
+	for id := range claimed {
+		if seen[id] {
+			t.Fatalf("task %s claimed twice", id)
+		}
+		seen[id] = true
+	}
+	stored, _ := pdm.LoadProjectV11(projectName)
+	for _, task := range stored.Tasks {
+		if seen[task.ID] != (task.Status == "IN_PROGRESS") {
+			t.Fatalf("claims and stored statuses disagree on %s: %s", task.ID, task.Status)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errTaskReleased stops a run whose task left IN_PROGRESS underneath it,
+// typically because it was cancelled, or whose lease was lost.
+var errTaskReleased = errors.New("task is no longer IN_PROGRESS")
+
+// errTaskTimedOut stops a run that exceeded behavior.timeout.
+var errTaskTimedOut = errors.New("timed out")
+
+// taskStatusPoll is how often a running task is checked for cancellation.
+var taskStatusPoll = time.Second
+
+// taskTimeout parses behavior.timeout; zero means no limit.
+func taskTimeout(behavior AgentBehavior) (time.Duration, error) {
+	if behavior.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(behavior.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q (expected a positive duration such as 10m)", behavior.Timeout)
+	}
+	return timeout, nil
+}
+
+// watchTask returns a context for running taskID that is cancelled with
+// errTaskReleased once the task leaves IN_PROGRESS or agentID loses its
+// lease, so cancelling a task stops its command. Meanwhile it renews the
+// lease leaseRenewals times per lease.ttl. Call release when the run is over.
+func (br *BackgroundRunner) watchTask(project, taskID, agentID string) (ctx context.Context, release func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	if br.ProjectManager == nil || taskID == "" || taskID == "default" {
+		return ctx, func() { cancel(nil) }
+	}
+	renewEvery := br.ProjectManager.SettingDuration(project, "lease.ttl") / leaseRenewals
+
+	go func() {
+		ticker := time.NewTicker(taskStatusPoll)
+		defer ticker.Stop()
+		renewedAt := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if status, ok := br.taskStatus(project, taskID); ok && canonicalStatus(status) != "IN_PROGRESS" {
+				cancel(errTaskReleased)
+				return
+			}
+			if renewEvery <= 0 || time.Since(renewedAt) < renewEvery {
+				continue
+			}
+			// A failed renewal, e.g. on a busy lock, is retried next tick.
+			held, err := br.ProjectManager.RenewLease(project, taskID, agentID)
+			if err != nil {
+				continue
+			}
+			if !held {
+				cancel(errTaskReleased)
+				return
+			}
+			renewedAt = time.Now()
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
+// taskStatus returns the stored status of taskID. ok is false when it
+// cannot be read, so a transient load error does not stop a run.
+func (br *BackgroundRunner) taskStatus(project, taskID string) (status string, ok bool) {
+	views, _, err := br.ProjectManager.GetTaskViews(project)
+	if err != nil {
+		return "", false
+	}
+	for _, view := range views {
+		if view.ID == taskID {
+			return view.Status, true
+		}
+	}
+	return "", false
+}
+
+// recordEvent appends a task event that does not change its status.
+func (br *BackgroundRunner) recordEvent(project, agentID string, event Event) {
+	if br.ProjectManager == nil {
+		return
+	}
+	err := br.ProjectManager.Update(project, func(tx *ProjectTx) error {
+		tx.AppendEvent(event)
+		return nil
+	})
+	if err != nil {
+		br.logExecutionError(agentID, "Failed to record "+event.Type+" event", err, "")
+	}
+}
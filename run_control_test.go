@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTask_TimeoutFailsAndRetries(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks: []TaskV11{{
+			ID:          "t-1",
+			Name:        "hangs",
+			Status:      "TODO",
+			Behavior:    AgentBehavior{Command: "sleep 30", Timeout: "200ms"},
+			RetryPolicy: &RetryPolicy{MaxAttempts: 2, Backoff: "fixed"},
+			UpdatedAt:   time.Now(),
+		}},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if err := pdm.SetProjectSetting(projectName, "runner.kill_grace", "100ms"); err != nil {
+		t.Fatalf("set kill grace failed: %v", err)
+	}
+	if err := pdm.UpdateTaskStatus(projectName, "t-1", "IN_PROGRESS", "worker-1"); err != nil {
+		t.Fatalf("failed to set IN_PROGRESS: %v", err)
+	}
+	views, _, _ := pdm.GetTaskViews(projectName)
+
+	start := time.Now()
+	err := (&BackgroundRunner{ProjectManager: pdm}).RunTask(projectName, "worker-1", &views[0])
+	if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("timed-out run took %s", elapsed)
+	}
+
+	stored, err := pdm.eventStore(projectName).ReadAll()
+	if err != nil {
+		t.Fatalf("read events failed: %v", err)
+	}
+	var types []string
+	for _, event := range stored {
+		if event.TaskID == "t-1" {
+			types = append(types, event.Type)
+		}
+	}
+	got := strings.Join(types, " ")
+	if !strings.Contains(got, "TASK_TIMED_OUT TASK_STATUS_CHANGED") || !strings.Contains(got, "TASK_RETRY_SCHEDULED") {
+		t.Fatalf("expected a timeout followed by a failure and a retry, got %q", got)
+	}
+}
+
+func TestRunTask_CancelStopsRunningCommand(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+	defer func(poll time.Duration) { taskStatusPoll = poll }(taskStatusPoll)
+	taskStatusPoll = 10 * time.Millisecond
+
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	task := startLoopTask(t, pdm, projectName, AgentBehavior{Command: "sleep 30"})
+
+	done := make(chan error, 1)
+	go func() { done <- (&BackgroundRunner{ProjectManager: pdm}).RunTask(projectName, "worker-1", &task) }()
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := cancelTask(pdm, projectName, "t-1", "human"); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a cancelled run to end quietly, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("cancelling did not stop the running command")
+	}
+	if status := taskStatus(t, pdm, projectName, "t-1"); status != "CANCELLED" {
+		t.Fatalf("expected the task to stay CANCELLED, got %s", status)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runLogFooterPrefix starts the last line of a finished run log.
+const runLogFooterPrefix = "# exit "
+
+const runLogTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// runLogMaxLine bounds how much of a line a stream buffers while waiting
+// for its newline; longer lines are logged in pieces of this size.
+const runLogMaxLine = 64 << 10
+
+// runLog is the log of one attempt at a task, kept at
+// <project>/runs/<task>/<attempt>.log. Every output line is stamped with
+// its time and stream; a header records the command and a footer the exit
+// code. Output past maxBytes is dropped with a marker.
+type runLog struct {
+	mu        sync.Mutex
+	file      *os.File
+	attempt   int
+	maxBytes  int64
+	written   int64
+	truncated bool
+	started   time.Time
+}
+
+// runLogStream is one output stream of a run log. It buffers partial lines
+// until their newline arrives or they reach runLogMaxLine.
+type runLogStream struct {
+	log     *runLog
+	name    string
+	partial []byte
+}
+
+func (pdm *ProjectDataManager) runLogDir(projectName, taskID string) string {
+	return filepath.Join(pdm.dataDir, projectName, "runs", taskID)
+}
+
+func (pdm *ProjectDataManager) runLogPath(projectName, taskID string, attempt int) string {
+	return filepath.Join(pdm.runLogDir(projectName, taskID), fmt.Sprintf("%d.log", attempt))
+}
+
+// RunLogAttempts returns the attempt numbers with a log for taskID, oldest
+// first.
+func (pdm *ProjectDataManager) RunLogAttempts(projectName, taskID string) ([]int, error) {
+	entries, err := os.ReadDir(pdm.runLogDir(projectName, taskID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var attempts []int
+	for _, entry := range entries {
+		n, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".log"))
+		if err == nil && strings.HasSuffix(entry.Name(), ".log") && n > 0 {
+			attempts = append(attempts, n)
+		}
+	}
+	sort.Ints(attempts)
+	return attempts, nil
+}
+
+// latestRunLog returns the newest attempt at taskID and its log, or attempt
+// 0 when the task has not run.
+func (pdm *ProjectDataManager) latestRunLog(projectName, taskID string) (int, []byte, error) {
+	attempts, err := pdm.RunLogAttempts(projectName, taskID)
+	if err != nil || len(attempts) == 0 {
+		return 0, nil, err
+	}
+	attempt := attempts[len(attempts)-1]
+	content, err := os.ReadFile(pdm.runLogPath(projectName, taskID, attempt))
+	return attempt, content, err
+}
+
+// openRunLog starts the log for the next attempt at taskID and prunes the
+// oldest logs beyond runs.keep.
+func (pdm *ProjectDataManager) openRunLog(projectName, taskID, agentID, command string) (*runLog, error) {
+	attempts, err := pdm.RunLogAttempts(projectName, taskID)
+	if err != nil {
+		return nil, err
+	}
+	attempt := 1
+	if len(attempts) > 0 {
+		attempt = attempts[len(attempts)-1] + 1
+	}
+
+	if err := os.MkdirAll(pdm.runLogDir(projectName, taskID), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(pdm.runLogPath(projectName, taskID, attempt), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := pdm.SettingInt(projectName, "runs.keep")
+	if keep > 0 {
+		attempts = append(attempts, attempt)
+		for len(attempts) > keep {
+			_ = os.Remove(pdm.runLogPath(projectName, taskID, attempts[0]))
+			attempts = attempts[1:]
+		}
+	}
+
+	log := &runLog{
+		file:     file,
+		attempt:  attempt,
+		maxBytes: int64(pdm.SettingInt(projectName, "runs.max_log_bytes")),
+		started:  time.Now(),
+	}
+	fmt.Fprintf(file, "# task %s attempt %d by %s started %s\n", taskID, attempt, agentID, log.started.Format(runLogTimeFormat))
+	fmt.Fprintf(file, "# command: %s\n", command)
+	return log, nil
+}
+
+// Stream returns a writer that logs each line it receives under name, such
+// as "stdout". Streams are safe to use from separate goroutines.
+func (l *runLog) Stream(name string) io.Writer {
+	return &runLogStream{log: l, name: name}
+}
+
+func (s *runLogStream) Write(p []byte) (int, error) {
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		s.log.writeLine(s.name, s.partial[:i])
+		s.partial = s.partial[i+1:]
+	}
+	for len(s.partial) >= runLogMaxLine {
+		s.log.writeLine(s.name, s.partial[:runLogMaxLine])
+		s.partial = s.partial[runLogMaxLine:]
+	}
+	return len(p), nil
+}
+
+func (s *runLogStream) flush() {
+	if len(s.partial) > 0 {
+		s.log.writeLine(s.name, s.partial)
+		s.partial = nil
+	}
+}
+
+func (l *runLog) writeLine(stream string, line []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.truncated {
+		return
+	}
+	stamp := time.Now().Format(runLogTimeFormat)
+	if l.maxBytes > 0 && l.written+int64(len(line)) > l.maxBytes {
+		l.truncated = true
+		fmt.Fprintf(l.file, "%s quickplan | output truncated after %d bytes (runs.max_log_bytes)\n", stamp, l.written)
+		return
+	}
+	l.written += int64(len(line))
+	fmt.Fprintf(l.file, "%s %s | %s\n", stamp, stream, line)
+}
+
+// Close flushes any unterminated lines of streams, writes the footer with
+// runErr's exit code and closes the file.
+func (l *runLog) Close(runErr error, streams ...io.Writer) error {
+	for _, stream := range streams {
+		if s, ok := stream.(*runLogStream); ok {
+			s.flush()
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	footer := fmt.Sprintf("%s%d after %s", runLogFooterPrefix, exitCode(runErr), time.Since(l.started).Round(time.Millisecond))
+	if runErr != nil {
+		footer += ": " + strings.ReplaceAll(runErr.Error(), "\n", " ")
+	}
+	fmt.Fprintln(l.file, footer)
+	return l.file.Close()
+}
+
+// runLogFinished reports whether a log's last line is the footer.
+func runLogFinished(content []byte) bool {
+	content = bytes.TrimRight(content, "\n")
+	lastLine := content[bytes.LastIndexByte(content, '\n')+1:]
+	return bytes.HasPrefix(lastLine, []byte(runLogFooterPrefix))
+}
+
+// runLogTail returns the last n lines of a log.
+func runLogTail(content []byte, n int) []string {
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
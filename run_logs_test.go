@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunLog_StampsLinesAndRecordsExit(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	log, err := pdm.openRunLog(projectName, "t-1", "worker-1", "make test")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	stdout, stderr := log.Stream("stdout"), log.Stream("stderr")
+	stdout.Write([]byte("building\nhalf"))
+	stderr.Write([]byte("warning: slow\n"))
+	stdout.Write([]byte(" done\nno newline"))
+	if err := log.Close(errors.New("boom"), stdout, stderr); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	content, err := os.ReadFile(pdm.runLogPath(projectName, "t-1", 1))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	got := string(content)
+	for _, want := range []string{
+		"# task t-1 attempt 1 by worker-1 started ",
+		"# command: make test\n",
+		" stdout | building\n",
+		" stderr | warning: slow\n",
+		" stdout | half done\n",
+		" stdout | no newline\n",
+		"# exit -1 after ",
+		": boom\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("log is missing %q:\n%s", want, got)
+		}
+	}
+	if !runLogFinished(content) {
+		t.Fatalf("expected the log to end with its footer:\n%s", got)
+	}
+}
+
+func TestRunLog_TruncatesPastMaxBytes(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	if err := pdm.SetProjectSetting(projectName, "runs.max_log_bytes", "10"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+
+	log, err := pdm.openRunLog(projectName, "t-1", "worker-1", "yes")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	stdout := log.Stream("stdout")
+	stdout.Write([]byte("12345\n67890\nabcde\nfghij\n"))
+	log.Close(nil, stdout)
+
+	content, _ := os.ReadFile(pdm.runLogPath(projectName, "t-1", 1))
+	got := string(content)
+	if !strings.Contains(got, "| 67890\n") || strings.Contains(got, "abcde") {
+		t.Fatalf("expected output to stop at 10 bytes:\n%s", got)
+	}
+	if !strings.Contains(got, "output truncated after 10 bytes") || !strings.Contains(got, "# exit 0 after ") {
+		t.Fatalf("expected a truncation marker and footer:\n%s", got)
+	}
+}
+
+func TestRunLog_SplitsLinesWithoutNewline(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	if err := pdm.SetProjectSetting(projectName, "runs.max_log_bytes", "0"); err != nil {
+		t.Fatalf("expected 0 to be accepted for no limit: %v", err)
+	}
+
+	log, err := pdm.openRunLog(projectName, "t-1", "worker-1", "yes | tr -d '\\n'")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	stdout := log.Stream("stdout")
+	chunk := bytes.Repeat([]byte("y"), 4096)
+	for i := 0; i < 40; i++ {
+		stdout.Write(chunk)
+	}
+	if buffered := len(stdout.(*runLogStream).partial); buffered >= runLogMaxLine {
+		t.Fatalf("expected the unterminated line to be flushed in pieces, %d bytes buffered", buffered)
+	}
+	log.Close(nil, stdout)
+
+	content, _ := os.ReadFile(pdm.runLogPath(projectName, "t-1", 1))
+	if got := strings.Count(string(content), " stdout | "); got != 3 {
+		t.Fatalf("expected 160 KiB split into 3 lines, got %d", got)
+	}
+	if strings.Contains(string(content), "output truncated") {
+		t.Fatal("expected runs.max_log_bytes 0 to keep all output")
+	}
+}
+
+func TestOpenRunLog_NumbersAttemptsAndPrunes(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	if err := pdm.SetProjectSetting(projectName, "runs.keep", "2"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		log, err := pdm.openRunLog(projectName, "t-1", "worker-1", "true")
+		if err != nil {
+			t.Fatalf("open %d failed: %v", i, err)
+		}
+		log.Close(nil)
+	}
+
+	attempts, err := pdm.RunLogAttempts(projectName, "t-1")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 3 || attempts[1] != 4 {
+		t.Fatalf("expected attempts [3 4] to be kept, got %v", attempts)
+	}
+	attempt, content, err := pdm.latestRunLog(projectName, "t-1")
+	if err != nil || attempt != 4 || !bytes.Contains(content, []byte("attempt 4")) {
+		t.Fatalf("expected attempt 4 as the latest, got %d (%v)", attempt, err)
+	}
+}
+
+func TestRunTask_WritesRunLog(t *testing.T) {
+	t.Setenv("QUICKPLAN_DISABLE_LOCAL_SANDBOX", "1")
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	task := startLoopTask(t, pdm, projectName, AgentBehavior{Command: "echo out; echo err >&2; exit 3"})
+
+	if err := (&BackgroundRunner{ProjectManager: pdm}).RunTask(projectName, "worker-1", &task); err == nil {
+		t.Fatalf("expected the run to fail")
+	}
+
+	attempt, content, err := pdm.latestRunLog(projectName, "t-1")
+	if err != nil || attempt != 1 {
+		t.Fatalf("expected a log for attempt 1, got %d (%v)", attempt, err)
+	}
+	got := string(content)
+	for _, want := range []string{" stdout | out\n", " stderr | err\n", "# exit 3 after "} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("log is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFollowRunLog_ReturnsOnFooter(t *testing.T) {
+	defer func(poll time.Duration) { runLogFollowPoll = poll }(runLogFollowPoll)
+	runLogFollowPoll = 10 * time.Millisecond
+
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	log, err := pdm.openRunLog(projectName, "t-1", "worker-1", "long")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	stdout := log.Stream("stdout")
+	stdout.Write([]byte("first\n"))
+
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() { done <- followRunLog(context.Background(), &out, pdm.runLogPath(projectName, "t-1", 1)) }()
+
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("follow returned before the run finished: %v", err)
+	default:
+	}
+	stdout.Write([]byte("second\n"))
+	log.Close(nil, stdout)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("follow failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("follow did not return after the footer was written")
+	}
+	if got := out.String(); !strings.Contains(got, "| first\n") || !strings.Contains(got, "| second\n") || !strings.Contains(got, "# exit 0") {
+		t.Fatalf("unexpected followed output:\n%s", got)
+	}
+}
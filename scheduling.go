@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Scheduling policy names, as accepted by the scheduler.policy setting.
+const (
+	policyFIFO         = "fifo"
+	policyPriority     = "priority"
+	policyCriticalPath = "critical-path"
+)
+
+// SchedulingPolicy decides in which order runnable tasks are handed to
+// workers.
+type SchedulingPolicy interface {
+	Name() string
+	// Order returns candidates sorted best first. views holds every task in
+	// the project, for policies that look at the dependency graph.
+	Order(candidates, views []TaskView, now time.Time) []TaskView
+}
+
+// schedulingPolicyNames lists the policies newSchedulingPolicy knows.
+var schedulingPolicyNames = []string{policyFIFO, policyPriority, policyCriticalPath}
+
+// newSchedulingPolicy returns the policy called name. aging is how long a
+// task must wait to gain one priority level; zero disables aging.
+func newSchedulingPolicy(name string, aging time.Duration) (SchedulingPolicy, error) {
+	switch name {
+	case policyFIFO:
+		return fifoPolicy{}, nil
+	case policyPriority:
+		return priorityPolicy{aging: aging}, nil
+	case policyCriticalPath:
+		return criticalPathPolicy{aging: aging}, nil
+	}
+	return nil, fmt.Errorf("unknown scheduling policy %q (expected %s)", name, strings.Join(schedulingPolicyNames, ", "))
+}
+
+// schedulingPolicy returns the policy configured for projectName, falling
+// back to priority order if the setting is somehow invalid.
+func (pdm *ProjectDataManager) schedulingPolicy(projectName string) SchedulingPolicy {
+	aging := pdm.SettingDuration(projectName, "scheduler.aging")
+	policy, err := newSchedulingPolicy(pdm.Setting(projectName, "scheduler.policy").Value, aging)
+	if err != nil {
+		return priorityPolicy{aging: aging}
+	}
+	return policy
+}
+
+// runnableCandidates returns the runnable tasks in views that accept lets
+// through, in the order the project's scheduling policy prefers. Tasks
+// whose start date lies ahead are left for later.
+func (pdm *ProjectDataManager) runnableCandidates(projectName string, views []TaskView, accept func(TaskView) bool) []TaskView {
+	now := time.Now()
+	statusByID := buildStatusIndex(views)
+	var candidates []TaskView
+	for _, view := range views {
+		if view.Start != nil && view.Start.After(now) {
+			continue
+		}
+		if accept(view) && isTaskRunnable(view, statusByID) {
+			candidates = append(candidates, view)
+		}
+	}
+	return pdm.schedulingPolicy(projectName).Order(candidates, views, now)
+}
+
+// fifoPolicy keeps file order.
+type fifoPolicy struct{}
+
+func (fifoPolicy) Name() string { return policyFIFO }
+
+func (fifoPolicy) Order(candidates, views []TaskView, now time.Time) []TaskView {
+	return candidates
+}
+
+// priorityPolicy orders by effective priority, then by how many tasks wait
+// on the candidate, then by how long it has waited.
+type priorityPolicy struct {
+	aging time.Duration
+}
+
+func (p priorityPolicy) Name() string { return policyPriority }
+
+func (p priorityPolicy) Order(candidates, views []TaskView, now time.Time) []TaskView {
+	dependents := waitingDependents(views)
+	return sortCandidates(candidates, func(a, b TaskView) int {
+		if d := effectivePriority(a, p.aging, now) - effectivePriority(b, p.aging, now); d != 0 {
+			return d
+		}
+		if d := dependents[a.ID] - dependents[b.ID]; d != 0 {
+			return d
+		}
+		return olderFirst(a, b)
+	})
+}
+
+// criticalPathPolicy orders by how many tasks wait on the candidate, then
+// by effective priority, then by how long it has waited.
+type criticalPathPolicy struct {
+	aging time.Duration
+}
+
+func (p criticalPathPolicy) Name() string { return policyCriticalPath }
+
+func (p criticalPathPolicy) Order(candidates, views []TaskView, now time.Time) []TaskView {
+	dependents := waitingDependents(views)
+	return sortCandidates(candidates, func(a, b TaskView) int {
+		if d := dependents[a.ID] - dependents[b.ID]; d != 0 {
+			return d
+		}
+		if d := effectivePriority(a, p.aging, now) - effectivePriority(b, p.aging, now); d != 0 {
+			return d
+		}
+		return olderFirst(a, b)
+	})
+}
+
+// sortCandidates returns a copy of candidates sorted so that tasks for
+// which better(a, b) > 0 come first. Ties keep file order.
+func sortCandidates(candidates []TaskView, better func(a, b TaskView) int) []TaskView {
+	sorted := append([]TaskView{}, candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return better(sorted[i], sorted[j]) > 0
+	})
+	return sorted
+}
+
+// olderFirst prefers the task that has waited longer, i.e. whose status
+// last changed earlier.
+func olderFirst(a, b TaskView) int {
+	switch {
+	case a.UpdatedAt.Before(b.UpdatedAt):
+		return 1
+	case b.UpdatedAt.Before(a.UpdatedAt):
+		return -1
+	}
+	return 0
+}
+
+// priorityRank maps a priority to a level: low 0, unset or medium 1,
+// high 2, urgent 3.
+func priorityRank(priority string) int {
+	switch priority {
+	case "low":
+		return 0
+	case "high":
+		return 2
+	case "urgent":
+		return 3
+	}
+	return 1
+}
+
+// effectivePriority is the task's priority level plus one for every full
+// aging interval it has waited, so no task waits behind newer, more
+// important work forever. Aging lifts a task to high at most: only tasks
+// marked urgent outrank everything else.
+func effectivePriority(task TaskView, aging time.Duration, now time.Time) int {
+	rank := priorityRank(task.Priority)
+	if aging > 0 && !task.UpdatedAt.IsZero() && now.After(task.UpdatedAt) && rank < maxAgedRank {
+		rank += int(now.Sub(task.UpdatedAt) / aging)
+		if rank > maxAgedRank {
+			rank = maxAgedRank
+		}
+	}
+	return rank
+}
+
+// maxAgedRank is the highest level aging can lift a task to: high.
+var maxAgedRank = priorityRank("high")
+
+// waitingDependents counts, for every task, the unfinished tasks that
+// cannot start until it is done: those depending on it directly or
+// transitively, including those depending on one of its parents.
+func waitingDependents(views []TaskView) map[string]int {
+	statusByID := buildStatusIndex(views)
+	parentOf := make(map[string]string, len(views))
+	dependentsOf := make(map[string][]string)
+	for _, view := range views {
+		parentOf[view.ID] = view.Parent
+		for _, dep := range view.DependsOn {
+			dependentsOf[dep] = append(dependentsOf[dep], view.ID)
+		}
+	}
+
+	counts := make(map[string]int, len(views))
+	for _, view := range views {
+		seen := map[string]bool{view.ID: true}
+		queue := []string{view.ID}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			// A task blocks whatever waits on it and on its ancestors.
+			climbed := make(map[string]bool)
+			for blocker := id; blocker != "" && !climbed[blocker]; blocker = parentOf[blocker] {
+				climbed[blocker] = true
+				for _, dependent := range dependentsOf[blocker] {
+					if seen[dependent] {
+						continue
+					}
+					seen[dependent] = true
+					queue = append(queue, dependent)
+					switch statusByID[dependent] {
+					case "DONE", "CANCELLED":
+					default:
+						counts[view.ID]++
+					}
+				}
+			}
+		}
+	}
+	return counts
+}
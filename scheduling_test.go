@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func orderedIDs(policy SchedulingPolicy, candidates, views []TaskView, now time.Time) string {
+	var ids []string
+	for _, task := range policy.Order(candidates, views, now) {
+		ids = append(ids, task.ID)
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestSchedulingPolicies_Order(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	views := []TaskView{
+		{ID: "t-1", Status: "TODO", Priority: "low", UpdatedAt: now.Add(-3 * time.Minute)},
+		{ID: "t-2", Status: "TODO", UpdatedAt: now.Add(-2 * time.Minute)},
+		{ID: "t-3", Status: "TODO", Priority: "high", UpdatedAt: now.Add(-1 * time.Minute)},
+		{ID: "t-4", Status: "TODO", UpdatedAt: now.Add(-4 * time.Minute)},
+		{ID: "t-5", Status: "TODO", DependsOn: []string{"t-2"}},
+		{ID: "t-6", Status: "TODO", DependsOn: []string{"t-5"}},
+		{ID: "t-7", Status: "TODO", DependsOn: []string{"t-1"}},
+	}
+	candidates := views[:4]
+
+	cases := []struct {
+		policy SchedulingPolicy
+		want   string
+	}{
+		{fifoPolicy{}, "t-1 t-2 t-3 t-4"},
+		// high first; t-2 and t-4 are both medium, t-2 has two tasks waiting.
+		{priorityPolicy{}, "t-3 t-2 t-4 t-1"},
+		// t-2 unblocks two tasks, t-1 one; the rest by priority, then age.
+		{criticalPathPolicy{}, "t-2 t-1 t-3 t-4"},
+	}
+	for _, tc := range cases {
+		if got := orderedIDs(tc.policy, candidates, views, now); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.policy.Name(), tc.want, got)
+		}
+	}
+}
+
+func TestWaitingDependents_CountsTransitiveAndParentDependents(t *testing.T) {
+	views := []TaskView{
+		{ID: "t-1", Status: "TODO", Subtasks: []string{"t-2"}},
+		{ID: "t-2", Status: "TODO", Parent: "t-1"},
+		{ID: "t-3", Status: "TODO", DependsOn: []string{"t-1"}},
+		{ID: "t-4", Status: "TODO", DependsOn: []string{"t-3"}},
+		{ID: "t-5", Status: "DONE", DependsOn: []string{"t-2"}},
+	}
+	counts := waitingDependents(views)
+	if counts["t-2"] != 2 || counts["t-1"] != 2 || counts["t-3"] != 1 || counts["t-4"] != 0 {
+		t.Fatalf("unexpected dependent counts: %v", counts)
+	}
+}
+
+// TestPriorityPolicy_IsFairAmongEqualTasks requeues each served task, as a
+// retry does, and expects equal tasks to take turns.
+func TestPriorityPolicy_IsFairAmongEqualTasks(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	queue := []TaskView{
+		{ID: "t-1", Status: "PENDING", UpdatedAt: now.Add(-time.Minute)},
+		{ID: "t-2", Status: "PENDING", UpdatedAt: now.Add(-2 * time.Minute)},
+		{ID: "t-3", Status: "PENDING", UpdatedAt: now.Add(-3 * time.Minute)},
+	}
+	policy := priorityPolicy{aging: time.Hour}
+
+	var served []string
+	for i := 0; i < 6; i++ {
+		next := policy.Order(queue, queue, now)[0]
+		served = append(served, next.ID)
+		for j := range queue {
+			if queue[j].ID == next.ID {
+				queue[j].UpdatedAt = now
+			}
+		}
+		now = now.Add(time.Second)
+	}
+	if got := strings.Join(served, " "); got != "t-3 t-2 t-1 t-3 t-2 t-1" {
+		t.Fatalf("expected round-robin service, got %q", got)
+	}
+}
+
+// TestPriorityPolicy_AgingPreventsStarvation feeds a fresh high-priority
+// task every ten minutes and expects a waiting low-priority task to get its
+// turn.
+func TestPriorityPolicy_AgingPreventsStarvation(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	servedAt := func(policy SchedulingPolicy) int {
+		now := start
+		queue := []TaskView{{ID: "low", Status: "PENDING", Priority: "low", UpdatedAt: start}}
+		for step := 1; step <= 50; step++ {
+			now = now.Add(10 * time.Minute)
+			queue = append(queue, TaskView{ID: fmt.Sprintf("high-%d", step), Status: "PENDING", Priority: "high", UpdatedAt: now})
+			next := policy.Order(queue, queue, now)[0]
+			if next.ID == "low" {
+				return step
+			}
+			for i := range queue {
+				if queue[i].ID == next.ID {
+					queue = append(queue[:i], queue[i+1:]...)
+					break
+				}
+			}
+		}
+		return -1
+	}
+
+	if step := servedAt(priorityPolicy{}); step != -1 {
+		t.Fatalf("expected the low task to starve without aging, served at step %d", step)
+	}
+	// Two aging intervals lift low to high; it is then the oldest.
+	if step := servedAt(priorityPolicy{aging: 30 * time.Minute}); step < 1 || step > 6 {
+		t.Fatalf("expected aging to serve the low task within an hour, got step %d", step)
+	}
+}
+
+// TestPriorityPolicy_UrgentBeatsAgedTasks expects a fresh urgent task to go
+// before a low task that has waited far longer than the aging interval.
+func TestPriorityPolicy_UrgentBeatsAgedTasks(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	queue := []TaskView{
+		{ID: "low", Status: "PENDING", Priority: "low", UpdatedAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: "high", Status: "PENDING", Priority: "high", UpdatedAt: now.Add(-time.Minute)},
+		{ID: "urgent", Status: "PENDING", Priority: "urgent", UpdatedAt: now},
+	}
+	order := priorityPolicy{aging: time.Hour}.Order(queue, queue, now)
+	if got := []string{order[0].ID, order[1].ID, order[2].ID}; strings.Join(got, " ") != "urgent low high" {
+		t.Fatalf("expected urgent first and the aged low task level with high, got %v", got)
+	}
+}
+
+func TestClaimNextRunnableTask_FollowsProjectPolicy(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	v11 := &ProjectV11{
+		SchemaVersion: schemaV12,
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks: []TaskV11{
+			{ID: "t-1", Name: "chore", Status: "TODO", Priority: "low", UpdatedAt: time.Now()},
+			{ID: "t-2", Name: "hotfix", Status: "TODO", Priority: "urgent", UpdatedAt: time.Now()},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	claimed, err := pdm.ClaimNextRunnableTask(projectName, "worker-1")
+	if err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if claimed == nil || claimed.ID != "t-2" {
+		t.Fatalf("expected the urgent task first, got %+v", claimed)
+	}
+
+	if err := pdm.SetProjectSetting(projectName, "scheduler.policy", "bogus"); err == nil {
+		t.Fatalf("expected an unknown policy to be rejected")
+	}
+	if err := pdm.SetProjectSetting(projectName, "scheduler.policy", policyFIFO); err != nil {
+		t.Fatalf("set policy failed: %v", err)
+	}
+	if got := pdm.schedulingPolicy(projectName).Name(); got != policyFIFO {
+		t.Fatalf("expected fifo policy, got %s", got)
+	}
+}
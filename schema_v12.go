@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	schemaV11 = "1.1"
+	schemaV12 = "1.2"
+	schemaV13 = "1.3"
+)
+
+// taskSchemaField is an optional task key added to project.yaml after
+// schema 1.1.
+type taskSchemaField struct {
+	Key   string
+	Since string // schema version that introduced the key
+	IsSet func(task TaskV11) bool
+}
+
+// taskSchemaFields lists every task key added after schema 1.1. Validation,
+// the JSON Schema, rollbacks and migration descriptions derive from it. A
+// new key needs a new schema version and migration step, so readers built
+// before it reject the file instead of silently dropping the key.
+var taskSchemaFields = []taskSchemaField{
+	{"priority", schemaV12, func(t TaskV11) bool { return t.Priority != "" }},
+	{"due", schemaV12, func(t TaskV11) bool { return t.Due != nil }},
+	{"labels", schemaV12, func(t TaskV11) bool { return len(t.Labels) > 0 }},
+	{"estimate", schemaV12, func(t TaskV11) bool { return t.Estimate != "" }},
+	{"description", schemaV12, func(t TaskV11) bool { return t.Description != "" }},
+	{"start", schemaV13, func(t TaskV11) bool { return t.Start != nil }},
+	{"parent", schemaV13, func(t TaskV11) bool { return t.Parent != "" }},
+	{"recurrence", schemaV13, func(t TaskV11) bool { return t.Recurrence != nil }},
+	{"recurs_from", schemaV13, func(t TaskV11) bool { return t.RecursFrom != "" }},
+	{"overdue_notified", schemaV13, func(t TaskV11) bool { return t.OverdueNotified != nil }},
+}
+
+// taskFieldKeysAddedIn returns the task keys schema version introduced.
+func taskFieldKeysAddedIn(version string) []string {
+	var keys []string
+	for _, field := range taskSchemaFields {
+		if field.Since == version {
+			keys = append(keys, field.Key)
+		}
+	}
+	return keys
+}
+
+// taskFieldKeysAfter returns the task keys a project at schema version
+// cannot hold.
+func taskFieldKeysAfter(version string) []string {
+	var keys []string
+	for _, field := range taskSchemaFields {
+		if versionLess(version, field.Since) {
+			keys = append(keys, field.Key)
+		}
+	}
+	return keys
+}
+
+// supportedSchemaVersions lists the project.yaml schema versions this build
+// reads, oldest first.
+func supportedSchemaVersions() []string {
+	versions := []string{schemaV11}
+	for _, m := range migrationRegistry {
+		if m.File == "project.yaml" {
+			versions = append(versions, m.To)
+		}
+	}
+	return versions
+}
+
+// taskPriorities lists the accepted priority values, lowest first.
+var taskPriorities = []string{"low", "medium", "high", "urgent"}
+
+// isSupportedSchemaVersion reports whether project.yaml at version can be
+// loaded: 1.1 or any version the migration registry produces. All share the
+// ProjectV11 shape; later versions add optional task keys.
+func isSupportedSchemaVersion(version string) bool {
+	for _, v := range supportedSchemaVersions() {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidPriority(priority string) bool {
+	for _, p := range taskPriorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEstimate accepts Go durations (90m, 4h) plus whole days and weeks
+// (3d, 2w), the units people actually estimate in.
+func parseEstimate(estimate string) (time.Duration, error) {
+	estimate = strings.TrimSpace(estimate)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(estimate, suffix); ok {
+			value, err := strconv.ParseFloat(n, 64)
+			if err != nil || value < 0 {
+				return 0, fmt.Errorf("invalid estimate %q", estimate)
+			}
+			return time.Duration(value * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(estimate)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid estimate %q (use e.g. 90m, 4h, 3d, 2w)", estimate)
+	}
+	return d, nil
+}
+
+// parseStartDate accepts RFC3339 timestamps or plain dates, which mean the
+// start of that day in local time.
+func parseStartDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid start date %q (use 2026-03-01 or RFC3339)", value)
+}
+
+// parseDueDate accepts RFC3339 timestamps or plain dates, which mean the
+// end of that day in local time.
+func parseDueDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q (use 2026-03-01 or RFC3339)", value)
+}
+
+// validateTaskSchemaFields checks the task keys added after schema 1.1. A
+// key newer than schemaVersion is an error, so older readers never silently
+// drop it.
+func validateTaskSchemaFields(schemaVersion string, task TaskV11) error {
+	return firstError(taskSchemaFindings(schemaVersion, -1, task))
+}
+
+// taskSchemaFindings reports every problem with the keys added after schema
+// 1.1 of the task at index taskIndex.
+func taskSchemaFindings(schemaVersion string, taskIndex int, task TaskV11) []finding {
+	var findings []finding
+	for _, field := range taskSchemaFields {
+		if field.IsSet(task) && versionLess(schemaVersion, field.Since) {
+			findings = append(findings, errorAt(taskIndex, field.Key, -2, "schema-field-too-new",
+				"task %s uses %s, which requires schema %s (run 'quickplan migrate up')", task.ID, field.Key, field.Since))
+		}
+	}
+	if len(findings) > 0 {
+		return findings
+	}
+
+	if task.Priority != "" && !isValidPriority(task.Priority) {
+		findings = append(findings, errorAt(taskIndex, "priority", -1, "task-priority-invalid",
+			"invalid priority for task %s: %s (expected one of %s)", task.ID, task.Priority, strings.Join(taskPriorities, ", ")))
+	}
+	if task.Estimate != "" {
+		if _, err := parseEstimate(task.Estimate); err != nil {
+			findings = append(findings, errorAt(taskIndex, "estimate", -1, "task-estimate-invalid", "task %s: %v", task.ID, err))
+		}
+	}
+	if task.Start != nil && task.Due != nil && task.Start.After(*task.Due) {
+		findings = append(findings, warningAt(taskIndex, "start", -1, "task-start-after-due",
+			"task %s starts after it is due", task.ID))
+	}
+	seen := make(map[string]bool, len(task.Labels))
+	for i, label := range task.Labels {
+		if strings.TrimSpace(label) == "" {
+			findings = append(findings, errorAt(taskIndex, "labels", i, "task-label-empty", "task %s has an empty label", task.ID))
+		} else if seen[label] {
+			findings = append(findings, errorAt(taskIndex, "labels", i, "task-label-duplicate", "task %s has duplicate label %s", task.ID, label))
+		}
+		seen[label] = true
+	}
+	return findings
+}
+
+// UpgradeProjectToV12 rewrites a 1.1 project.yaml as 1.2 through the
+// migration registry, so comments, key order and fields this build does not
+// know about survive and the step can be rolled back. It returns the
+// upgraded document; with dryRun nothing is written.
+func (pdm *ProjectDataManager) UpgradeProjectToV12(projectName string, dryRun bool) ([]byte, error) {
+	v11File := filepath.Join(pdm.dataDir, projectName, "project.yaml")
+	data, err := os.ReadFile(v11File)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		SchemaVersion string `yaml:"schema_version"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse project.yaml: %w", err)
+	}
+	switch probe.SchemaVersion {
+	case schemaV12:
+		return nil, fmt.Errorf("project '%s' is already schema 1.2", projectName)
+	case schemaV11:
+	default:
+		return nil, fmt.Errorf("cannot upgrade schema %s to 1.2", probe.SchemaVersion)
+	}
+
+	out, _, err := pdm.MigrateProject(projectName, schemaV12, dryRun)
+	return out, err
+}
+
+// mappingValue returns the value node for key in a YAML mapping node.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
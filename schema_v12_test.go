@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateProjectV11_SchemaV12Fields(t *testing.T) {
+	due := time.Date(2026, 3, 1, 23, 59, 59, 0, time.UTC)
+	task := TaskV11{ID: "t-1", Name: "one", Status: "TODO", Priority: "high", Due: &due, Labels: []string{"backend"}, Estimate: "3d"}
+
+	v11 := &ProjectV11{SchemaVersion: schemaV11, Project: ProjectMeta{Name: "p"}, Tasks: []TaskV11{task}}
+	if err := ValidateProjectV11(v11); err == nil {
+		t.Fatal("expected 1.2 fields to be rejected on a 1.1 project")
+	}
+
+	v11.SchemaVersion = schemaV12
+	if err := ValidateProjectV11(v11); err != nil {
+		t.Fatalf("expected valid 1.2 project, got %v", err)
+	}
+
+	bad := []TaskV11{
+		{ID: "t-1", Name: "one", Status: "TODO", Priority: "critical"},
+		{ID: "t-1", Name: "one", Status: "TODO", Estimate: "soon"},
+		{ID: "t-1", Name: "one", Status: "TODO", Labels: []string{"a", "a"}},
+	}
+	for _, task := range bad {
+		v11.Tasks = []TaskV11{task}
+		if err := ValidateProjectV11(v11); err == nil {
+			t.Fatalf("expected validation error for %+v", task)
+		}
+	}
+}
+
+func TestParseEstimate(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90m": 90 * time.Minute,
+		"4h":  4 * time.Hour,
+		"3d":  72 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+	}
+	for input, want := range cases {
+		got, err := parseEstimate(input)
+		if err != nil || got != want {
+			t.Fatalf("parseEstimate(%q) = %s, %v; want %s", input, got, err, want)
+		}
+	}
+	if _, err := parseEstimate("-1d"); err == nil {
+		t.Fatal("expected error for negative estimate")
+	}
+}
+
+func TestUpgradeProjectToV12_PreservesDocument(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	original := `# Team roadmap
+schema_version: "1.1"
+project:
+  name: demo
+custom_field: keep-me # not known to this build
+tasks:
+  - id: t-1
+    name: one
+    status: TODO
+revision: 4
+`
+	v11File := filepath.Join(pdm.dataDir, projectName, "project.yaml")
+	if err := os.WriteFile(v11File, []byte(original), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	if _, err := pdm.UpgradeProjectToV12(projectName, false); err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+
+	data, err := os.ReadFile(v11File)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	upgraded := string(data)
+	for _, want := range []string{"# Team roadmap", `schema_version: "1.2"`, "custom_field: keep-me # not known to this build", "revision: 5"} {
+		if !strings.Contains(upgraded, want) {
+			t.Fatalf("upgraded project.yaml missing %q:\n%s", want, upgraded)
+		}
+	}
+
+	loaded, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load after upgrade failed: %v", err)
+	}
+	if loaded.SchemaVersion != schemaV12 {
+		t.Fatalf("expected schema 1.2, got %s", loaded.SchemaVersion)
+	}
+
+	if _, err := pdm.UpgradeProjectToV12(projectName, false); err == nil {
+		t.Fatal("expected second upgrade to fail")
+	}
+}
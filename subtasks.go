@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// rollupActor is the event actor for statuses derived from subtasks.
+const rollupActor = "system:rollup"
+
+// subtaskIndex maps each parent ID to the indices of its direct subtasks,
+// in task order. Parents that do not exist are ignored; validation reports
+// them.
+func subtaskIndex(tasks []TaskV11) map[string][]int {
+	ids := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		ids[task.ID] = true
+	}
+	children := make(map[string][]int)
+	for i, task := range tasks {
+		if task.Parent != "" && task.Parent != task.ID && ids[task.Parent] {
+			children[task.Parent] = append(children[task.Parent], i)
+		}
+	}
+	return children
+}
+
+// retriesExhausted reports whether a failed task will stay failed, i.e. it
+// has no retry policy or has used every attempt.
+func retriesExhausted(task TaskV11) bool {
+	policy := task.RetryPolicy
+	return policy == nil || policy.MaxAttempts <= 0 || task.Attempts >= policy.MaxAttempts
+}
+
+// deriveParentStatus computes a parent's status from its direct subtasks:
+//   - FAILED once any subtask failed past its retries,
+//   - DONE when every subtask is DONE or CANCELLED and at least one is DONE,
+//     CANCELLED when all are cancelled,
+//   - IN_PROGRESS once any subtask has started,
+//   - BLOCKED when every open subtask is blocked, PENDING otherwise.
+func deriveParentStatus(children []TaskV11) string {
+	var done, cancelled, started, blocked int
+	for _, child := range children {
+		switch canonicalStatus(child.Status) {
+		case "FAILED":
+			if retriesExhausted(child) {
+				return "FAILED"
+			}
+			started++
+		case "DONE":
+			done++
+		case "CANCELLED":
+			cancelled++
+		case "IN_PROGRESS", "RETRYING":
+			started++
+		case "BLOCKED":
+			blocked++
+		}
+	}
+
+	switch {
+	case done+cancelled == len(children) && done > 0:
+		return "DONE"
+	case cancelled == len(children):
+		return "CANCELLED"
+	case started > 0 || done > 0:
+		return "IN_PROGRESS"
+	case blocked > 0 && blocked+cancelled == len(children):
+		return "BLOCKED"
+	}
+	return "PENDING"
+}
+
+// rollUpParents sets every parent's status from its subtasks, deepest
+// parents first, and returns a TASK_ROLLED_UP event for each parent whose
+// status changed. Cycles in parent links are left alone; validation
+// rejects them.
+func rollUpParents(project *ProjectV11) []Event {
+	children := subtaskIndex(project.Tasks)
+	if len(children) == 0 {
+		return nil
+	}
+
+	const (
+		visiting = 1
+		resolved = 2
+	)
+	state := make(map[string]int, len(children))
+	now := time.Now()
+	var events []Event
+
+	var resolve func(i int)
+	resolve = func(i int) {
+		task := &project.Tasks[i]
+		kids := children[task.ID]
+		if len(kids) == 0 || state[task.ID] != 0 {
+			return
+		}
+		state[task.ID] = visiting
+		subtasks := make([]TaskV11, 0, len(kids))
+		done := 0
+		for _, k := range kids {
+			resolve(k)
+			subtasks = append(subtasks, project.Tasks[k])
+			if project.Tasks[k].Status == "DONE" {
+				done++
+			}
+		}
+		state[task.ID] = resolved
+
+		next := deriveParentStatus(subtasks)
+		if canonicalStatus(next) == canonicalStatus(task.Status) {
+			return
+		}
+		events = append(events, Event{
+			Timestamp:  now,
+			Type:       "TASK_ROLLED_UP",
+			Actor:      rollupActor,
+			TaskID:     task.ID,
+			PrevStatus: task.Status,
+			NextStatus: next,
+			Message:    fmt.Sprintf("Derived from subtasks: %d/%d done", done, len(kids)),
+		})
+		task.Status = next
+		task.UpdatedAt = now
+	}
+
+	for i := range project.Tasks {
+		resolve(i)
+	}
+	return events
+}
+
+// taskParentFindings reports parent links that point nowhere or form a
+// loop, subtasks that depend on one of their own ancestors (which could
+// never finish), and parent fields that have no effect.
+func taskParentFindings(tasks []TaskV11) []finding {
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		if _, ok := index[task.ID]; !ok {
+			index[task.ID] = i
+		}
+	}
+	children := subtaskIndex(tasks)
+
+	var findings []finding
+	for i, task := range tasks {
+		if task.Parent == "" {
+			continue
+		}
+		if task.Parent == task.ID {
+			findings = append(findings, errorAt(i, "parent", -1, "task-parent-self", "task %s cannot be its own parent", task.ID))
+			continue
+		}
+		if _, ok := index[task.Parent]; !ok {
+			findings = append(findings, errorAt(i, "parent", -1, "task-parent-missing",
+				"task %s has non-existent parent %s", task.ID, task.Parent))
+			continue
+		}
+
+		// Walk up the ancestors; meeting the task again means a loop.
+		ancestors := make(map[string]bool)
+		for id := task.Parent; id != ""; id = tasks[index[id]].Parent {
+			if id == task.ID {
+				findings = append(findings, errorAt(i, "parent", -1, "task-parent-cycle",
+					"task %s is its own ancestor", task.ID))
+				break
+			}
+			if ancestors[id] {
+				break
+			}
+			if _, ok := index[id]; !ok {
+				break
+			}
+			ancestors[id] = true
+		}
+		for j, dep := range task.DependsOn {
+			if ancestors[dep] {
+				findings = append(findings, errorAt(i, "depends_on", j, "task-parent-dependency",
+					"task %s depends on its parent %s, which cannot finish before it", task.ID, dep))
+			}
+		}
+	}
+
+	for i, task := range tasks {
+		if len(children[task.ID]) == 0 {
+			continue
+		}
+		if task.Behavior.Command != "" || task.Behavior.Plugin != "" {
+			findings = append(findings, warningAt(i, "behavior", -1, "task-parent-executable",
+				"task %s has subtasks, so its command or plugin is never run", task.ID))
+		}
+		if len(task.DependsOn) > 0 {
+			findings = append(findings, warningAt(i, "depends_on", -1, "task-parent-depends-on",
+				"task %s has subtasks, so its depends_on is not enforced; put it on the subtasks", task.ID))
+		}
+	}
+	return findings
+}
+
+// taskTreeRow is one line of a task tree: the task and the indentation
+// drawn before it.
+type taskTreeRow struct {
+	Task   TaskView
+	Prefix string
+}
+
+// taskTree orders views depth-first under their parents, keeping task
+// order among siblings. Top-level rows have an empty prefix.
+func taskTree(views []TaskView) []taskTreeRow {
+	index := make(map[string]int, len(views))
+	for i, view := range views {
+		index[view.ID] = i
+	}
+
+	rows := make([]taskTreeRow, 0, len(views))
+	placed := make(map[string]bool, len(views))
+	var walk func(i int, prefix, childPrefix string)
+	walk = func(i int, prefix, childPrefix string) {
+		view := views[i]
+		if placed[view.ID] {
+			return
+		}
+		placed[view.ID] = true
+		rows = append(rows, taskTreeRow{Task: view, Prefix: prefix})
+
+		var kids []int
+		for _, id := range view.Subtasks {
+			if k, ok := index[id]; ok && !placed[id] {
+				kids = append(kids, k)
+			}
+		}
+		for n, k := range kids {
+			if n == len(kids)-1 {
+				walk(k, childPrefix+"└─ ", childPrefix+"   ")
+			} else {
+				walk(k, childPrefix+"├─ ", childPrefix+"│  ")
+			}
+		}
+	}
+
+	for i, view := range views {
+		if _, ok := index[view.Parent]; view.Parent == "" || !ok {
+			walk(i, "", "")
+		}
+	}
+	// Anything left sits on a parent loop; show it flat rather than drop it.
+	for i := range views {
+		walk(i, "", "")
+	}
+	return rows
+}
+
+// hasSubtasks reports whether any view has a parent.
+func hasSubtasks(views []TaskView) bool {
+	for _, view := range views {
+		if view.Parent != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// subtaskProgress formats how many of a parent's subtasks are done.
+func subtaskProgress(view TaskView, statusByID map[string]string) string {
+	done := 0
+	for _, id := range view.Subtasks {
+		if statusByID[id] == "DONE" {
+			done++
+		}
+	}
+	return fmt.Sprintf("%d/%d", done, len(view.Subtasks))
+}
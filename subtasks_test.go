@@ -0,0 +1,180 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// seedSubtaskProject saves a 1.3 project where t-1 groups t-2 and t-3,
+// and t-3 groups t-4 and t-5.
+func seedSubtaskProject(t *testing.T, pdm *ProjectDataManager, projectName string) {
+	t.Helper()
+	v11 := &ProjectV11{
+		SchemaVersion: schemaV13,
+		Project:       ProjectMeta{Name: projectName, CreatedAt: time.Now()},
+		Tasks: []TaskV11{
+			{ID: "t-1", Name: "release", Status: "TODO"},
+			{ID: "t-2", Name: "changelog", Status: "TODO", Parent: "t-1"},
+			{ID: "t-3", Name: "build", Status: "TODO", Parent: "t-1"},
+			{ID: "t-4", Name: "linux", Status: "TODO", Parent: "t-3"},
+			{ID: "t-5", Name: "mac", Status: "TODO", Parent: "t-3"},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("seed save failed: %v", err)
+	}
+}
+
+func TestDeriveParentStatus(t *testing.T) {
+	exhausted := &RetryPolicy{MaxAttempts: 2}
+	cases := []struct {
+		name     string
+		children []TaskV11
+		want     string
+	}{
+		{"all open", []TaskV11{{Status: "TODO"}, {Status: "PENDING"}}, "PENDING"},
+		{"one started", []TaskV11{{Status: "IN_PROGRESS"}, {Status: "TODO"}}, "IN_PROGRESS"},
+		{"partly done", []TaskV11{{Status: "DONE"}, {Status: "TODO"}}, "IN_PROGRESS"},
+		{"all done", []TaskV11{{Status: "DONE"}, {Status: "DONE"}}, "DONE"},
+		{"done or cancelled", []TaskV11{{Status: "DONE"}, {Status: "CANCELLED"}}, "DONE"},
+		{"all cancelled", []TaskV11{{Status: "CANCELLED"}}, "CANCELLED"},
+		{"all blocked", []TaskV11{{Status: "BLOCKED"}, {Status: "BLOCKED"}}, "BLOCKED"},
+		{"failed without retries", []TaskV11{{Status: "DONE"}, {Status: "FAILED"}}, "FAILED"},
+		{"failed with retries left", []TaskV11{{Status: "FAILED", RetryPolicy: exhausted, Attempts: 1}}, "IN_PROGRESS"},
+		{"failed past retries", []TaskV11{{Status: "FAILED", RetryPolicy: exhausted, Attempts: 2}}, "FAILED"},
+	}
+	for _, tc := range cases {
+		if got := deriveParentStatus(tc.children); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRollUpParents_FollowsNestedSubtasks(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedSubtaskProject(t, pdm, projectName)
+
+	for _, id := range []string{"t-2", "t-4", "t-5"} {
+		if err := pdm.UpdateTaskStatus(projectName, id, "IN_PROGRESS", "worker-1"); err != nil {
+			t.Fatalf("start %s failed: %v", id, err)
+		}
+		if err := pdm.UpdateTaskStatus(projectName, id, "DONE", "worker-1"); err != nil {
+			t.Fatalf("complete %s failed: %v", id, err)
+		}
+	}
+
+	v11, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	for _, task := range v11.Tasks {
+		if task.Status != "DONE" {
+			t.Fatalf("expected every task DONE, %s is %s", task.ID, task.Status)
+		}
+	}
+
+	stored, err := pdm.eventStore(projectName).ReadAll()
+	if err != nil {
+		t.Fatalf("read events failed: %v", err)
+	}
+	var rolled []string
+	for _, event := range stored {
+		if event.Type == "TASK_ROLLED_UP" {
+			rolled = append(rolled, event.TaskID+":"+event.NextStatus)
+		}
+	}
+	want := "t-1:IN_PROGRESS t-3:IN_PROGRESS t-3:DONE t-1:DONE"
+	if got := strings.Join(rolled, " "); got != want {
+		t.Fatalf("expected roll-up events %q, got %q", want, got)
+	}
+}
+
+func TestParentTasks_AreNotExecuted(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+	seedSubtaskProject(t, pdm, projectName)
+
+	if err := pdm.UpdateTaskStatus(projectName, "t-1", "DONE", "human"); err == nil {
+		t.Fatalf("expected setting a parent's status to fail")
+	}
+
+	claimed := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		task, err := pdm.ClaimNextRunnableTask(projectName, "worker-1")
+		if err != nil {
+			t.Fatalf("claim failed: %v", err)
+		}
+		if task == nil {
+			break
+		}
+		claimed[task.ID] = true
+	}
+	if len(claimed) != 3 || claimed["t-1"] || claimed["t-3"] {
+		t.Fatalf("expected only leaf tasks to be claimed, got %v", claimed)
+	}
+
+	snapshot, err := pdm.GetExecutionSnapshot(projectName)
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	if snapshot.Groups != 2 || snapshot.InProgress != 3 || snapshot.Total != 5 {
+		t.Fatalf("unexpected snapshot: %s", snapshot.Summary())
+	}
+}
+
+func TestValidateProjectV11_ParentRules(t *testing.T) {
+	cases := []struct {
+		name  string
+		tasks []TaskV11
+		want  string
+	}{
+		{"missing", []TaskV11{{ID: "t-1", Status: "TODO", Parent: "t-9"}}, "non-existent parent"},
+		{"self", []TaskV11{{ID: "t-1", Status: "TODO", Parent: "t-1"}}, "its own parent"},
+		{"loop", []TaskV11{
+			{ID: "t-1", Status: "TODO", Parent: "t-2"},
+			{ID: "t-2", Status: "TODO", Parent: "t-1"},
+		}, "its own ancestor"},
+		{"depends on ancestor", []TaskV11{
+			{ID: "t-1", Status: "TODO"},
+			{ID: "t-2", Status: "TODO", Parent: "t-1", DependsOn: []string{"t-1"}},
+		}, "depends on its parent"},
+	}
+	for _, tc := range cases {
+		project := &ProjectV11{SchemaVersion: schemaV13, Tasks: tc.tasks}
+		err := ValidateProjectV11(project)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	for _, version := range []string{schemaV11, schemaV12} {
+		project := &ProjectV11{SchemaVersion: version, Tasks: []TaskV11{
+			{ID: "t-1", Status: "TODO"},
+			{ID: "t-2", Status: "TODO", Parent: "t-1"},
+		}}
+		if err := ValidateProjectV11(project); err == nil || !strings.Contains(err.Error(), "requires schema 1.3") {
+			t.Fatalf("expected parent to require schema 1.3 in a %s project, got %v", version, err)
+		}
+	}
+}
+
+func TestTaskTree_NestsSubtasksInOrder(t *testing.T) {
+	project := &ProjectV11{Tasks: []TaskV11{
+		{ID: "t-1"},
+		{ID: "t-2", Parent: "t-1"},
+		{ID: "t-3"},
+		{ID: "t-4", Parent: "t-1"},
+		{ID: "t-5", Parent: "t-2"},
+	}}
+
+	var got []string
+	for _, row := range taskTree(taskViewsFromV11(project)) {
+		got = append(got, row.Prefix+row.Task.ID)
+	}
+	want := []string{"t-1", "├─ t-2", "│  └─ t-5", "└─ t-4", "t-3"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected tree:\n%s", strings.Join(got, "\n"))
+	}
+}
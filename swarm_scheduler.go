@@ -16,53 +16,62 @@ type ExecutionSnapshot struct {
 	Failed      int
 	Cancelled   int
 	Runnable    int
+	Groups      int // parents of subtasks, counted in Total only
 	AllTerminal bool
 }
 
 func (s ExecutionSnapshot) Summary() string {
-	return fmt.Sprintf(
+	summary := fmt.Sprintf(
 		"total=%d done=%d failed=%d cancelled=%d pending=%d blocked=%d in_progress=%d retrying=%d runnable=%d",
 		s.Total, s.Done, s.Failed, s.Cancelled, s.Pending, s.Blocked, s.InProgress, s.Retrying, s.Runnable,
 	)
+	if s.Groups > 0 {
+		summary += fmt.Sprintf(" groups=%d", s.Groups)
+	}
+	return summary
 }
 
-// ClaimNextRunnableTask attempts to claim one runnable task for an agent.
+// ClaimNextRunnableTask attempts to claim one runnable task for an agent,
+// trying candidates in the order of the project's scheduling policy.
 // The claim is done through an IN_PROGRESS transition, so transition validation
-// and task readiness checks remain centralized in UpdateTaskStatus.
+// and task readiness checks remain centralized in SetStatus; it also takes a
+// lease the agent must renew (see ClaimTask). Candidates are picked without
+// holding the project lock and the claim is replayed if another worker saved
+// the project in the meantime.
 func (pdm *ProjectDataManager) ClaimNextRunnableTask(projectName, agentID string) (*TaskView, error) {
 	if _, err := pdm.ReconcileTaskReadiness(projectName, "swarm"); err != nil {
 		return nil, err
 	}
 
-	views, _, err := pdm.GetTaskViews(projectName)
+	ttl := pdm.SettingDuration(projectName, "lease.ttl")
+	var claimed *TaskView
+	err := retryOnConflict(func() error {
+		claimed = nil
+		return pdm.UpdateAtRevision(projectName, func(tx *ProjectTx) error {
+			candidates := pdm.runnableCandidates(projectName, tx.Views(), func(view TaskView) bool {
+				return view.AssignedTo == "" || view.AssignedTo == agentID
+			})
+			for _, view := range candidates {
+				if claimErr := tx.claim(view.ID, agentID, ttl); claimErr != nil {
+					if isClaimConflict(claimErr) {
+						continue
+					}
+					return claimErr
+				}
+
+				task := view
+				task.Status = "IN_PROGRESS"
+				task.AssignedTo = agentID
+				claimed = &task
+				return nil
+			}
+			return nil
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
-	statusByID := buildStatusIndex(views)
-
-	for _, view := range views {
-		if view.AssignedTo != "" && view.AssignedTo != agentID {
-			continue
-		}
-		if !isTaskRunnable(view, statusByID) {
-			continue
-		}
-
-		claimErr := pdm.UpdateTaskStatus(projectName, view.ID, "IN_PROGRESS", agentID)
-		if claimErr != nil {
-			if isClaimConflict(claimErr) {
-				continue
-			}
-			return nil, claimErr
-		}
-
-		claimed := view
-		claimed.Status = "IN_PROGRESS"
-		claimed.AssignedTo = agentID
-		return &claimed, nil
-	}
-
-	return nil, nil
+	return claimed, nil
 }
 
 // GetExecutionSnapshot reports aggregate execution state for swarm scheduling.
@@ -80,6 +89,13 @@ func (pdm *ProjectDataManager) GetExecutionSnapshot(projectName string) (Executi
 	statusByID := buildStatusIndex(views)
 
 	for _, view := range views {
+		// A parent's status mirrors its subtasks; counting it too would
+		// hide a stall behind a parent that looks IN_PROGRESS.
+		if len(view.Subtasks) > 0 {
+			snapshot.Groups++
+			continue
+		}
+
 		switch canonicalStatus(view.Status) {
 		case "DONE":
 			snapshot.Done++
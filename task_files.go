@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Storage layouts for v1.1 and later projects. The single-file layout keeps
+// every task in project.yaml. The task-files layout keeps one file per task
+// under tasks/, so edits to different tasks on two git branches merge cleanly.
+const (
+	layoutSingleFile = "single-file"
+	layoutTaskFiles  = "task-files"
+
+	taskFilesDirName = "tasks"
+	// projectStateFile holds what a task-files project changes on every
+	// save (revision, updated_at), keeping that churn out of project.yaml.
+	projectStateFile = ".state.yaml"
+)
+
+// projectLayouts lists the values `layout` may take in project.yaml.
+var projectLayouts = []string{layoutSingleFile, layoutTaskFiles}
+
+// projectState is the on-disk form of projectStateFile.
+type projectState struct {
+	Revision  int64     `yaml:"revision"`
+	UpdatedAt time.Time `yaml:"updated_at"`
+}
+
+// taskFile is the on-disk form of a task in the task-files layout. Order
+// keeps the task's position in the project, since directory listings lose
+// it. Tasks added on two branches may end up with the same order; ties go
+// by ID.
+type taskFile struct {
+	TaskV11 `yaml:",inline"`
+	Order   int `yaml:"order,omitempty"`
+}
+
+func isValidLayout(layout string) bool {
+	return layout == "" || containsString(projectLayouts, layout)
+}
+
+// taskFileName returns the file holding task id in the task-files layout.
+func taskFileName(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("task ID %q cannot be used as a file name", id)
+	}
+	return id + ".yaml", nil
+}
+
+// loadTaskFiles fills project.Tasks, Revision and UpdatedAt from a
+// task-files project directory. Tasks keep their stored order; tasks of
+// equal order, such as files written before orders were stored, are ordered
+// by ID, numerically where IDs end in a number.
+func loadTaskFiles(projectPath string, project *ProjectV11) error {
+	dir := filepath.Join(projectPath, taskFilesDirName)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", taskFilesDirName, err)
+	}
+
+	var files []taskFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var task taskFile
+		if err := yaml.Unmarshal(data, &task); err != nil {
+			backup, backupErr := loadYAMLBackup(path, &task)
+			if backupErr != nil {
+				return fmt.Errorf("failed to parse %s/%s: %w", taskFilesDirName, name, err)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %s/%s is unreadable (%v), using last-good copy %s\n", taskFilesDirName, name, err, backup)
+		}
+		if want := strings.TrimSuffix(name, ".yaml"); task.ID != want {
+			return fmt.Errorf("%s/%s holds task %q; task files must be named after their ID", taskFilesDirName, name, task.ID)
+		}
+		files = append(files, task)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Order != files[j].Order {
+			return files[i].Order < files[j].Order
+		}
+		return compareTaskIDs(files[i].ID, files[j].ID) < 0
+	})
+	project.Tasks = make([]TaskV11, 0, len(files))
+	for _, file := range files {
+		project.Tasks = append(project.Tasks, file.TaskV11)
+	}
+
+	state, err := loadProjectState(projectPath)
+	if err != nil {
+		return err
+	}
+	if state.Revision > 0 {
+		project.Revision = state.Revision
+		project.Project.UpdatedAt = state.UpdatedAt
+	}
+	return nil
+}
+
+func loadProjectState(projectPath string) (*projectState, error) {
+	var state projectState
+	data, err := os.ReadFile(filepath.Join(projectPath, projectStateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &state, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", projectStateFile, err)
+	}
+	return &state, nil
+}
+
+// compareTaskIDs orders IDs sharing a prefix by their numeric suffix, so
+// t-2 sorts before t-10.
+func compareTaskIDs(a, b string) int {
+	prefixA, numA, okA := splitTaskID(a)
+	prefixB, numB, okB := splitTaskID(b)
+	if okA && okB && prefixA == prefixB && numA != numB {
+		if numA < numB {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func splitTaskID(id string) (string, int, bool) {
+	i := len(id)
+	for i > 0 && id[i-1] >= '0' && id[i-1] <= '9' {
+		i--
+	}
+	if i == len(id) {
+		return id, 0, false
+	}
+	n, err := strconv.Atoi(id[i:])
+	if err != nil {
+		return id, 0, false
+	}
+	return id[:i], n, true
+}
+
+// writeTaskFilesLocked saves a task-files project; the caller must hold the
+// project lock. Only files whose content changed are rewritten: each task
+// file, then project.yaml (whose updated_at moves only when it changes
+// itself), then the state file carrying the new revision. A task keeps its
+// stored order while that still follows the task before it, so appending or
+// editing a task leaves the other files alone.
+func (pdm *ProjectDataManager) writeTaskFilesLocked(projectName string, project *ProjectV11, checkRevision bool) error {
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	statePath := filepath.Join(projectPath, projectStateFile)
+
+	stored, err := storedRevision(statePath)
+	if err != nil {
+		return err
+	}
+	if checkRevision && stored != project.Revision {
+		return &RevisionConflictError{Project: projectName, Expected: project.Revision, Actual: stored}
+	}
+
+	revision := nextRevision(stored, project.Revision)
+	now := time.Now()
+
+	dir := filepath.Join(projectPath, taskFilesDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", taskFilesDirName, err)
+	}
+	keep := make(map[string]bool, len(project.Tasks))
+	order := 0
+	for _, task := range project.Tasks {
+		name, err := taskFileName(task.ID)
+		if err != nil {
+			return err
+		}
+		keep[name] = true
+		path := filepath.Join(dir, name)
+
+		var stored taskFile
+		if current, err := os.ReadFile(path); err == nil && yaml.Unmarshal(current, &stored) == nil && stored.Order > order {
+			order = stored.Order
+		} else {
+			order++
+		}
+		data, err := yaml.Marshal(taskFile{TaskV11: task, Order: order})
+		if err != nil {
+			return fmt.Errorf("failed to marshal task %s: %w", task.ID, err)
+		}
+		if err := writeFileIfChanged(path, data); err != nil {
+			return fmt.Errorf("failed to save task %s: %w", task.ID, err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".yaml" || keep[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to remove %s/%s: %w", taskFilesDirName, name, err)
+		}
+		os.Remove(filepath.Join(dir, name+backupSuffix))
+	}
+
+	v11File := filepath.Join(projectPath, "project.yaml")
+	header := *project
+	header.Tasks = nil
+	header.Revision = 0
+	var onDisk ProjectV11
+	current, _ := os.ReadFile(v11File)
+	if yaml.Unmarshal(current, &onDisk) == nil && !onDisk.Project.UpdatedAt.IsZero() {
+		header.Project.UpdatedAt = onDisk.Project.UpdatedAt
+	}
+	data, err := yaml.Marshal(&header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project.yaml: %w", err)
+	}
+	if !bytes.Equal(data, current) {
+		header.Project.UpdatedAt = now
+		if data, err = yaml.Marshal(&header); err != nil {
+			return fmt.Errorf("failed to marshal project.yaml: %w", err)
+		}
+		if err := writeFileAtomicWithBackup(v11File, data, 0644); err != nil {
+			return fmt.Errorf("failed to save project.yaml: %w", err)
+		}
+	}
+
+	state, err := yaml.Marshal(projectState{Revision: revision, UpdatedAt: now})
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(statePath, state, 0644); err != nil {
+		return fmt.Errorf("failed to save %s: %w", projectStateFile, err)
+	}
+
+	project.Revision = revision
+	project.Project.UpdatedAt = now
+	return nil
+}
+
+// writeFileIfChanged durably replaces path with data unless it already
+// holds exactly that, so untouched tasks produce no diff.
+func writeFileIfChanged(path string, data []byte) error {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return writeFileAtomicWithBackup(path, data, 0644)
+}
+
+// ConvertLayout rewrites a v1.1 or later project in the given layout.
+// Converting to single-file removes tasks/ and the state file afterwards.
+func (pdm *ProjectDataManager) ConvertLayout(projectName, layout string) error {
+	if !containsString(projectLayouts, layout) {
+		return fmt.Errorf("unknown layout %q (use %s)", layout, strings.Join(projectLayouts, " or "))
+	}
+
+	if err := pdm.AcquireLock(projectName, pdm.lockTTL(projectName)); err != nil {
+		return err
+	}
+	defer pdm.ReleaseLock(projectName)
+
+	project, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("project '%s' has no project.yaml; run 'quickplan migrate v1.1' first", projectName)
+		}
+		return err
+	}
+	if projectLayout(project) == layout {
+		return fmt.Errorf("project '%s' already uses the %s layout", projectName, layout)
+	}
+
+	if layout == layoutTaskFiles {
+		project.Layout = layoutTaskFiles
+		if err := pdm.writeProjectV11Locked(projectName, project, false); err != nil {
+			return err
+		}
+		pdm.recordHistoryLine(projectName, "Convert to the task-files layout")
+		return nil
+	}
+
+	project.Layout = ""
+	if err := pdm.writeProjectV11Locked(projectName, project, false); err != nil {
+		return err
+	}
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	if err := os.RemoveAll(filepath.Join(projectPath, taskFilesDirName)); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(projectPath, projectStateFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	pdm.recordHistoryLine(projectName, "Convert to the single-file layout")
+	return nil
+}
+
+// projectLayout names the layout a loaded project uses.
+func projectLayout(project *ProjectV11) string {
+	if project.Layout == "" {
+		return layoutSingleFile
+	}
+	return project.Layout
+}
+
+// CreateProjectV11 creates an empty schema 1.1 project in the given layout.
+func (pdm *ProjectDataManager) CreateProjectV11(projectName, layout string) error {
+	if !containsString(projectLayouts, layout) {
+		return fmt.Errorf("unknown layout %q (use %s)", layout, strings.Join(projectLayouts, " or "))
+	}
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+	if err := os.MkdirAll(projectPath, 0755); err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+
+	now := time.Now()
+	config := &ProjectConfig{
+		Name:       projectName,
+		Created:    now,
+		Modified:   now,
+		SyncSource: SyncSource{Type: "local"},
+	}
+	if err := pdm.SaveProjectConfig(projectName, config); err != nil {
+		return fmt.Errorf("failed to save project config: %w", err)
+	}
+
+	project := &ProjectV11{
+		SchemaVersion: schemaV11,
+		Project: ProjectMeta{
+			Name:      projectName,
+			Version:   "0.1.0",
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Lock: LockConfig{
+			File:       ".quickplan.lock",
+			TTLSeconds: pdm.lockTTL(projectName),
+		},
+		Tasks: []TaskV11{},
+	}
+	if layout == layoutTaskFiles {
+		project.Layout = layoutTaskFiles
+	}
+	return pdm.SaveProjectV11(projectName, project)
+}
+
+// taskFilesNode gathers the task files of a task-files project into a
+// mapping with a tasks sequence, the shape migration Down steps inspect in
+// a single-file project.yaml. It returns nil for single-file projects.
+func taskFilesNode(projectPath string, root *yaml.Node) (*yaml.Node, error) {
+	if layout := mappingValue(root, "layout"); layout == nil || layout.Value != layoutTaskFiles {
+		return nil, nil
+	}
+	dir := filepath.Join(projectPath, taskFilesDirName)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	tasks := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s/%s: %w", taskFilesDirName, name, err)
+		}
+		if len(doc.Content) > 0 {
+			tasks.Content = append(tasks.Content, doc.Content[0])
+		}
+	}
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "tasks"}, tasks,
+	}}, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTaskFilesLayout_SaveLoadAndConvert(t *testing.T) {
+	pdm := NewProjectDataManager(t.TempDir(), NewVersionManager("0.3.0-alpha.rc1"))
+	projectName := "split"
+	if err := pdm.CreateProjectV11(projectName, layoutTaskFiles); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	projectPath := filepath.Join(pdm.dataDir, projectName)
+
+	project, err := pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	for _, id := range []string{"t-10", "t-2", "t-1"} {
+		project.Tasks = append(project.Tasks, TaskV11{ID: id, Name: id, Status: "TODO"})
+	}
+	if err := pdm.SaveProjectV11(projectName, project); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	header, _ := os.ReadFile(filepath.Join(projectPath, "project.yaml"))
+	if bytes.Contains(header, []byte("t-1")) {
+		t.Fatalf("expected tasks kept out of project.yaml:\n%s", header)
+	}
+
+	project, err = pdm.LoadProjectV11(projectName)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if len(project.Tasks) != 3 || project.Tasks[0].ID != "t-10" || project.Tasks[1].ID != "t-2" || project.Tasks[2].ID != "t-1" {
+		t.Fatalf("expected tasks in their saved order, got %+v", project.Tasks)
+	}
+
+	// Editing one task touches only its file and the state file.
+	untouched, _ := os.ReadFile(filepath.Join(projectPath, "tasks", "t-10.yaml"))
+	project.Tasks[1].Status = "IN_PROGRESS"
+	project.Tasks = project.Tasks[:2]
+	if err := pdm.CompareAndSwapProjectV11(projectName, project); err != nil {
+		t.Fatalf("compare-and-swap failed: %v", err)
+	}
+	if after, _ := os.ReadFile(filepath.Join(projectPath, "project.yaml")); !bytes.Equal(after, header) {
+		t.Fatalf("expected project.yaml unchanged by task edits:\n%s", after)
+	}
+	if after, _ := os.ReadFile(filepath.Join(projectPath, "tasks", "t-10.yaml")); !bytes.Equal(after, untouched) {
+		t.Fatal("expected untouched task file to stay byte-identical")
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "tasks", "t-1.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("expected removed task file deleted, got %v", err)
+	}
+
+	stale := *project
+	stale.Revision--
+	if err := pdm.CompareAndSwapProjectV11(projectName, &stale); !isRevisionConflict(err) {
+		t.Fatalf("expected revision conflict from the state file, got %v", err)
+	}
+
+	if err := pdm.ConvertLayout(projectName, layoutSingleFile); err != nil {
+		t.Fatalf("convert to single-file failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "tasks")); !os.IsNotExist(err) {
+		t.Fatal("expected tasks/ removed after converting to single-file")
+	}
+	single, err := pdm.LoadProjectV11(projectName)
+	if err != nil || len(single.Tasks) != 2 || single.Tasks[1].Status != "IN_PROGRESS" {
+		t.Fatalf("expected tasks inlined after conversion, got %+v (%v)", single, err)
+	}
+
+	if err := pdm.ConvertLayout(projectName, layoutTaskFiles); err != nil {
+		t.Fatalf("convert to task-files failed: %v", err)
+	}
+	views, isV11, err := pdm.GetTaskViews(projectName)
+	if err != nil || !isV11 || len(views) != 2 {
+		t.Fatalf("expected task views from task files, got %d (%v)", len(views), err)
+	}
+}
+
+func TestTaskFilesLayout_RejectsMisnamedFiles(t *testing.T) {
+	pdm := NewProjectDataManager(t.TempDir(), NewVersionManager("0.3.0-alpha.rc1"))
+	if err := pdm.CreateProjectV11("split", layoutTaskFiles); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	dir := filepath.Join(pdm.dataDir, "split", "tasks")
+	if err := os.WriteFile(filepath.Join(dir, "t-1.yaml"), []byte("id: t-9\nname: x\nstatus: TODO\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pdm.LoadProjectV11("split"); err == nil {
+		t.Fatal("expected error for a task file not named after its ID")
+	}
+
+	if _, err := taskFileName("../escape"); err == nil {
+		t.Fatal("expected path separators to be rejected in task IDs")
+	}
+}
+
+func TestTaskFilesLayout_KeepsOrderAcrossEdits(t *testing.T) {
+	pdm := NewProjectDataManager(t.TempDir(), NewVersionManager("0.3.0-alpha.rc1"))
+	if err := pdm.CreateProjectV11("split", layoutTaskFiles); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	dir := filepath.Join(pdm.dataDir, "split", "tasks")
+
+	// Files written before orders were stored load in ID order.
+	for _, id := range []string{"t-2", "t-10", "t-1"} {
+		if err := os.WriteFile(filepath.Join(dir, id+".yaml"), []byte("id: "+id+"\nname: "+id+"\nstatus: TODO\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	project, err := pdm.LoadProjectV11("split")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if got := taskIDs(project.Tasks); got != "t-1 t-2 t-10" {
+		t.Fatalf("expected ID order for unordered files, got %s", got)
+	}
+
+	// Moving t-10 to the front sticks, and appending leaves it there.
+	project.Tasks = append([]TaskV11{project.Tasks[2]}, project.Tasks[:2]...)
+	if err := pdm.SaveProjectV11("split", project); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	project, _ = pdm.LoadProjectV11("split")
+	first, _ := os.ReadFile(filepath.Join(dir, "t-10.yaml"))
+	project.Tasks = append(project.Tasks, TaskV11{ID: "t-3", Name: "t-3", Status: "TODO"})
+	if err := pdm.SaveProjectV11("split", project); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	project, err = pdm.LoadProjectV11("split")
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if got := taskIDs(project.Tasks); got != "t-10 t-1 t-2 t-3" {
+		t.Fatalf("expected the stored order to be kept, got %s", got)
+	}
+	if after, _ := os.ReadFile(filepath.Join(dir, "t-10.yaml")); !bytes.Equal(after, first) {
+		t.Fatal("expected appending a task to leave the other task files alone")
+	}
+}
+
+func taskIDs(tasks []TaskV11) string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return strings.Join(ids, " ")
+}
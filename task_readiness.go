@@ -18,6 +18,9 @@ func isTaskRunnable(task TaskView, statusByID map[string]string) bool {
 }
 
 func taskReadinessIssue(task TaskView, statusByID map[string]string) string {
+	if len(task.Subtasks) > 0 {
+		return "task groups subtasks and is not executed itself"
+	}
 	if task.Status != "TODO" && task.Status != "PENDING" {
 		return fmt.Sprintf("task is not runnable from status %s", task.Status)
 	}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseAtTime resolves an --at value to an instant. It accepts RFC3339
+// timestamps, plain dates (2006-01-02, local midnight) and durations meaning
+// "that long ago" (90m, 36h, 7d).
+func parseAtTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("--at requires a timestamp or duration")
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --at value %q: use RFC3339 (2026-02-17T09:00:00Z), a date (2026-02-17) or a duration ago (36h, 7d)", value)
+}
+
+// atFlag reads the --at flag shared by list, stats and tui. It returns nil
+// when the flag is unset.
+func atFlag(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	at, err := parseAtTime(value, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+// GetTaskViewsAt returns task views as they were at the given instant, by
+// folding the event log up to it over the current task definitions.
+// Tasks created after the cutoff did not exist yet and are dropped; tasks
+// deleted since are restored with the status they had. A task with no
+// status change up to the cutoff takes the status its first later change
+// moved it from, and keeps its current status if it never changed.
+func (pdm *ProjectDataManager) GetTaskViewsAt(projectName string, at time.Time) ([]TaskView, bool, error) {
+	views, isV11, err := pdm.GetTaskViews(projectName)
+	if err != nil {
+		return nil, false, err
+	}
+	eventLog, err := pdm.loadCanonicalEventLog(projectName)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load event log: %w", err)
+	}
+
+	createdLater := make(map[string]bool)
+	statusBefore := make(map[string]string)
+	var past []Event
+	for _, event := range eventLog.Events {
+		if !event.Timestamp.After(at) {
+			past = append(past, event)
+			continue
+		}
+		if event.TaskID == "" {
+			continue
+		}
+		if isTaskCreation(event) {
+			createdLater[event.TaskID] = true
+		}
+		if _, ok := statusBefore[event.TaskID]; !ok && event.PrevStatus != "" {
+			statusBefore[event.TaskID] = event.PrevStatus
+		}
+	}
+	statuses := replayTaskStatuses(past)
+
+	result := make([]TaskView, 0, len(views))
+	seen := make(map[string]bool, len(views))
+	for _, view := range views {
+		seen[view.ID] = true
+		if status, ok := statuses[view.ID]; ok {
+			view.Status = status
+		} else if createdLater[view.ID] {
+			continue
+		} else if status, ok := statusBefore[view.ID]; ok {
+			view.Status = status
+		}
+		result = append(result, view)
+	}
+
+	for _, event := range past {
+		id := event.TaskID
+		if id == "" || seen[id] {
+			continue
+		}
+		status, ok := statuses[id]
+		if !ok {
+			continue
+		}
+		seen[id] = true
+		result = append(result, TaskView{
+			ID:     id,
+			Text:   deletedTaskText(past, id),
+			Status: status,
+			IsV11:  isV11,
+		})
+	}
+
+	return result, isV11, nil
+}
+
+// isTaskCreation reports whether event brought its task into existence:
+// TASK_CREATED from add, or TASK_RECURRED for the next instance of a
+// recurring task.
+func isTaskCreation(event Event) bool {
+	return event.Type == "TASK_CREATED" || event.Type == "TASK_RECURRED"
+}
+
+// deletedTaskText recovers a deleted task's text from its creation event.
+func deletedTaskText(events []Event, taskID string) string {
+	for _, event := range events {
+		if event.TaskID == taskID && event.Type == "TASK_CREATED" {
+			if text, ok := strings.CutPrefix(event.Message, "Task created: "); ok {
+				return text + " (deleted)"
+			}
+		}
+	}
+	return "(deleted)"
+}
+
+// countEventsAt returns how many events were recorded up to at.
+func (pdm *ProjectDataManager) countEventsAt(projectName string, at time.Time) (int, error) {
+	eventLog, err := pdm.loadCanonicalEventLog(projectName)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, event := range eventLog.Events {
+		if !event.Timestamp.After(at) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAtTime(t *testing.T) {
+	now := time.Date(2026, 2, 20, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]time.Time{
+		"2026-02-17T09:00:00Z": time.Date(2026, 2, 17, 9, 0, 0, 0, time.UTC),
+		"36h":                  now.Add(-36 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+	}
+	for input, want := range cases {
+		got, err := parseAtTime(input, now)
+		if err != nil {
+			t.Fatalf("parseAtTime(%q) failed: %v", input, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("parseAtTime(%q) = %s, want %s", input, got, want)
+		}
+	}
+
+	if _, err := parseAtTime("last tuesday", now); err == nil {
+		t.Fatal("expected error for unparseable value")
+	}
+	if _, err := parseAtTime("-5h", now); err == nil {
+		t.Fatal("expected error for negative duration")
+	}
+}
+
+func TestGetTaskViewsAt_FoldsEventsUpToCutoff(t *testing.T) {
+	pdm, projectName, cleanup := newTransitionTestManager(t)
+	defer cleanup()
+
+	base := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	v11 := &ProjectV11{
+		SchemaVersion: "1.1",
+		Project:       ProjectMeta{Name: projectName, CreatedAt: base},
+		Tasks: []TaskV11{
+			{ID: "t-1", Name: "one", Status: "DONE"},
+			{ID: "t-3", Name: "three", Status: "TODO"},
+			{ID: "t-4", Name: "untracked", Status: "IN_PROGRESS"},
+		},
+		Events: []Event{
+			{Timestamp: base, Type: "TASK_CREATED", TaskID: "t-1", NextStatus: "TODO"},
+			{Timestamp: base, Type: "TASK_CREATED", TaskID: "t-2", NextStatus: "TODO", Message: "Task created: two"},
+			{Timestamp: base.Add(1 * time.Hour), Type: "TASK_BLOCKED", TaskID: "t-1", PrevStatus: "TODO", NextStatus: "BLOCKED"},
+			{Timestamp: base.Add(2 * time.Hour), Type: "TASK_UNBLOCKED", TaskID: "t-1", PrevStatus: "BLOCKED", NextStatus: "PENDING"},
+			{Timestamp: base.Add(3 * time.Hour), Type: "TASK_DELETED", TaskID: "t-2"},
+			{Timestamp: base.Add(3 * time.Hour), Type: "TASK_CREATED", TaskID: "t-3", NextStatus: "TODO"},
+			{Timestamp: base.Add(4 * time.Hour), Type: "TASK_STATUS_CHANGED", TaskID: "t-1", PrevStatus: "IN_PROGRESS", NextStatus: "DONE"},
+		},
+	}
+	if err := pdm.SaveProjectV11(projectName, v11); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	views, _, err := pdm.GetTaskViewsAt(projectName, base.Add(90*time.Minute))
+	if err != nil {
+		t.Fatalf("time travel failed: %v", err)
+	}
+
+	byID := make(map[string]TaskView)
+	for _, v := range views {
+		byID[v.ID] = v
+	}
+	if byID["t-1"].Status != "BLOCKED" {
+		t.Fatalf("expected t-1 BLOCKED at cutoff, got %q", byID["t-1"].Status)
+	}
+	if v, ok := byID["t-2"]; !ok || v.Status != "TODO" || v.Text != "two (deleted)" {
+		t.Fatalf("expected deleted t-2 restored, got %+v (present=%v)", v, ok)
+	}
+	if _, ok := byID["t-3"]; ok {
+		t.Fatal("t-3 was created after the cutoff and must not appear")
+	}
+	if byID["t-4"].Status != "IN_PROGRESS" {
+		t.Fatalf("expected untracked t-4 to keep its current status, got %q", byID["t-4"].Status)
+	}
+}
+
+func TestGetTaskViewsAt_KeepsAddedTaskThatChangedLater(t *testing.T) {
+	pdm, projectName, cleanup := newLeaseTestProject(t)
+	defer cleanup()
+
+	err := pdm.Update(projectName, func(tx *ProjectTx) error {
+		_, _, err := addTaskTx(addCmd, tx, "write docs")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	// An older v1.1 task that was added before add recorded TASK_CREATED.
+	err = pdm.Update(projectName, func(tx *ProjectTx) error {
+		tx.V11().Tasks = append(tx.V11().Tasks, TaskV11{ID: "t-2", Name: "old", Status: "TODO", UpdatedAt: time.Now()})
+		tx.MarkModified()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	for _, id := range []string{"t-1", "t-2"} {
+		for _, status := range []string{"IN_PROGRESS", "DONE"} {
+			if err := pdm.UpdateTaskStatus(projectName, id, status, "human"); err != nil {
+				t.Fatalf("move %s to %s failed: %v", id, status, err)
+			}
+		}
+	}
+	err = pdm.Update(projectName, func(tx *ProjectTx) error {
+		_, _, err := addTaskTx(addCmd, tx, "added later")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	views, _, err := pdm.GetTaskViewsAt(projectName, cutoff)
+	if err != nil {
+		t.Fatalf("time travel failed: %v", err)
+	}
+	byID := make(map[string]TaskView)
+	for _, v := range views {
+		byID[v.ID] = v
+	}
+	for _, id := range []string{"t-1", "t-2"} {
+		if v, ok := byID[id]; !ok || v.Status != "TODO" {
+			t.Fatalf("expected %s to be TODO at the cutoff, got %+v (present=%v)", id, v, ok)
+		}
+	}
+	if _, ok := byID["t-3"]; ok {
+		t.Fatal("t-3 was added after the cutoff and must not appear")
+	}
+}
@@ -2,10 +2,8 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-
-	"gopkg.in/yaml.v3"
+	"strconv"
+	"strings"
 )
 
 // VersionManager handles version compatibility and migrations
@@ -21,78 +19,137 @@ func NewVersionManager(version string) *VersionManager {
 	}
 }
 
-// MigrateProjectIfNeeded checks and migrates project data to current version
+// MigrateProjectIfNeeded applies the automatic tasks.yaml migrations in the
+// migration registry, ending with the current CLI version stamp.
 // Returns true if migration occurred, false otherwise
 func (vm *VersionManager) MigrateProjectIfNeeded(projectPath string) (bool, error) {
-	tasksFile := filepath.Join(projectPath, "tasks.yaml")
-
-	// Read existing data
-	data, err := os.ReadFile(tasksFile)
+	_, records, err := vm.Migrate(projectPath, "tasks.yaml", vm.currentVersion, true, false)
 	if err != nil {
-		return false, fmt.Errorf("failed to read tasks file: %w", err)
+		return false, fmt.Errorf("migration failed: %w", err)
 	}
+	return len(records) > 0, nil
+}
 
-	var projectData ProjectData
-	if err := yaml.Unmarshal(data, &projectData); err != nil {
-		return false, fmt.Errorf("failed to parse tasks file: %w", err)
+// ValidateProjectVersion checks if a project version is compatible.
+// Projects written by a newer quickplan are rejected, since they may hold
+// data this build would silently drop on save.
+func (vm *VersionManager) ValidateProjectVersion(projectVersion string) error {
+	if projectVersion == "" {
+		// Empty version means legacy project, compatible
+		return nil
 	}
 
-	// Check if migration is needed
-	if projectData.Version == vm.currentVersion {
-		return false, nil // Already up to date
+	if cmp, ok := compareVersions(projectVersion, vm.currentVersion); ok && cmp > 0 {
+		return fmt.Errorf("project was written by quickplan %s, which is newer than this build (%s); upgrade quickplan to open it", projectVersion, vm.currentVersion)
 	}
+	return nil
+}
 
-	// Perform migration
-	if err := vm.migrateFromVersion(projectData.Version, vm.currentVersion, &projectData); err != nil {
-		return false, fmt.Errorf("migration failed: %w", err)
+// compareVersions orders two semantic versions ("1.2", "v0.3.0-alpha.rc1"),
+// returning -1, 0 or 1. ok is false when either does not parse.
+func compareVersions(a, b string) (int, bool) {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
 	}
-
-	// Update version
-	projectData.Version = vm.currentVersion
-
-	// Save migrated data
-	migratedData, err := yaml.Marshal(&projectData)
-	if err != nil {
-		return false, fmt.Errorf("failed to marshal migrated data: %w", err)
+	for i := 0; i < 3; i++ {
+		if va.core[i] != vb.core[i] {
+			if va.core[i] < vb.core[i] {
+				return -1, true
+			}
+			return 1, true
+		}
 	}
+	return comparePrerelease(va.pre, vb.pre), true
+}
 
-	if err := os.WriteFile(tasksFile, migratedData, 0644); err != nil {
-		return false, fmt.Errorf("failed to write migrated data: %w", err)
+// versionLess reports whether a sorts before b. The empty version sorts
+// first; versions that do not parse count as older than anything else, so
+// development builds keep re-stamping as before.
+func versionLess(a, b string) bool {
+	if a == b {
+		return false
+	}
+	if a == "" {
+		return true
+	}
+	if b == "" {
+		return false
 	}
+	cmp, ok := compareVersions(a, b)
+	if !ok {
+		return true
+	}
+	return cmp < 0
+}
 
-	return true, nil
+type semVersion struct {
+	core [3]int
+	pre  []string
 }
 
-// migrateFromVersion applies version-specific migrations
-// Following Open/Closed Principle - easy to extend with new version migrations
-func (vm *VersionManager) migrateFromVersion(from, to string, data *ProjectData) error {
-	// Handle empty version (legacy projects)
-	if from == "" {
-		// Legacy projects don't need data migration, just version tagging
-		return nil
+func parseVersion(value string) (semVersion, bool) {
+	var v semVersion
+	value = strings.TrimPrefix(strings.TrimSpace(value), "v")
+	value, _, _ = strings.Cut(value, "+")
+	value, pre, hasPre := strings.Cut(value, "-")
+	if hasPre {
+		v.pre = strings.Split(pre, ".")
 	}
 
-	// Future migrations can be added here
-	// Example:
-	// if from == "0.1.0" && to >= "0.2.0" {
-	//     // Apply 0.1.0 -> 0.2.0 migration logic
-	// }
-
-	return nil
+	parts := strings.Split(value, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
 }
 
-// ValidateProjectVersion checks if a project version is compatible
-func (vm *VersionManager) ValidateProjectVersion(projectVersion string) error {
-	if projectVersion == "" {
-		// Empty version means legacy project, compatible
-		return nil
+// comparePrerelease follows semver precedence: a release outranks its
+// pre-releases, and numeric identifiers compare numerically.
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
 	}
-
-	// For now, all versions are compatible
-	// Future: implement semver comparison for breaking changes
-	// if !isCompatible(projectVersion, vm.currentVersion) {
-	//     return fmt.Errorf("project version %s is not compatible with CLI version %s", projectVersion, vm.currentVersion)
-	// }
-
-	return nil
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		na, errA := strconv.Atoi(a[i])
+		nb, errB := strconv.Atoi(b[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		case a[i] < b[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
 }
@@ -115,7 +115,9 @@ func TestVersionManager_ValidateProjectVersion(t *testing.T) {
 	}{
 		{"EmptyVersion", "", false},
 		{"SameVersion", "0.1.0", false},
-		{"DifferentVersion", "0.2.0", false}, // Currently all versions compatible
+		{"OlderVersion", "0.0.9", false},
+		{"NewerVersion", "0.2.0", true},
+		{"UnparseableVersion", "dev", false},
 	}
 
 	for _, tt := range tests {
@@ -127,3 +129,28 @@ func TestVersionManager_ValidateProjectVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.1.0", "0.2.0", -1},
+		{"1.2", "1.1", 1},
+		{"v0.3.0", "0.3.0", 0},
+		{"0.3.0-alpha.rc1", "0.3.0", -1},
+		{"0.3.0-alpha.2", "0.3.0-alpha.10", -1},
+		{"0.3.0-beta", "0.3.0-alpha.rc1", 1},
+	}
+
+	for _, tt := range tests {
+		got, ok := compareVersions(tt.a, tt.b)
+		if !ok || got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, %v; want %d", tt.a, tt.b, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := compareVersions("dev", "0.1.0"); ok {
+		t.Error("expected unparseable version to be unordered")
+	}
+}
@@ -12,7 +12,7 @@ func applyWebAuth(req *http.Request) {
 		return
 	}
 
-	if key := strings.TrimSpace(os.Getenv("QUICKPLAN_API_KEY")); key != "" {
+	if key := strings.TrimSpace(globalSetting("api_key").Value); key != "" {
 		req.Header.Set("X-API-Key", key)
 	}
 
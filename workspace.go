@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// repoDirName is the repository-local data directory. Like .git, it is
+// discovered from the working directory upwards, so a plan can be committed
+// next to the code it describes.
+const repoDirName = ".quickplan"
+
+// runtimeGitignore keeps runtime state out of commits; everything else is
+// the plan itself. It is written both to .quickplan/.gitignore and to the
+// project directory of a history-enabled project, so its patterns match at
+// any depth.
+const runtimeGitignore = `# quickplan runtime state; commit everything else
+.quickplan.lock
+.quickplan.lock.waiters/
+.state.yaml
+.current_project
+.migrations.yaml
+.undo_backup.yaml
+runs/
+**/events/.lock
+**/events/.writer
+*.bak
+.*.tmp-*
+`
+
+// repoGitattributes lets git merge event segments that two branches of one
+// clone both appended to by keeping the lines of both sides; the event store
+// orders them by sequence number when reading.
+const repoGitattributes = `# quickplan event segments are append-only
+*/events/*.jsonl merge=union
+`
+
+// findRepoDataDir returns the nearest .quickplan directory in dir or one of
+// its parents, or "" when there is none.
+func findRepoDataDir(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, repoDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// projectDataDir is the data directory --project was found in when it is
+// not the one getDataDir would pick otherwise; see useProjectDataDir.
+var projectDataDir string
+
+// useProjectDataDir lets --project name a global project from inside a
+// repository: when the project exists in the global data directory but not
+// in the repository-local one, getDataDir returns the global directory for
+// the rest of the command. create is left alone so that it still creates
+// projects inside the repository.
+func useProjectDataDir(cmd *cobra.Command) {
+	flag := cmd.Flags().Lookup("project")
+	if flag == nil || flag.Value.String() == "" || cmd.Name() == "create" {
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	projectDataDir = findProjectDataDir(cwd, flag.Value.String())
+}
+
+// findProjectDataDir returns the global data directory when projectName
+// exists there but not in the repository-local directory above dir, and ""
+// otherwise.
+func findProjectDataDir(dir, projectName string) string {
+	repoDir := findRepoDataDir(dir)
+	if repoDir == "" || os.Getenv("QUICKPLAN_DATADIR") != "" || isDir(filepath.Join(repoDir, projectName)) {
+		return ""
+	}
+	globalDir, err := globalDataDir()
+	if err != nil || !isDir(filepath.Join(globalDir, projectName)) {
+		return ""
+	}
+	return globalDir
+}
+
+// allDataDirs returns the data directories that commands spanning every
+// project, such as agenda and the daemon, read: the repository-local one
+// when there is one, then the global one.
+func allDataDirs() ([]string, error) {
+	dataDir, err := getDataDir()
+	if err != nil || os.Getenv("QUICKPLAN_DATADIR") != "" {
+		return []string{dataDir}, err
+	}
+	globalDir, err := globalDataDir()
+	if err != nil {
+		return nil, err
+	}
+	if dataDir == globalDir {
+		return []string{dataDir}, nil
+	}
+	return []string{dataDir, globalDir}, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// soleRepoProject returns the only project of a repository-local data
+// directory, or "" when dataDir is not one or holds several projects.
+func soleRepoProject(dataDir string) string {
+	if filepath.Base(dataDir) != repoDirName {
+		return ""
+	}
+	projects, err := NewProjectDataManager(dataDir, NewVersionManager(version)).ListProjects(true)
+	if err != nil || len(projects) != 1 {
+		return ""
+	}
+	return projects[0]
+}
+
+// initRepoDataDir creates .quickplan/ in dir holding a new project and
+// makes that project current there. It returns the new data directory.
+func initRepoDataDir(dir, projectName string) (string, error) {
+	dataDir := filepath.Join(dir, repoDirName)
+	if _, err := os.Stat(dataDir); err == nil {
+		return "", fmt.Errorf("%s already exists", dataDir)
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dataDir, err)
+	}
+
+	projectManager := NewProjectDataManager(dataDir, NewVersionManager(version))
+	if err := projectManager.CreateProject(projectName); err != nil {
+		return "", fmt.Errorf("failed to create project: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, ".current_project"), []byte(projectName), 0644); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, ".gitignore"), []byte(runtimeGitignore), 0644); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, ".gitattributes"), []byte(repoGitattributes), 0644); err != nil {
+		return "", err
+	}
+	return dataDir, nil
+}
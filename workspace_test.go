@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitRepoDataDir_DiscoveredFromNestedDirectory(t *testing.T) {
+	repo := t.TempDir()
+	nested := filepath.Join(repo, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := findRepoDataDir(nested); got != "" {
+		t.Fatalf("expected no workspace before init, got %s", got)
+	}
+
+	dataDir, err := initRepoDataDir(repo, "app")
+	if err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if got := findRepoDataDir(nested); got != dataDir {
+		t.Fatalf("expected %s from nested dir, got %q", dataDir, got)
+	}
+
+	current, err := os.ReadFile(filepath.Join(dataDir, ".current_project"))
+	if err != nil || string(current) != "app" {
+		t.Fatalf("expected current project app, got %q (%v)", current, err)
+	}
+	ignore, _ := os.ReadFile(filepath.Join(dataDir, ".gitignore"))
+	if !strings.Contains(string(ignore), ".quickplan.lock") {
+		t.Fatalf("expected lock files ignored:\n%s", ignore)
+	}
+	attributes, _ := os.ReadFile(filepath.Join(dataDir, ".gitattributes"))
+	if !strings.Contains(string(attributes), "merge=union") {
+		t.Fatalf("expected event segments merged as unions:\n%s", attributes)
+	}
+	pdm := NewProjectDataManager(dataDir, NewVersionManager(version))
+	if projects, err := pdm.ListProjects(false); err != nil || len(projects) != 1 || projects[0] != "app" {
+		t.Fatalf("expected only project app inside the workspace, got %v (%v)", projects, err)
+	}
+	if got := soleRepoProject(dataDir); got != "app" {
+		t.Fatalf("expected a fresh clone to fall back to app, got %q", got)
+	}
+
+	if _, err := initRepoDataDir(repo, "again"); err == nil {
+		t.Fatal("expected error when .quickplan already exists")
+	}
+}
+
+func TestRuntimeGitignore_MatchesInRepoAndProjectDirectories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	if _, err := runGit(repo, nil, "init", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{".quickplan", filepath.Join(".quickplan", "app")} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repo, dir, ".gitignore"), []byte(runtimeGitignore), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ignored := []string{
+		".quickplan/.current_project",
+		".quickplan/.undo_backup.yaml",
+		".quickplan/app/.quickplan.lock",
+		".quickplan/app/.state.yaml",
+		".quickplan/app/.migrations.yaml",
+		".quickplan/app/runs/t-1/1.log",
+		".quickplan/app/events/.lock",
+		".quickplan/app/events/.writer",
+		".quickplan/app/tasks/t-1.yaml.bak",
+	}
+	for _, path := range ignored {
+		if _, err := runGit(repo, nil, "check-ignore", "--quiet", path); err != nil {
+			t.Errorf("expected %s to be ignored", path)
+		}
+	}
+	for _, path := range []string{".quickplan/app/project.yaml", ".quickplan/app/tasks/t-1.yaml", ".quickplan/app/events/events-0a1b2c3d-000001.jsonl"} {
+		if _, err := runGit(repo, nil, "check-ignore", "--quiet", path); err == nil {
+			t.Errorf("expected %s to be committed", path)
+		}
+	}
+}
+
+func TestUseProjectDataDir_ResolvesGlobalProjectInsideRepository(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("QUICKPLAN_DATADIR", "")
+	globalDir := t.TempDir()
+	if err := setGlobalSetting("data_dir", globalDir); err != nil {
+		t.Fatalf("set data_dir failed: %v", err)
+	}
+	if err := NewProjectDataManager(globalDir, NewVersionManager(version)).CreateProject("globalp"); err != nil {
+		t.Fatalf("create global project failed: %v", err)
+	}
+	repo := t.TempDir()
+	repoDir, err := initRepoDataDir(repo, "app")
+	if err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	t.Chdir(repo)
+	t.Cleanup(func() {
+		projectDataDir = ""
+		listCmd.Flags().Set("project", "")
+	})
+
+	if got, _ := getDataDir(); got != repoDir {
+		t.Fatalf("expected the repository data dir without --project, got %s", got)
+	}
+	if err := listCmd.Flags().Set("project", "globalp"); err != nil {
+		t.Fatal(err)
+	}
+	useProjectDataDir(listCmd)
+	if got, _ := getDataDir(); got != globalDir {
+		t.Fatalf("expected -p globalp to use the global data dir, got %s", got)
+	}
+	if !projectExists("globalp") {
+		t.Fatal("expected the global project to be found from inside the repository")
+	}
+
+	for _, name := range []string{"app", "missing"} {
+		if got := findProjectDataDir(repo, name); got != "" {
+			t.Fatalf("expected %s to stay in the repository, got %s", name, got)
+		}
+	}
+
+	projectDataDir = ""
+	dirs, err := allDataDirs()
+	if err != nil || len(dirs) != 2 || dirs[0] != repoDir || dirs[1] != globalDir {
+		t.Fatalf("expected the repository and global data dirs, got %v (%v)", dirs, err)
+	}
+}